@@ -0,0 +1,94 @@
+package dft
+
+import "fmt"
+
+// StreamAnalyzer computes the short-time fourier transform of a signal that
+// arrives in pieces of any size, e.g. from a microphone, a network connection
+// or a streaming decoder. It keeps the latest frameSize samples in a ring
+// buffer and emits a windowed frame every hopSize samples. The frames are the
+// same as those of STFT with the same window.
+type StreamAnalyzer struct {
+	sampleRate int
+	frameSize  int
+	hopSize    int
+	window     []float64
+	ring       []float64
+	pos        int // next write position in ring
+	total      int // number of samples pushed since the last reset
+	next       int // value of total at which the next frame is complete
+	index      int // index of the next frame
+	buf        []float64
+}
+
+// NewStreamAnalyzer returns a StreamAnalyzer for frames of frameSize samples
+// with a hop of hopSize samples, weighted with window
+func NewStreamAnalyzer(sampleRate, frameSize, hopSize int, window Window) (*StreamAnalyzer, error) {
+	if sampleRate <= 0 || frameSize <= 0 || hopSize <= 0 {
+		return nil, fmt.Errorf("invalid sample rate %d, frame size %d or hop size %d", sampleRate, frameSize, hopSize)
+	}
+	return &StreamAnalyzer{
+		sampleRate: sampleRate,
+		frameSize:  frameSize,
+		hopSize:    hopSize,
+		window:     window.Coefficients(frameSize),
+		ring:       make([]float64, frameSize),
+		next:       frameSize,
+		buf:        make([]float64, frameSize),
+	}, nil
+}
+
+// Push appends samples to the stream and returns the frames completed by them,
+// in order. Frame.Time is relative to the first sample pushed after the last
+// reset.
+func (a *StreamAnalyzer) Push(samples []float64) []Frame {
+	var frames []Frame
+	for len(samples) > 0 {
+		// Copy up to the completion of the next frame
+		n := min(len(samples), a.next-a.total)
+		for _, v := range samples[:n] {
+			a.ring[a.pos] = v
+			a.pos = (a.pos + 1) % a.frameSize
+		}
+		a.total += n
+		samples = samples[n:]
+
+		if a.total == a.next {
+			frames = append(frames, a.frame())
+			a.next += a.hopSize
+		}
+	}
+	return frames
+}
+
+// frame windows the ring buffer content, oldest sample first, and transforms it
+func (a *StreamAnalyzer) frame() Frame {
+	n := copy(a.buf, a.ring[a.pos:])
+	copy(a.buf[n:], a.ring[:a.pos])
+	for i, w := range a.window {
+		a.buf[i] *= w
+	}
+	f := Frame{
+		Time:     FrameTime(a.index, a.sampleRate, a.frameSize, a.hopSize),
+		Spectrum: Forward(a.buf),
+	}
+	a.index++
+	return f
+}
+
+// Reset discards the buffered samples and restarts the frame count
+func (a *StreamAnalyzer) Reset() {
+	for i := range a.ring {
+		a.ring[i] = 0
+	}
+	a.pos, a.total, a.next, a.index = 0, 0, a.frameSize, 0
+}
+
+// FrameSize returns the number of samples per frame
+func (a *StreamAnalyzer) FrameSize() int {
+	return a.frameSize
+}
+
+// SampleRate returns the sample rate of the stream
+func (a *StreamAnalyzer) SampleRate() int {
+	return a.sampleRate
+}
@@ -0,0 +1,294 @@
+// Package aiff decodes AIFF and AIFF-C files into beep streamers, like the wav
+// package of beep.
+package aiff
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/faiface/beep"
+)
+
+// encoding is the sample encoding of the SSND chunk
+type encoding int
+
+const (
+	bigEndianPCM encoding = iota
+	littleEndianPCM
+	float32BE
+	float64BE
+)
+
+type decoder struct {
+	r          io.Reader
+	br         *bufio.Reader
+	channels   int
+	frames     int // number of sample frames
+	bits       int // bits per sample
+	bytes      int // bytes per sample
+	enc        encoding
+	dataOffset int64 // offset of the first sample in r
+	pos        int
+	buf        []byte
+	err        error
+}
+
+// Decode reads the header of an AIFF or AIFF-C stream from r and returns a
+// streamer for its samples. AIFF-C is supported for uncompressed PCM (NONE,
+// sowt) and floating point (fl32, fl64) data. Seeking requires r to implement
+// io.Seeker, as does a file whose SSND chunk comes before its COMM chunk.
+func Decode(r io.Reader) (s beep.StreamSeekCloser, format beep.Format, err error) {
+	d := &decoder{r: r}
+	defer func() {
+		if closer, ok := r.(io.Closer); ok && err != nil {
+			closer.Close()
+		}
+	}()
+
+	var form [12]byte
+	if _, err := io.ReadFull(r, form[:]); err != nil {
+		return nil, beep.Format{}, fmt.Errorf("aiff: %w", err)
+	}
+	if string(form[:4]) != "FORM" {
+		return nil, beep.Format{}, fmt.Errorf("aiff: missing FORM at the beginning")
+	}
+	aifc := false
+	switch string(form[8:]) {
+	case "AIFF":
+	case "AIFC":
+		aifc = true
+	default:
+		return nil, beep.Format{}, fmt.Errorf("aiff: unsupported form type %q", form[8:])
+	}
+
+	var sampleRate float64
+	offset := int64(12)
+	hasComm, hasData := false, false
+	for !hasComm || !hasData {
+		var header [8]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return nil, beep.Format{}, fmt.Errorf("aiff: missing COMM or SSND chunk: %w", err)
+		}
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		start := offset + 8
+		offset = start + size + size%2 // chunks are padded to an even length
+
+		switch string(header[:4]) {
+		case "COMM":
+			chunk := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, chunk); err != nil {
+				return nil, beep.Format{}, fmt.Errorf("aiff: %w", err)
+			}
+			if sampleRate, err = d.parseComm(chunk[:size], aifc); err != nil {
+				return nil, beep.Format{}, err
+			}
+			hasComm = true
+			if hasData {
+				// The samples came before the COMM chunk, go back to them
+				if _, err := r.(io.Seeker).Seek(d.dataOffset, io.SeekStart); err != nil {
+					return nil, beep.Format{}, fmt.Errorf("aiff: seek error: %w", err)
+				}
+			}
+		case "SSND":
+			var ssnd [8]byte
+			if _, err := io.ReadFull(r, ssnd[:]); err != nil {
+				return nil, beep.Format{}, fmt.Errorf("aiff: %w", err)
+			}
+			d.dataOffset = start + 8 + int64(binary.BigEndian.Uint32(ssnd[:4]))
+			hasData = true
+			if hasComm {
+				// Leave r at the first sample
+				if _, err := io.CopyN(io.Discard, r, d.dataOffset-start-8); err != nil {
+					return nil, beep.Format{}, fmt.Errorf("aiff: %w", err)
+				}
+				break
+			}
+			seeker, ok := r.(io.Seeker)
+			if !ok {
+				return nil, beep.Format{}, fmt.Errorf("aiff: SSND chunk before COMM chunk requires io.Seeker")
+			}
+			if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+				return nil, beep.Format{}, fmt.Errorf("aiff: seek error: %w", err)
+			}
+		default:
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return nil, beep.Format{}, fmt.Errorf("aiff: %w", err)
+			}
+		}
+	}
+	d.br = bufio.NewReader(r)
+
+	format = beep.Format{
+		SampleRate:  beep.SampleRate(math.Round(sampleRate)),
+		NumChannels: d.channels,
+		Precision:   d.bytes,
+	}
+	return d, format, nil
+}
+
+// parseComm reads the common chunk and returns the sample rate
+func (d *decoder) parseComm(chunk []byte, aifc bool) (float64, error) {
+	if len(chunk) < 18 {
+		return 0, fmt.Errorf("aiff: COMM chunk too short")
+	}
+	d.channels = int(binary.BigEndian.Uint16(chunk[0:]))
+	d.frames = int(binary.BigEndian.Uint32(chunk[2:]))
+	d.bits = int(binary.BigEndian.Uint16(chunk[6:]))
+	sampleRate := extended(chunk[8:18])
+
+	d.enc = bigEndianPCM
+	if aifc {
+		if len(chunk) < 22 {
+			return 0, fmt.Errorf("aiff: AIFC COMM chunk too short")
+		}
+		switch compression := string(chunk[18:22]); compression {
+		case "NONE", "twos":
+		case "sowt":
+			d.enc = littleEndianPCM
+		case "fl32", "FL32":
+			d.enc, d.bits = float32BE, 32
+		case "fl64", "FL64":
+			d.enc, d.bits = float64BE, 64
+		default:
+			return 0, fmt.Errorf("aiff: unsupported compression %q", compression)
+		}
+	}
+
+	if d.channels < 1 {
+		return 0, fmt.Errorf("aiff: invalid number of channels %d", d.channels)
+	}
+	if d.bits < 1 || d.bits > 64 {
+		return 0, fmt.Errorf("aiff: invalid sample size %d", d.bits)
+	}
+	if sampleRate <= 0 || math.IsInf(sampleRate, 0) || math.IsNaN(sampleRate) {
+		return 0, fmt.Errorf("aiff: invalid sample rate")
+	}
+	d.bytes = (d.bits + 7) / 8
+	return sampleRate, nil
+}
+
+// extended converts an 80 bit IEEE 754 extended precision number
+func extended(b []byte) float64 {
+	exponent := int(binary.BigEndian.Uint16(b[0:]) & 0x7fff)
+	mantissa := binary.BigEndian.Uint64(b[2:])
+	if exponent == 0 && mantissa == 0 {
+		return 0
+	}
+	v := math.Ldexp(float64(mantissa), exponent-16383-63)
+	if b[0]&0x80 != 0 {
+		v = -v
+	}
+	return v
+}
+
+// Stream fills samples with the first two channels, mono is copied to both
+func (d *decoder) Stream(samples [][2]float64) (n int, ok bool) {
+	buf, n := d.readFrames(len(samples))
+	frameBytes := d.channels * d.bytes
+	for i := 0; i < n; i++ {
+		frame := buf[i*frameBytes:]
+		left := d.sample(frame)
+		right := left
+		if d.channels > 1 {
+			right = d.sample(frame[d.bytes:])
+		}
+		samples[i] = [2]float64{left, right}
+	}
+	return n, n > 0
+}
+
+// StreamChannels fills samples with all channels, samples[i] needs one value
+// per channel
+func (d *decoder) StreamChannels(samples [][]float64) (n int, ok bool) {
+	buf, n := d.readFrames(len(samples))
+	frameBytes := d.channels * d.bytes
+	for i := 0; i < n; i++ {
+		for c := 0; c < d.channels; c++ {
+			samples[i][c] = d.sample(buf[i*frameBytes+c*d.bytes:])
+		}
+	}
+	return n, n > 0
+}
+
+// readFrames reads up to count sample frames and returns their bytes
+func (d *decoder) readFrames(count int) (buf []byte, n int) {
+	if d.err != nil || d.pos >= d.frames {
+		return nil, 0
+	}
+	frameBytes := d.channels * d.bytes
+	count = min(count, d.frames-d.pos)
+	if cap(d.buf) < count*frameBytes {
+		d.buf = make([]byte, count*frameBytes)
+	}
+	buf = d.buf[:count*frameBytes]
+	read, err := io.ReadFull(d.br, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		d.err = fmt.Errorf("aiff: %w", err)
+	}
+	n = read / frameBytes
+	d.pos += n
+	return buf, n
+}
+
+// sample decodes a single sample in [-1, 1]
+func (d *decoder) sample(b []byte) float64 {
+	switch d.enc {
+	case float32BE:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case float64BE:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+
+	// PCM samples are left aligned in d.bytes bytes
+	var v uint64
+	for i := 0; i < d.bytes; i++ {
+		shift := 8 * (d.bytes - 1 - i)
+		if d.enc == littleEndianPCM {
+			shift = 8 * i
+		}
+		v |= uint64(b[i]) << shift
+	}
+	signed := int64(v<<(64-8*d.bytes)) >> (64 - 8*d.bytes)
+	return float64(signed) / float64(int64(1)<<(8*d.bytes-1))
+}
+
+func (d *decoder) Err() error {
+	return d.err
+}
+
+func (d *decoder) Len() int {
+	return d.frames
+}
+
+func (d *decoder) Position() int {
+	return d.pos
+}
+
+func (d *decoder) Seek(p int) error {
+	seeker, ok := d.r.(io.Seeker)
+	if !ok {
+		return fmt.Errorf("aiff: seek: resource is not io.Seeker")
+	}
+	if p < 0 || p > d.frames {
+		return fmt.Errorf("aiff: seek position %v out of range [%v, %v]", p, 0, d.frames)
+	}
+	offset := d.dataOffset + int64(p*d.channels*d.bytes)
+	if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("aiff: seek error: %w", err)
+	}
+	d.br.Reset(d.r)
+	d.pos = p
+	return nil
+}
+
+func (d *decoder) Close() error {
+	if closer, ok := d.r.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("aiff: %w", err)
+		}
+	}
+	return nil
+}
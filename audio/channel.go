@@ -0,0 +1,212 @@
+//go:build !tinygo
+
+package audio
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/faiface/beep"
+)
+
+// Channel selects the signal that is analyzed from a multi-channel file.
+// Non-negative values select a single channel by its index.
+type Channel int
+
+const (
+	Mid   Channel = -1 // average of left and right, the default mono downmix
+	Side  Channel = -2 // half the difference of left and right, i.e. out-of-phase content
+	Left  Channel = 0
+	Right Channel = 1
+)
+
+func (c Channel) String() string {
+	switch c {
+	case Mid:
+		return "mid"
+	case Side:
+		return "side"
+	case Left:
+		return "left"
+	case Right:
+		return "right"
+	}
+	return strconv.Itoa(int(c))
+}
+
+// ParseChannel parses mid, side, left, right or a channel index
+func ParseChannel(s string) (Channel, error) {
+	switch strings.ToLower(s) {
+	case "mid", "mono", "mix":
+		return Mid, nil
+	case "side":
+		return Side, nil
+	case "left", "l":
+		return Left, nil
+	case "right", "r":
+		return Right, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid channel %q", s)
+	}
+	return Channel(n), nil
+}
+
+// ChannelStreamer is implemented by decoders that give access to all channels,
+// also of files with more than two channels
+type ChannelStreamer interface {
+	beep.Streamer
+
+	// StreamChannels is like Stream, but samples[i] receives one value per
+	// channel and needs at least beep.Format.NumChannels elements
+	StreamChannels(samples [][]float64) (n int, ok bool)
+}
+
+// SelectChannel returns the signal selected by c from channels as returned by
+// LoadChannels. Mid and side of a mono file are the mono signal and silence.
+func SelectChannel(channels [][]float64, c Channel) ([]float64, error) {
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("no channels")
+	}
+	left, right := channels[0], channels[0]
+	if len(channels) > 1 {
+		right = channels[1]
+	}
+
+	switch {
+	case c == Mid || c == Side:
+		out := make([]float64, len(left))
+		for i := range out {
+			if c == Mid {
+				out[i] = (left[i] + right[i]) / 2
+			} else {
+				out[i] = (left[i] - right[i]) / 2
+			}
+		}
+		return out, nil
+	case c >= 0 && int(c) < len(channels):
+		return channels[c], nil
+	}
+	return nil, fmt.Errorf("channel %v does not exist in a file with %d channels", c, len(channels))
+}
+
+// LoadChannels is like LoadRange but returns every channel separately instead
+// of a mono downmix. All channels of files with more than two channels are
+// only available from decoders implementing ChannelStreamer (FLAC, AIFF and
+// raw PCM); the others return at most two.
+func LoadChannels(r io.Reader, hint Format, start, length time.Duration) (channels [][]float64, sampleRate int, err error) {
+	return LoadChannelsProgress(r, hint, start, length, nil)
+}
+
+// LoadChannelsProgress is LoadChannels reporting the decoded samples to fn,
+// see ReadChannelsRangeProgress
+func LoadChannelsProgress(r io.Reader, hint Format, start, length time.Duration, fn dft.ProgressFunc) (channels [][]float64, sampleRate int, err error) {
+	seekable := false
+	if rs, ok := r.(io.ReadSeeker); ok {
+		r = struct{ io.ReadSeeker }{rs} // hide Close from the decoders
+		seekable = true
+	} else {
+		r = struct{ io.Reader }{r}
+	}
+
+	streamer, format, err := Decode(r, hint)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer streamer.Close()
+
+	if !seekable {
+		// Hide Seek, the decoders cannot seek without io.Seeker
+		if cs, ok := streamer.(ChannelStreamer); ok {
+			return ReadChannelsRangeProgress(struct{ ChannelStreamer }{cs}, format, start, length, fn)
+		}
+		return ReadChannelsRangeProgress(struct{ beep.Streamer }{streamer}, format, start, length, fn)
+	}
+	return ReadChannelsRangeProgress(streamer, format, start, length, fn)
+}
+
+// ReadChannelsRange is like ReadMonoRange but returns every channel separately
+func ReadChannelsRange(streamer beep.Streamer, format beep.Format, start, length time.Duration) (channels [][]float64, sampleRate int, err error) {
+	return ReadChannelsRangeProgress(streamer, format, start, length, nil)
+}
+
+// ReadChannelsRangeProgress is ReadChannelsRange calling fn, if not nil, after
+// every block of decoded samples. The total is the length of the range, or the
+// rest of the stream if streamer implements beep.StreamSeeker, otherwise it is
+// unknown.
+func ReadChannelsRangeProgress(streamer beep.Streamer, format beep.Format, start, length time.Duration, fn dft.ProgressFunc) (channels [][]float64, sampleRate int, err error) {
+	if err := skipTo(streamer, format, start); err != nil {
+		return nil, 0, err
+	}
+	begin := time.Now()
+	limit := -1
+	total := 0
+	if length > 0 {
+		limit = format.SampleRate.N(length)
+		total = limit
+	}
+	if seeker, ok := streamer.(beep.StreamSeeker); ok && seeker.Len() > 0 {
+		if rest := seeker.Len() - seeker.Position(); total == 0 || rest < total {
+			total = rest
+		}
+	}
+
+	numChannels := min(format.NumChannels, 2)
+	cs, multi := streamer.(ChannelStreamer)
+	if multi {
+		numChannels = format.NumChannels
+	}
+	if numChannels < 1 {
+		return nil, 0, fmt.Errorf("invalid number of channels %d", format.NumChannels)
+	}
+	channels = make([][]float64, numChannels)
+
+	const block = 4096
+	stereo := make([][2]float64, block)
+	frames := make([][]float64, block)
+	for i := range frames {
+		frames[i] = make([]float64, numChannels)
+	}
+	for limit != 0 {
+		count := block
+		if limit > 0 {
+			count = min(count, limit)
+		}
+
+		var n int
+		var ok bool
+		if multi {
+			n, ok = cs.StreamChannels(frames[:count])
+			for i := 0; i < n; i++ {
+				for c := range channels {
+					channels[c] = append(channels[c], frames[i][c])
+				}
+			}
+		} else {
+			n, ok = streamer.Stream(stereo[:count])
+			for i := 0; i < n; i++ {
+				for c := range channels {
+					channels[c] = append(channels[c], stereo[i][c])
+				}
+			}
+		}
+		if limit > 0 {
+			limit -= n
+		}
+		if fn != nil {
+			fn(dft.Progress{Done: int64(len(channels[0])), Total: int64(total), Elapsed: time.Since(begin)})
+		}
+		if !ok {
+			break
+		}
+	}
+	if err := streamer.Err(); err != nil {
+		return nil, 0, err
+	}
+	return channels, int(format.SampleRate), nil
+}
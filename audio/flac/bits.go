@@ -0,0 +1,106 @@
+package flac
+
+import (
+	"bufio"
+	"io"
+)
+
+// bitReader reads big-endian bit fields from a byte stream
+type bitReader struct {
+	r     *bufio.Reader
+	cache uint64 // unread bits, right aligned
+	n     uint   // number of unread bits in cache
+	crc16 uint16 // CRC-16 of the bytes read since the last reset
+}
+
+func newBitReader(r io.Reader) *bitReader {
+	return &bitReader{r: bufio.NewReaderSize(r, 1<<16)}
+}
+
+func (br *bitReader) reset(r io.Reader) {
+	br.r.Reset(r)
+	br.cache, br.n = 0, 0
+}
+
+func (br *bitReader) readByte() (byte, error) {
+	b, err := br.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	br.crc16 = br.crc16<<8 ^ crc16Table[byte(br.crc16>>8)^b]
+	return b, nil
+}
+
+// read returns the next n <= 32 bits as an unsigned integer
+func (br *bitReader) read(n uint) (uint64, error) {
+	for br.n < n {
+		b, err := br.readByte()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		br.cache = br.cache<<8 | uint64(b)
+		br.n += 8
+	}
+	br.n -= n
+	v := br.cache >> br.n & (1<<n - 1)
+	return v, nil
+}
+
+// readSigned returns the next n bits as a two's complement integer
+func (br *bitReader) readSigned(n uint) (int64, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	v, err := br.read(n)
+	if err != nil {
+		return 0, err
+	}
+	return int64(v<<(64-n)) >> (64 - n), nil
+}
+
+// readUnary counts the zero bits before the next one bit
+func (br *bitReader) readUnary() (uint64, error) {
+	var count uint64
+	for {
+		if br.n == 0 {
+			b, err := br.readByte()
+			if err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return 0, err
+			}
+			br.cache, br.n = uint64(b), 8
+		}
+		bit := br.cache >> (br.n - 1) & 1
+		br.n--
+		if bit == 1 {
+			return count, nil
+		}
+		count++
+	}
+}
+
+// align drops the bits up to the next byte boundary
+func (br *bitReader) align() {
+	br.n -= br.n % 8
+}
+
+// crc16Table is the table of the CRC-16 used by FLAC frames (polynomial 0x8005)
+var crc16Table = func() (table [256]uint16) {
+	for i := range table {
+		crc := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x8005
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
@@ -0,0 +1,296 @@
+// Package flac decodes FLAC files into beep streamers, like the wav, mp3 and
+// vorbis packages of beep. It is written in plain Go without dependencies.
+package flac
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/faiface/beep"
+)
+
+// streamInfo is the content of the STREAMINFO metadata block
+type streamInfo struct {
+	sampleRate    int
+	channels      int
+	bitsPerSample uint
+	totalSamples  int // 0 if unknown
+}
+
+// seekPoint is an entry of the SEEKTABLE metadata block
+type seekPoint struct {
+	sample int   // first sample of the target frame
+	offset int64 // offset of the target frame from the first frame
+}
+
+type decoder struct {
+	r          io.Reader
+	br         *bitReader
+	info       streamInfo
+	seekTable  []seekPoint
+	headerSize int64 // offset of the first frame
+
+	block  frame // current frame
+	offset int   // position in the current frame
+	pos    int   // position in the stream
+	err    error
+}
+
+// Decode reads the metadata of a FLAC stream from r and returns a streamer for
+// its samples. Seeking requires r to implement io.Seeker. Close closes r if it
+// implements io.Closer.
+func Decode(r io.Reader) (s beep.StreamSeekCloser, format beep.Format, err error) {
+	d := &decoder{r: r}
+	defer func() {
+		if closer, ok := r.(io.Closer); ok && err != nil {
+			closer.Close()
+		}
+	}()
+
+	cr := &countingReader{r: r}
+	if err := d.readMetadata(cr); err != nil {
+		return nil, beep.Format{}, err
+	}
+	d.headerSize = cr.n
+	d.br = newBitReader(r)
+
+	format = beep.Format{
+		SampleRate:  beep.SampleRate(d.info.sampleRate),
+		NumChannels: d.info.channels,
+		Precision:   int(d.info.bitsPerSample+7) / 8,
+	}
+	return d, format, nil
+}
+
+// readMetadata parses the "fLaC" marker and the metadata blocks. An ID3v2 tag in
+// front of the marker is skipped.
+func (d *decoder) readMetadata(r io.Reader) error {
+	var marker [4]byte
+	if _, err := io.ReadFull(r, marker[:]); err != nil {
+		return fmt.Errorf("flac: %w", err)
+	}
+	if string(marker[:3]) == "ID3" {
+		var tag [6]byte
+		if _, err := io.ReadFull(r, tag[:]); err != nil {
+			return fmt.Errorf("flac: %w", err)
+		}
+		// The tag size is a 28 bit syncsafe integer
+		size := int64(tag[2])<<21 | int64(tag[3])<<14 | int64(tag[4])<<7 | int64(tag[5])
+		if _, err := io.CopyN(io.Discard, r, size); err != nil {
+			return fmt.Errorf("flac: %w", err)
+		}
+		if _, err := io.ReadFull(r, marker[:]); err != nil {
+			return fmt.Errorf("flac: %w", err)
+		}
+	}
+	if string(marker[:]) != "fLaC" {
+		return fmt.Errorf("flac: missing fLaC marker")
+	}
+
+	hasInfo := false
+	for last := false; !last; {
+		var header [4]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return fmt.Errorf("flac: %w", err)
+		}
+		last = header[0]&0x80 != 0
+		kind := header[0] & 0x7f
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+
+		if kind == 3 {
+			if err := d.readSeekTable(r, length); err != nil {
+				return err
+			}
+			continue
+		}
+		if kind != 0 {
+			if _, err := io.CopyN(io.Discard, r, length); err != nil {
+				return fmt.Errorf("flac: %w", err)
+			}
+			continue
+		}
+		if length != 34 {
+			return fmt.Errorf("flac: invalid STREAMINFO length %d", length)
+		}
+		var block [34]byte
+		if _, err := io.ReadFull(r, block[:]); err != nil {
+			return fmt.Errorf("flac: %w", err)
+		}
+		// Bytes 10..17 hold 20 bits sample rate, 3 bits channels-1, 5 bits
+		// bits per sample-1 and 36 bits total samples
+		v := binary.BigEndian.Uint64(block[10:18])
+		d.info = streamInfo{
+			sampleRate:    int(v >> 44),
+			channels:      int(v>>41&0x7) + 1,
+			bitsPerSample: uint(v>>36&0x1f) + 1,
+			totalSamples:  int(v & (1<<36 - 1)),
+		}
+		hasInfo = true
+	}
+	if !hasInfo {
+		return fmt.Errorf("flac: missing STREAMINFO block")
+	}
+	if d.info.sampleRate == 0 {
+		return fmt.Errorf("flac: invalid sample rate")
+	}
+	return nil
+}
+
+// readSeekTable reads the seek points of a SEEKTABLE block, they are sorted by
+// sample number
+func (d *decoder) readSeekTable(r io.Reader, length int64) error {
+	block := make([]byte, length)
+	if _, err := io.ReadFull(r, block); err != nil {
+		return fmt.Errorf("flac: %w", err)
+	}
+	for i := 0; i+18 <= len(block); i += 18 {
+		sample := binary.BigEndian.Uint64(block[i:])
+		if sample == 1<<64-1 { // placeholder
+			continue
+		}
+		d.seekTable = append(d.seekTable, seekPoint{
+			sample: int(sample),
+			offset: int64(binary.BigEndian.Uint64(block[i+8:])),
+		})
+	}
+	return nil
+}
+
+// Stream fills samples with the first two channels, mono is copied to both
+func (d *decoder) Stream(samples [][2]float64) (n int, ok bool) {
+	scale := d.scale()
+	for n < len(samples) && d.nextFrame() {
+		left := d.block.samples[0]
+		right := left
+		if len(d.block.samples) > 1 {
+			right = d.block.samples[1]
+		}
+		for ; n < len(samples) && d.offset < len(left); n, d.offset = n+1, d.offset+1 {
+			samples[n][0] = float64(left[d.offset]) * scale
+			samples[n][1] = float64(right[d.offset]) * scale
+		}
+	}
+	d.pos += n
+	return n, n > 0
+}
+
+// StreamChannels fills samples with all channels, samples[i] needs one value
+// per channel
+func (d *decoder) StreamChannels(samples [][]float64) (n int, ok bool) {
+	scale := d.scale()
+	for n < len(samples) && d.nextFrame() {
+		for ; n < len(samples) && d.offset < len(d.block.samples[0]); n, d.offset = n+1, d.offset+1 {
+			for c, channel := range d.block.samples {
+				samples[n][c] = float64(channel[d.offset]) * scale
+			}
+		}
+	}
+	d.pos += n
+	return n, n > 0
+}
+
+// nextFrame makes sure the current frame has samples left, decoding the next
+// frame if needed. It returns false at the end of the stream or on errors.
+func (d *decoder) nextFrame() bool {
+	if d.err != nil {
+		return false
+	}
+	if d.block.samples != nil && d.offset < len(d.block.samples[0]) {
+		return true
+	}
+	f, err := d.readFrame()
+	if err != nil {
+		if err != io.EOF {
+			d.err = err
+		}
+		return false
+	}
+	d.block, d.offset = f, 0
+	return true
+}
+
+// scale converts integer samples to [-1, 1]
+func (d *decoder) scale() float64 {
+	return 1 / float64(int64(1)<<(d.info.bitsPerSample-1))
+}
+
+func (d *decoder) Err() error {
+	return d.err
+}
+
+// Len returns the total number of samples from the STREAMINFO block, which
+// is 0 if the encoder did not know it
+func (d *decoder) Len() int {
+	return d.info.totalSamples
+}
+
+func (d *decoder) Position() int {
+	return d.pos
+}
+
+// Seek moves to sample p. It jumps to the closest preceding point of the seek
+// table, if the file has one, and decodes the frames from there. Without a
+// seek table it decodes from the first frame, so a seek costs time in
+// proportion to p, up to decoding the whole file.
+func (d *decoder) Seek(p int) error {
+	seeker, ok := d.r.(io.Seeker)
+	if !ok {
+		return fmt.Errorf("flac: seek: resource is not io.Seeker")
+	}
+	if p < 0 || (d.info.totalSamples > 0 && p > d.info.totalSamples) {
+		return fmt.Errorf("flac: seek position %v out of range [%v, %v]", p, 0, d.info.totalSamples)
+	}
+	var point seekPoint
+	for _, sp := range d.seekTable {
+		if sp.sample > p {
+			break
+		}
+		point = sp
+	}
+	if _, err := seeker.Seek(d.headerSize+point.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("flac: seek error: %w", err)
+	}
+	d.br.reset(d.r)
+	d.block, d.offset, d.pos, d.err = frame{}, 0, point.sample, nil
+
+	for d.pos < p {
+		f, err := d.readFrame()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			d.err = err
+			return err
+		}
+		size := len(f.samples[0])
+		if d.pos+size > p {
+			d.block, d.offset = f, p-d.pos
+			d.pos = p
+			break
+		}
+		d.pos += size
+	}
+	return nil
+}
+
+func (d *decoder) Close() error {
+	if closer, ok := d.r.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("flac: %w", err)
+		}
+	}
+	return nil
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
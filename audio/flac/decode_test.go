@@ -0,0 +1,171 @@
+package flac
+
+import (
+	"bytes"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// testSignal returns two 16 bit channels of noisy sines, with the lowest
+// bits cleared if wasted > 0
+func testSignal(n int, wasted uint) [][]int64 {
+	rng := rand.New(rand.NewPCG(1, 2))
+	left, right := make([]int64, n), make([]int64, n)
+	for i := range left {
+		left[i] = int64(12000*math.Sin(2*math.Pi*440*float64(i)/44100)) + rng.Int64N(101) - 50
+		right[i] = int64(-32768 + rng.Int64N(65536)) // full scale noise
+		if i%3 == 0 {
+			right[i] = int64(8000 * math.Sin(2*math.Pi*660*float64(i)/44100))
+		}
+		left[i] &^= 1<<wasted - 1
+		right[i] &^= 1<<wasted - 1
+	}
+	return [][]int64{left, right}
+}
+
+// decodeAll decodes a stream and returns its samples as integers
+func decodeAll(t *testing.T, stream []byte) [][]int64 {
+	t.Helper()
+	s, format, err := Decode(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format.SampleRate != 44100 || format.NumChannels != 2 || format.Precision != 2 {
+		t.Fatalf("format %+v", format)
+	}
+	out := [][]int64{{}, {}}
+	buf := make([][2]float64, 300)
+	for {
+		n, ok := s.Stream(buf)
+		for _, v := range buf[:n] {
+			out[0] = append(out[0], int64(v[0]*32768))
+			out[1] = append(out[1], int64(v[1]*32768))
+		}
+		if !ok {
+			break
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func compareSamples(t *testing.T, got, want [][]int64) {
+	t.Helper()
+	for c := range want {
+		if len(got[c]) != len(want[c]) {
+			t.Fatalf("channel %d: %d samples, want %d", c, len(got[c]), len(want[c]))
+		}
+		for i := range want[c] {
+			if got[c][i] != want[c][i] {
+				t.Fatalf("channel %d, sample %d: %d, want %d", c, i, got[c][i], want[c][i])
+			}
+		}
+	}
+}
+
+func TestDecodeFrameTypes(t *testing.T) {
+	fixed := func(order int) subframeSpec {
+		return subframeSpec{kind: fixedSubframe, order: order, partitionOrder: 2}
+	}
+	lpc := subframeSpec{
+		kind: lpcSubframe, order: 3, coeffs: []int64{1900, -1200, 250},
+		precision: 13, shift: 10, partitionOrder: 3,
+	}
+	escaped := lpc
+	escaped.escape = true
+	rice5 := fixed(2)
+	rice5.riceParamBits = 5
+
+	for _, c := range []struct {
+		name   string
+		wasted uint
+		frames []frameSpec
+	}{
+		{"verbatim", 0, []frameSpec{{1, []subframeSpec{{kind: verbatimSubframe}, {kind: verbatimSubframe}}}}},
+		{"fixed", 0, []frameSpec{
+			{1, []subframeSpec{fixed(0), fixed(1)}},
+			{1, []subframeSpec{fixed(2), fixed(3)}},
+			{1, []subframeSpec{fixed(4), rice5}},
+		}},
+		{"lpc", 0, []frameSpec{{1, []subframeSpec{lpc, escaped}}}},
+		{"wasted bits", 3, []frameSpec{
+			{1, []subframeSpec{{kind: fixedSubframe, order: 2, wasted: 3}, {kind: verbatimSubframe, wasted: 2}}},
+			{1, []subframeSpec{{kind: lpcSubframe, order: 3, coeffs: lpc.coeffs, precision: 13, shift: 10, wasted: 3}, fixed(1)}},
+			{midSide, []subframeSpec{{kind: fixedSubframe, order: 2, wasted: 2}, {kind: verbatimSubframe, wasted: 3}}},
+		}},
+		{"left/side", 0, []frameSpec{{leftSide, []subframeSpec{fixed(2), lpc}}}},
+		{"side/right", 0, []frameSpec{{sideRight, []subframeSpec{lpc, fixed(2)}}}},
+		{"mid/side", 0, []frameSpec{
+			{midSide, []subframeSpec{fixed(2), fixed(1)}},
+			{midSide, []subframeSpec{lpc, {kind: verbatimSubframe}}},
+		}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			// The last frame of 100 samples is shorter than the others
+			signal := testSignal(5*1024+100, c.wasted)
+			stream := encodeStream(signal, 44100, 16, 1024, c.frames, 0)
+			compareSamples(t, decodeAll(t, stream), signal)
+		})
+	}
+
+	t.Run("constant", func(t *testing.T) {
+		signal := [][]int64{make([]int64, 1000), make([]int64, 1000)}
+		for i := range signal[0] {
+			signal[0][i], signal[1][i] = -1234, 32767
+		}
+		constant := subframeSpec{kind: constantSubframe}
+		stream := encodeStream(signal, 44100, 16, 256, []frameSpec{{1, []subframeSpec{constant, constant}}}, 0)
+		compareSamples(t, decodeAll(t, stream), signal)
+	})
+}
+
+func TestDecodeCRCMismatch(t *testing.T) {
+	signal := testSignal(2048, 0)
+	fixed := subframeSpec{kind: fixedSubframe, order: 2}
+	stream := encodeStream(signal, 44100, 16, 1024, []frameSpec{{1, []subframeSpec{fixed, fixed}}}, 0)
+	stream[len(stream)-10] ^= 0x10
+
+	s, _, err := Decode(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([][2]float64, 4096)
+	n, _ := s.Stream(buf)
+	if n != 1024 || s.Err() == nil {
+		t.Errorf("decoded %d samples with error %v, want 1024 and a CRC mismatch", n, s.Err())
+	}
+}
+
+func TestSeek(t *testing.T) {
+	signal := testSignal(20*256+17, 0)
+	fixed := subframeSpec{kind: fixedSubframe, order: 2, partitionOrder: 1}
+	specs := []frameSpec{{midSide, []subframeSpec{fixed, fixed}}}
+	for _, seekEvery := range []int{0, 4} {
+		stream := encodeStream(signal, 44100, 16, 256, specs, seekEvery)
+		s, _, err := Decode(bytes.NewReader(stream))
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := make([][2]float64, 10)
+		for _, p := range []int{3000, 0, 1024, 1023, 5136, 255, 4000} {
+			if err := s.Seek(p); err != nil {
+				t.Fatalf("seek table every %d frames, seek to %d: %v", seekEvery, p, err)
+			}
+			n, _ := s.Stream(buf)
+			for i, v := range buf[:n] {
+				if got, want := int64(v[0]*32768), signal[0][p+i]; got != want {
+					t.Fatalf("seek table every %d frames, sample %d after seeking to %d: %d, want %d", seekEvery, p+i, p, got, want)
+				}
+			}
+			if want := min(10, len(signal[0])-p); n != want {
+				t.Errorf("seek table every %d frames: %d samples after seeking to %d, want %d", seekEvery, n, p, want)
+			}
+			if s.Position() != p+n {
+				t.Errorf("position %d after reading %d samples at %d", s.Position(), n, p)
+			}
+		}
+	}
+}
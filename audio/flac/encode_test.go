@@ -0,0 +1,294 @@
+package flac
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// The encoder of the tests writes FLAC streams with chosen frame and
+// subframe types, so that every decoding path is covered by a known input.
+
+// bitWriter writes big-endian bit fields
+type bitWriter struct {
+	buf []byte
+	n   uint // number of bits used in the last byte, 0 if it is full
+}
+
+func (w *bitWriter) write(v uint64, n uint) {
+	for i := int(n) - 1; i >= 0; i-- {
+		if w.n == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		w.buf[len(w.buf)-1] |= byte(v>>uint(i)&1) << (7 - w.n)
+		w.n = (w.n + 1) % 8
+	}
+}
+
+func (w *bitWriter) writeSigned(v int64, n uint) {
+	w.write(uint64(v)&(1<<n-1), n)
+}
+
+func (w *bitWriter) writeUnary(q uint64) {
+	for ; q > 0; q-- {
+		w.write(0, 1)
+	}
+	w.write(1, 1)
+}
+
+// subframeSpec selects the coding of a subframe
+type subframeSpec struct {
+	kind           int     // subframe type: constant, verbatim, fixed or lpc
+	order          int     // predictor order
+	coeffs         []int64 // LPC coefficients
+	precision      uint    // LPC coefficient precision
+	shift          uint    // LPC quantization shift
+	wasted         uint    // wasted bits per sample
+	partitionOrder uint    // residual partition order
+	riceParamBits  uint    // 4 or 5
+	escape         bool    // store the first partition unencoded
+}
+
+const (
+	constantSubframe = iota
+	verbatimSubframe
+	fixedSubframe
+	lpcSubframe
+)
+
+// frameSpec selects the coding of a frame
+type frameSpec struct {
+	channelCode int            // independent channels-1, leftSide, sideRight or midSide
+	subframes   []subframeSpec // one per channel
+}
+
+// encodeStream returns a FLAC stream of channels with a frame per spec of
+// blockSize samples each, the last frame holds the rest. With seekEvery > 0
+// a seek table with a point at every seekEvery-th frame is written.
+func encodeStream(channels [][]int64, sampleRate int, bps uint, blockSize int, specs []frameSpec, seekEvery int) []byte {
+	total := len(channels[0])
+	var frames [][]byte
+	for start, i := 0, 0; start < total; start, i = start+blockSize, i+1 {
+		end := min(start+blockSize, total)
+		block := make([][]int64, len(channels))
+		for c := range channels {
+			block[c] = channels[c][start:end]
+		}
+		frames = append(frames, encodeFrame(block, i, bps, specs[i%len(specs)]))
+	}
+
+	out := []byte("fLaC")
+	info := make([]byte, 34)
+	binary.BigEndian.PutUint16(info[0:], uint16(blockSize))
+	binary.BigEndian.PutUint16(info[2:], uint16(blockSize))
+	binary.BigEndian.PutUint64(info[10:], uint64(sampleRate)<<44|uint64(len(channels)-1)<<41|uint64(bps-1)<<36|uint64(total))
+	last := byte(0x80)
+	if seekEvery > 0 {
+		last = 0
+	}
+	out = append(out, last|0, 0, 0, 34)
+	out = append(out, info...)
+
+	if seekEvery > 0 {
+		var table []byte
+		offset := 0
+		for i, f := range frames {
+			if i%seekEvery == 0 {
+				table = binary.BigEndian.AppendUint64(table, uint64(i*blockSize))
+				table = binary.BigEndian.AppendUint64(table, uint64(offset))
+				table = binary.BigEndian.AppendUint16(table, uint16(blockSize))
+			}
+			offset += len(f)
+		}
+		// Placeholder point
+		table = binary.BigEndian.AppendUint64(table, 1<<64-1)
+		table = append(table, make([]byte, 10)...)
+		out = append(out, 0x80|3, byte(len(table)>>16), byte(len(table)>>8), byte(len(table)))
+		out = append(out, table...)
+	}
+
+	for _, f := range frames {
+		out = append(out, f...)
+	}
+	return out
+}
+
+func encodeFrame(block [][]int64, number int, bps uint, spec frameSpec) []byte {
+	w := &bitWriter{}
+	w.write(0x7ffc, 15)
+	w.write(0, 1) // fixed block size
+	w.write(7, 4) // 16 bit block size at the end of the header
+	w.write(0, 4) // sample rate of the STREAMINFO block
+	w.write(uint64(spec.channelCode), 4)
+	w.write(0, 3) // sample size of the STREAMINFO block
+	w.write(0, 1)
+	w.write(uint64(number), 8) // frame number < 128 as 1 byte UTF-8
+	w.write(uint64(len(block[0])-1), 16)
+	w.write(uint64(crc8(w.buf)), 8)
+
+	channels := correlate(block, spec.channelCode)
+	for c, samples := range channels {
+		depth := bps
+		if (spec.channelCode == leftSide || spec.channelCode == midSide) && c == 1 ||
+			spec.channelCode == sideRight && c == 0 {
+			depth++
+		}
+		encodeSubframe(w, samples, depth, spec.subframes[c])
+	}
+
+	out := w.buf
+	var crc uint16
+	for _, b := range out {
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^b]
+	}
+	return binary.BigEndian.AppendUint16(out, crc)
+}
+
+// correlate is the inverse of decorrelate
+func correlate(block [][]int64, channelCode int) [][]int64 {
+	if channelCode < leftSide {
+		return block
+	}
+	left, right := block[0], block[1]
+	a, b := make([]int64, len(left)), make([]int64, len(left))
+	for i := range left {
+		side := left[i] - right[i]
+		switch channelCode {
+		case leftSide:
+			a[i], b[i] = left[i], side
+		case sideRight:
+			a[i], b[i] = side, right[i]
+		case midSide:
+			a[i], b[i] = (left[i]+right[i])>>1, side
+		}
+	}
+	return [][]int64{a, b}
+}
+
+func encodeSubframe(w *bitWriter, samples []int64, bps uint, spec subframeSpec) {
+	kind := map[int]uint64{
+		constantSubframe: 0,
+		verbatimSubframe: 1,
+		fixedSubframe:    8 + uint64(spec.order),
+		lpcSubframe:      31 + uint64(spec.order),
+	}[spec.kind]
+	w.write(0, 1)
+	w.write(kind, 6)
+	if spec.wasted > 0 {
+		w.write(1, 1)
+		w.writeUnary(uint64(spec.wasted - 1))
+		bps -= spec.wasted
+		shifted := make([]int64, len(samples))
+		for i, v := range samples {
+			shifted[i] = v >> spec.wasted
+		}
+		samples = shifted
+	} else {
+		w.write(0, 1)
+	}
+
+	switch spec.kind {
+	case constantSubframe:
+		w.writeSigned(samples[0], bps)
+		return
+	case verbatimSubframe:
+		for _, v := range samples {
+			w.writeSigned(v, bps)
+		}
+		return
+	}
+
+	coeffs, shift := fixedCoefficients[spec.order], uint(0)
+	if spec.kind == lpcSubframe {
+		coeffs, shift = spec.coeffs, spec.shift
+	}
+	for _, v := range samples[:spec.order] {
+		w.writeSigned(v, bps)
+	}
+	if spec.kind == lpcSubframe {
+		w.write(uint64(spec.precision-1), 4)
+		w.writeSigned(int64(shift), 5)
+		for _, c := range coeffs {
+			w.writeSigned(c, spec.precision)
+		}
+	}
+	residual := make([]int64, 0, len(samples))
+	for i := spec.order; i < len(samples); i++ {
+		var sum int64
+		for j, c := range coeffs {
+			sum += c * samples[i-1-j]
+		}
+		residual = append(residual, samples[i]-sum>>shift)
+	}
+	encodeResidual(w, residual, len(samples), spec)
+}
+
+func encodeResidual(w *bitWriter, residual []int64, blockSize int, spec subframeSpec) {
+	paramBits, escape := uint(4), uint64(15)
+	if spec.riceParamBits == 5 {
+		paramBits, escape = 5, 31
+		w.write(1, 2)
+	} else {
+		w.write(0, 2)
+	}
+	// Like encoders do, the partition order is lowered for blocks that it
+	// does not divide, e.g. the last one
+	order := spec.partitionOrder
+	for order > 0 && (blockSize%(1<<order) != 0 || blockSize>>order < spec.order) {
+		order--
+	}
+	w.write(uint64(order), 4)
+	partitions := 1 << order
+	for p := 0; p < partitions; p++ {
+		n := blockSize / partitions
+		if p == 0 {
+			n -= spec.order
+		}
+		part := residual[:n]
+		residual = residual[n:]
+
+		if spec.escape && p == 0 {
+			w.write(escape, paramBits)
+			w.write(24, 5)
+			for _, v := range part {
+				w.writeSigned(v, 24)
+			}
+			continue
+		}
+		// Rice parameter from the mean magnitude
+		var sum uint64
+		for _, v := range part {
+			sum += zigzag(v)
+		}
+		param := uint64(0)
+		if len(part) > 0 && sum/uint64(len(part)) > 0 {
+			param = uint64(bits.Len64(sum/uint64(len(part)))) - 1
+		}
+		param = min(param, escape-1)
+		w.write(param, paramBits)
+		for _, v := range part {
+			z := zigzag(v)
+			w.writeUnary(z >> param)
+			w.write(z&(1<<param-1), uint(param))
+		}
+	}
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1 ^ v>>63)
+}
+
+// crc8 is the CRC-8 of frame headers (polynomial 0x07)
+func crc8(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,374 @@
+package flac
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// errSync is returned when no frame starts at the current position
+var errSync = errors.New("flac: missing frame sync code")
+
+// Channel assignments of a frame
+const (
+	leftSide  = 8
+	sideRight = 9
+	midSide   = 10
+)
+
+// frame is a decoded block of samples, one slice per channel
+type frame struct {
+	samples [][]int64
+}
+
+// readFrame decodes the next frame. It returns io.EOF at the end of the stream.
+func (d *decoder) readFrame() (frame, error) {
+	br := d.br
+	br.crc16 = 0
+
+	sync, err := br.read(15)
+	if err == io.ErrUnexpectedEOF {
+		return frame{}, io.EOF
+	}
+	if err != nil {
+		return frame{}, err
+	}
+	if sync != 0x7ffc {
+		return frame{}, errSync
+	}
+	if _, err := br.read(1); err != nil { // blocking strategy
+		return frame{}, err
+	}
+
+	header, err := br.read(16)
+	if err != nil {
+		return frame{}, err
+	}
+	blockCode := header >> 12
+	rateCode := header >> 8 & 0xf
+	channelCode := int(header >> 4 & 0xf)
+	sizeCode := header >> 1 & 0x7
+
+	// The frame or sample number is coded like UTF-8, it is not needed for decoding
+	first, err := br.read(8)
+	if err != nil {
+		return frame{}, err
+	}
+	for mask := uint64(0x40); first&0x80 != 0 && first&mask != 0; mask >>= 1 {
+		if _, err := br.read(8); err != nil {
+			return frame{}, err
+		}
+	}
+
+	blockSize := 0
+	switch {
+	case blockCode == 1:
+		blockSize = 192
+	case blockCode >= 2 && blockCode <= 5:
+		blockSize = 576 << (blockCode - 2)
+	case blockCode == 6:
+		v, err := br.read(8)
+		if err != nil {
+			return frame{}, err
+		}
+		blockSize = int(v) + 1
+	case blockCode == 7:
+		v, err := br.read(16)
+		if err != nil {
+			return frame{}, err
+		}
+		blockSize = int(v) + 1
+	case blockCode >= 8:
+		blockSize = 256 << (blockCode - 8)
+	default:
+		return frame{}, fmt.Errorf("flac: reserved block size")
+	}
+
+	switch rateCode {
+	case 12:
+		_, err = br.read(8)
+	case 13, 14:
+		_, err = br.read(16)
+	case 15:
+		err = fmt.Errorf("flac: invalid sample rate")
+	}
+	if err != nil {
+		return frame{}, err
+	}
+
+	bps := d.info.bitsPerSample
+	switch sizeCode {
+	case 0:
+	case 1:
+		bps = 8
+	case 2:
+		bps = 12
+	case 4:
+		bps = 16
+	case 5:
+		bps = 20
+	case 6:
+		bps = 24
+	case 7:
+		bps = 32
+	default:
+		return frame{}, fmt.Errorf("flac: reserved sample size")
+	}
+
+	if _, err := br.read(8); err != nil { // CRC-8 of the header
+		return frame{}, err
+	}
+
+	channels := channelCode + 1
+	if channelCode >= leftSide {
+		if channelCode > midSide {
+			return frame{}, fmt.Errorf("flac: reserved channel assignment %d", channelCode)
+		}
+		channels = 2
+	}
+
+	f := frame{samples: make([][]int64, channels)}
+	for ch := range f.samples {
+		depth := bps
+		// The side channel needs one extra bit
+		if (channelCode == leftSide || channelCode == midSide) && ch == 1 ||
+			channelCode == sideRight && ch == 0 {
+			depth++
+		}
+		f.samples[ch], err = d.readSubframe(blockSize, depth)
+		if err != nil {
+			return frame{}, err
+		}
+	}
+
+	br.align()
+	crc := br.crc16
+	footer, err := br.read(16)
+	if err != nil {
+		return frame{}, err
+	}
+	if uint16(footer) != crc {
+		return frame{}, fmt.Errorf("flac: frame CRC mismatch")
+	}
+
+	decorrelate(f.samples, channelCode)
+	return f, nil
+}
+
+// decorrelate restores left and right from stereo decorrelated channels
+func decorrelate(s [][]int64, channelCode int) {
+	switch channelCode {
+	case leftSide:
+		for i, side := range s[1] {
+			s[1][i] = s[0][i] - side
+		}
+	case sideRight:
+		for i, side := range s[0] {
+			s[0][i] = side + s[1][i]
+		}
+	case midSide:
+		for i, side := range s[1] {
+			mid := s[0][i]<<1 | side&1
+			s[0][i] = (mid + side) >> 1
+			s[1][i] = (mid - side) >> 1
+		}
+	}
+}
+
+// readSubframe decodes the samples of one channel
+func (d *decoder) readSubframe(blockSize int, bps uint) ([]int64, error) {
+	br := d.br
+	header, err := br.read(8)
+	if err != nil {
+		return nil, err
+	}
+	if header&0x80 != 0 {
+		return nil, fmt.Errorf("flac: invalid subframe padding")
+	}
+	kind := header >> 1 & 0x3f
+
+	wasted := uint(0)
+	if header&1 != 0 {
+		k, err := br.readUnary()
+		if err != nil {
+			return nil, err
+		}
+		wasted = uint(k) + 1
+		bps -= wasted
+	}
+
+	samples := make([]int64, blockSize)
+	switch {
+	case kind == 0: // constant
+		v, err := br.readSigned(bps)
+		if err != nil {
+			return nil, err
+		}
+		for i := range samples {
+			samples[i] = v
+		}
+	case kind == 1: // verbatim
+		for i := range samples {
+			if samples[i], err = br.readSigned(bps); err != nil {
+				return nil, err
+			}
+		}
+	case kind >= 8 && kind <= 12: // fixed predictor
+		err = d.readFixed(samples, int(kind-8), bps)
+	case kind >= 32: // linear predictor
+		err = d.readLPC(samples, int(kind-31), bps)
+	default:
+		err = fmt.Errorf("flac: reserved subframe type %d", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if wasted > 0 {
+		for i := range samples {
+			samples[i] <<= wasted
+		}
+	}
+	return samples, nil
+}
+
+// fixedCoefficients are the polynomial predictors of order 0 to 4
+var fixedCoefficients = [][]int64{{}, {1}, {2, -1}, {3, -3, 1}, {4, -6, 4, -1}}
+
+func (d *decoder) readFixed(samples []int64, order int, bps uint) error {
+	if order > len(samples) {
+		return fmt.Errorf("flac: predictor order %d exceeds block size", order)
+	}
+	for i := 0; i < order; i++ {
+		v, err := d.br.readSigned(bps)
+		if err != nil {
+			return err
+		}
+		samples[i] = v
+	}
+	if err := d.readResidual(samples, order); err != nil {
+		return err
+	}
+	predict(samples, fixedCoefficients[order], order, 0)
+	return nil
+}
+
+func (d *decoder) readLPC(samples []int64, order int, bps uint) error {
+	br := d.br
+	if order > len(samples) {
+		return fmt.Errorf("flac: predictor order %d exceeds block size", order)
+	}
+	for i := 0; i < order; i++ {
+		v, err := br.readSigned(bps)
+		if err != nil {
+			return err
+		}
+		samples[i] = v
+	}
+
+	precision, err := br.read(4)
+	if err != nil {
+		return err
+	}
+	if precision == 15 {
+		return fmt.Errorf("flac: invalid coefficient precision")
+	}
+	shift, err := br.readSigned(5)
+	if err != nil {
+		return err
+	}
+	if shift < 0 {
+		return fmt.Errorf("flac: negative predictor shift")
+	}
+	coeffs := make([]int64, order)
+	for i := range coeffs {
+		if coeffs[i], err = br.readSigned(uint(precision) + 1); err != nil {
+			return err
+		}
+	}
+
+	if err := d.readResidual(samples, order); err != nil {
+		return err
+	}
+	predict(samples, coeffs, order, uint(shift))
+	return nil
+}
+
+// predict adds the prediction from the previous samples to the residuals
+// stored in samples[order:]
+func predict(samples, coeffs []int64, order int, shift uint) {
+	for i := order; i < len(samples); i++ {
+		var sum int64
+		for j, c := range coeffs {
+			sum += c * samples[i-1-j]
+		}
+		samples[i] += sum >> shift
+	}
+}
+
+// readResidual decodes the Rice coded residuals into samples[order:]
+func (d *decoder) readResidual(samples []int64, order int) error {
+	br := d.br
+	method, err := br.read(2)
+	if err != nil {
+		return err
+	}
+	paramBits, escape := uint(4), uint64(15)
+	switch method {
+	case 0:
+	case 1:
+		paramBits, escape = 5, 31
+	default:
+		return fmt.Errorf("flac: reserved residual coding method")
+	}
+
+	partitionOrder, err := br.read(4)
+	if err != nil {
+		return err
+	}
+	partitions := 1 << partitionOrder
+	if len(samples)%partitions != 0 || len(samples)/partitions < order {
+		return fmt.Errorf("flac: invalid residual partition order %d", partitionOrder)
+	}
+
+	i := order
+	for p := 0; p < partitions; p++ {
+		n := len(samples) / partitions
+		if p == 0 {
+			n -= order
+		}
+		param, err := br.read(paramBits)
+		if err != nil {
+			return err
+		}
+
+		if param == escape {
+			bits, err := br.read(5)
+			if err != nil {
+				return err
+			}
+			for j := 0; j < n; j++ {
+				if samples[i], err = br.readSigned(uint(bits)); err != nil {
+					return err
+				}
+				i++
+			}
+			continue
+		}
+
+		for j := 0; j < n; j++ {
+			q, err := br.readUnary()
+			if err != nil {
+				return err
+			}
+			r, err := br.read(uint(param))
+			if err != nil {
+				return err
+			}
+			v := q<<param | r
+			samples[i] = int64(v>>1) ^ -int64(v&1)
+			i++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Package audio detects audio file formats and decodes them with the matching
+// decoder.
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Format is an audio container or codec
+type Format int
+
+const (
+	Unknown Format = iota
+	WAV
+	AIFF
+	MP3
+	Vorbis
+	FLAC
+)
+
+var formatNames = map[Format]string{
+	Unknown: "unknown",
+	WAV:     "wav",
+	AIFF:    "aiff",
+	MP3:     "mp3",
+	Vorbis:  "vorbis",
+	FLAC:    "flac",
+}
+
+func (f Format) String() string {
+	if name, ok := formatNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// sniffLen is the number of bytes Sniff inspects
+const sniffLen = 36
+
+// FormatFromExtension guesses the format from the file extension of path
+func FormatFromExtension(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav", ".wave":
+		return WAV
+	case ".aiff", ".aif", ".aifc":
+		return AIFF
+	case ".mp3":
+		return MP3
+	case ".ogg", ".oga":
+		return Vorbis
+	case ".flac":
+		return FLAC
+	}
+	return Unknown
+}
+
+// Detect identifies the format from the first bytes of a file by its magic
+// bytes. An ID3v2 tag has to be skipped before, see Sniff.
+func Detect(header []byte) Format {
+	switch {
+	case len(header) >= 12 && (bytes.HasPrefix(header, []byte("RIFF")) || bytes.HasPrefix(header, []byte("RF64"))) &&
+		string(header[8:12]) == "WAVE":
+		return WAV
+	case len(header) >= 12 && bytes.HasPrefix(header, []byte("FORM")) &&
+		(string(header[8:12]) == "AIFF" || string(header[8:12]) == "AIFC"):
+		return AIFF
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return FLAC
+	case bytes.HasPrefix(header, []byte("OggS")):
+		// The first page holds the identification header of the codec
+		if len(header) >= 35 && string(header[28:35]) == "\x01vorbis" {
+			return Vorbis
+		}
+	case len(header) >= 2 && header[0] == 0xff && header[1]&0xe0 == 0xe0:
+		// MPEG audio frame sync, layer bits 01 (layer III), 10 (II) or 11 (I)
+		if header[1]&0x06 != 0 {
+			return MP3
+		}
+	}
+	return Unknown
+}
+
+// Sniff detects the format of the data in r by its magic bytes and seeks r
+// back to where it was. ID3v2 tags, which may precede MP3 and FLAC data, are
+// skipped. It returns Unknown if no known signature was found.
+func Sniff(r io.ReadSeeker) (Format, error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return Unknown, err
+	}
+	defer r.Seek(start, io.SeekStart)
+
+	header := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return Unknown, err
+	}
+	header = header[:n]
+
+	if len(header) >= 10 && bytes.HasPrefix(header, []byte("ID3")) {
+		// The tag size is a 28 bit syncsafe integer, excluding the 10 byte header
+		size := int64(header[6])<<21 | int64(header[7])<<14 | int64(header[8])<<7 | int64(header[9])
+		if header[5]&0x10 != 0 { // footer present
+			size += 10
+		}
+		if _, err := r.Seek(start+10+size, io.SeekStart); err != nil {
+			return Unknown, err
+		}
+		header = header[:cap(header)]
+		n, err := io.ReadFull(r, header)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return Unknown, err
+		}
+		header = header[:n]
+	}
+	return Detect(header), nil
+}
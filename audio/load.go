@@ -0,0 +1,177 @@
+//go:build !tinygo
+
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/audio/aiff"
+	"github.com/epikur-io/go-discrete-fourier-transform/audio/flac"
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/mp3"
+	"github.com/faiface/beep/vorbis"
+	"github.com/faiface/beep/wav"
+)
+
+// Decode detects the format of r by its magic bytes and returns a streamer
+// from the matching decoder. If the format cannot be detected hint is used,
+// e.g. FormatFromExtension of the file name. Seeking the streamer requires r
+// to implement io.Seeker. Closing the streamer closes r if it implements
+// io.Closer.
+func Decode(r io.Reader, hint Format) (beep.StreamSeekCloser, beep.Format, error) {
+	var kind Format
+	if rs, ok := r.(io.ReadSeeker); ok {
+		var err error
+		if kind, err = Sniff(rs); err != nil {
+			return nil, beep.Format{}, err
+		}
+	} else {
+		br := bufio.NewReader(r)
+		header, err := br.Peek(sniffLen)
+		if err != nil && err != io.EOF {
+			return nil, beep.Format{}, err
+		}
+		kind = Detect(header)
+		if kind == Unknown && bytes.HasPrefix(header, []byte("ID3")) && hint == Unknown {
+			// Skipping the tag needs seeking, ID3v2 is mostly used with MP3
+			kind = MP3
+		}
+		r = readCloser{br, r}
+	}
+	if kind == Unknown {
+		kind = hint
+	}
+
+	switch kind {
+	case WAV:
+		streamer, format, err := wav.Decode(r)
+		if err != nil {
+			return nil, format, err
+		}
+		return wavScale(streamer, format), format, nil
+	case MP3:
+		return mp3.Decode(toReadCloser(r))
+	case Vorbis:
+		return vorbis.Decode(toReadCloser(r))
+	case FLAC:
+		return flac.Decode(r)
+	case AIFF:
+		return aiff.Decode(r)
+	}
+	return nil, beep.Format{}, fmt.Errorf("unsupported format")
+}
+
+// scaledStreamer multiplies the samples of a streamer by gain
+type scaledStreamer struct {
+	beep.StreamSeekCloser
+	gain float64
+}
+
+func (s scaledStreamer) Stream(samples [][2]float64) (int, bool) {
+	n, ok := s.StreamSeekCloser.Stream(samples)
+	for i := range samples[:n] {
+		samples[i][0] *= s.gain
+		samples[i][1] *= s.gain
+	}
+	return n, ok
+}
+
+// wavScale corrects the level of 16 and 24 bit WAV files: the beep decoder
+// divides the samples by 2^bits-1 instead of 2^(bits-1), which decodes them
+// at half of their amplitude
+func wavScale(s beep.StreamSeekCloser, format beep.Format) beep.StreamSeekCloser {
+	switch format.Precision {
+	case 2:
+		return scaledStreamer{s, float64(1<<16-1) / (1 << 15)}
+	case 3:
+		return scaledStreamer{s, float64(1<<24-1) / (1 << 23)}
+	}
+	return s
+}
+
+// LoadAudio decodes all samples of r, e.g. an HTTP response body, an embedded
+// file or a bytes.Reader, and returns them mixed to mono in [-1..1] together
+// with the sample rate (Hz) and the duration. See Decode for hint. r is not
+// closed.
+func LoadAudio(r io.Reader, hint Format) (mono []float64, sampleRate int, duration time.Duration, err error) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		r = struct{ io.ReadSeeker }{rs} // hide Close from the decoders
+	} else {
+		r = struct{ io.Reader }{r}
+	}
+
+	streamer, format, err := Decode(r, hint)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer streamer.Close()
+
+	return ReadMono(streamer, format)
+}
+
+// ReadMono reads all samples of streamer and mixes them to mono
+func ReadMono(streamer beep.Streamer, format beep.Format) (mono []float64, sampleRate int, duration time.Duration, err error) {
+	// buffer of stereo frames
+	buf := make([][2]float64, 4096)
+
+	for {
+		n, ok := streamer.Stream(buf)
+		for i := 0; i < n; i++ {
+			// mix stereo -> mono (average). Mono sources are copied to both channels.
+			mono = append(mono, (buf[i][0]+buf[i][1])/2)
+		}
+		if !ok {
+			break
+		}
+	}
+	if err := streamer.Err(); err != nil {
+		return nil, 0, 0, err
+	}
+
+	sampleRate = int(format.SampleRate)
+	if sampleRate <= 0 {
+		return nil, 0, 0, fmt.Errorf("invalid sample rate %d", sampleRate)
+	}
+
+	// compute duration from number of frames (mono length) and sample rate
+	duration = time.Duration(len(mono)) * time.Second / time.Duration(sampleRate)
+
+	return mono, sampleRate, duration, nil
+}
+
+// readCloser reads from a buffered reader and closes the underlying reader
+type readCloser struct {
+	io.Reader
+	src io.Reader
+}
+
+func (rc readCloser) Close() error {
+	if closer, ok := rc.src.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// toReadCloser adds a no-op Close to r if it has none, as required by the mp3
+// and vorbis decoders. An io.Seeker stays seekable.
+func toReadCloser(r io.Reader) io.ReadCloser {
+	if rc, ok := r.(io.ReadCloser); ok {
+		return rc
+	}
+	if rs, ok := r.(io.ReadSeeker); ok {
+		return nopSeekCloser{rs}
+	}
+	return io.NopCloser(r)
+}
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,39 @@
+//go:build !tinygo
+
+package audio
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+// The fixtures hold 480 samples of a 1 kHz sine at -6 dBFS (amplitude 0.5)
+// and 48 kHz, written with Python's wave module
+func TestDecodeWAVLevel(t *testing.T) {
+	for _, name := range []string{"sine-6dbfs-16bit.wav", "sine-6dbfs-24bit.wav"} {
+		f, err := os.Open("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mono, sampleRate, _, err := LoadAudio(f, Unknown)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if sampleRate != 48000 || len(mono) != 480 {
+			t.Fatalf("%s: got %d samples at %d Hz, want 480 at 48000 Hz", name, len(mono), sampleRate)
+		}
+		peak, sum := 0.0, 0.0
+		for _, v := range mono {
+			peak = math.Max(peak, math.Abs(v))
+			sum += v * v
+		}
+		if math.Abs(peak-0.5) > 1e-4 {
+			t.Errorf("%s: peak %.5f, want 0.5", name, peak)
+		}
+		if rms := math.Sqrt(sum / float64(len(mono))); math.Abs(rms-0.5/math.Sqrt2) > 1e-4 {
+			t.Errorf("%s: RMS %.5f, want %.5f", name, rms, 0.5/math.Sqrt2)
+		}
+	}
+}
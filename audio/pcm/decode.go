@@ -0,0 +1,231 @@
+// Package pcm decodes header-less PCM data, e.g. captured from an ADC or
+// exported as raw samples, into beep streamers.
+package pcm
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/faiface/beep"
+)
+
+// Format describes the layout of raw PCM data
+type Format struct {
+	SampleRate int
+	Channels   int  // number of interleaved channels
+	BitDepth   int  // 8, 16, 24 or 32 for integers, 32 or 64 for floats
+	BigEndian  bool // byte order of multi-byte samples
+	Float      bool // IEEE 754 floating point samples in [-1, 1]
+	Unsigned   bool // unsigned integers with an offset of half the range
+}
+
+// ParseFormat builds a Format from an encoding name as used by ffmpeg and sox:
+// s8, u8, s16le, s16be, u16le, u16be, s24le, s24be, s32le, s32be, f32le,
+// f32be, f64le or f64be
+func ParseFormat(encoding string, sampleRate, channels int) (Format, error) {
+	f := Format{SampleRate: sampleRate, Channels: channels}
+	name := strings.ToLower(encoding)
+	switch {
+	case strings.HasSuffix(name, "le"):
+		name = strings.TrimSuffix(name, "le")
+	case strings.HasSuffix(name, "be"):
+		name = strings.TrimSuffix(name, "be")
+		f.BigEndian = true
+	}
+	if len(name) < 2 {
+		return Format{}, fmt.Errorf("pcm: invalid encoding %q", encoding)
+	}
+	switch name[0] {
+	case 's':
+	case 'u':
+		f.Unsigned = true
+	case 'f':
+		f.Float = true
+	default:
+		return Format{}, fmt.Errorf("pcm: invalid encoding %q", encoding)
+	}
+	if _, err := fmt.Sscanf(name[1:], "%d", &f.BitDepth); err != nil {
+		return Format{}, fmt.Errorf("pcm: invalid encoding %q", encoding)
+	}
+	if f.BitDepth > 8 && name == strings.ToLower(encoding) {
+		return Format{}, fmt.Errorf("pcm: encoding %q is missing the byte order (le or be)", encoding)
+	}
+	return f, f.validate()
+}
+
+func (f Format) validate() error {
+	if f.SampleRate <= 0 {
+		return fmt.Errorf("pcm: invalid sample rate %d", f.SampleRate)
+	}
+	if f.Channels <= 0 {
+		return fmt.Errorf("pcm: invalid number of channels %d", f.Channels)
+	}
+	switch {
+	case f.Float && (f.BitDepth == 32 || f.BitDepth == 64):
+	case !f.Float && (f.BitDepth == 8 || f.BitDepth == 16 || f.BitDepth == 24 || f.BitDepth == 32):
+	default:
+		return fmt.Errorf("pcm: unsupported bit depth %d", f.BitDepth)
+	}
+	return nil
+}
+
+type decoder struct {
+	r      io.Reader
+	br     *bufio.Reader
+	f      Format
+	bytes  int // bytes per sample
+	frames int // number of frames, 0 if unknown
+	pos    int
+	buf    []byte
+	err    error
+}
+
+// Decode returns a streamer for the raw samples in r laid out as described by
+// f. Len is only known and Seek only works if r implements io.Seeker.
+func Decode(r io.Reader, f Format) (s beep.StreamSeekCloser, format beep.Format, err error) {
+	if err := f.validate(); err != nil {
+		return nil, beep.Format{}, err
+	}
+	d := &decoder{r: r, br: bufio.NewReader(r), f: f, bytes: f.BitDepth / 8}
+
+	if seeker, ok := r.(io.Seeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			end, err := seeker.Seek(0, io.SeekEnd)
+			if err != nil {
+				return nil, beep.Format{}, fmt.Errorf("pcm: seek error: %w", err)
+			}
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, beep.Format{}, fmt.Errorf("pcm: seek error: %w", err)
+			}
+			d.frames = int(end-start) / (f.Channels * d.bytes)
+		}
+	}
+
+	format = beep.Format{
+		SampleRate:  beep.SampleRate(f.SampleRate),
+		NumChannels: f.Channels,
+		Precision:   d.bytes,
+	}
+	return d, format, nil
+}
+
+// Stream fills samples with the first two channels, mono is copied to both
+func (d *decoder) Stream(samples [][2]float64) (n int, ok bool) {
+	buf, n := d.readFrames(len(samples))
+	frameBytes := d.f.Channels * d.bytes
+	for i := 0; i < n; i++ {
+		frame := buf[i*frameBytes:]
+		left := d.sample(frame)
+		right := left
+		if d.f.Channels > 1 {
+			right = d.sample(frame[d.bytes:])
+		}
+		samples[i] = [2]float64{left, right}
+	}
+	return n, n > 0
+}
+
+// StreamChannels fills samples with all channels, samples[i] needs one value
+// per channel
+func (d *decoder) StreamChannels(samples [][]float64) (n int, ok bool) {
+	buf, n := d.readFrames(len(samples))
+	frameBytes := d.f.Channels * d.bytes
+	for i := 0; i < n; i++ {
+		for c := 0; c < d.f.Channels; c++ {
+			samples[i][c] = d.sample(buf[i*frameBytes+c*d.bytes:])
+		}
+	}
+	return n, n > 0
+}
+
+// readFrames reads up to count sample frames and returns their bytes
+func (d *decoder) readFrames(count int) (buf []byte, n int) {
+	if d.err != nil {
+		return nil, 0
+	}
+	frameBytes := d.f.Channels * d.bytes
+	if cap(d.buf) < count*frameBytes {
+		d.buf = make([]byte, count*frameBytes)
+	}
+	buf = d.buf[:count*frameBytes]
+	read, err := io.ReadFull(d.br, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		d.err = fmt.Errorf("pcm: %w", err)
+	}
+	n = read / frameBytes
+	d.pos += n
+	return buf, n
+}
+
+// sample decodes a single sample in [-1, 1]
+func (d *decoder) sample(b []byte) float64 {
+	var order binary.ByteOrder = binary.LittleEndian
+	if d.f.BigEndian {
+		order = binary.BigEndian
+	}
+	if d.f.Float {
+		if d.bytes == 4 {
+			return float64(math.Float32frombits(order.Uint32(b)))
+		}
+		return math.Float64frombits(order.Uint64(b))
+	}
+
+	var v uint64
+	for i := 0; i < d.bytes; i++ {
+		shift := 8 * i
+		if d.f.BigEndian {
+			shift = 8 * (d.bytes - 1 - i)
+		}
+		v |= uint64(b[i]) << shift
+	}
+	bits := uint(d.f.BitDepth)
+	if d.f.Unsigned {
+		return (float64(v) - float64(uint64(1)<<(bits-1))) / float64(uint64(1)<<(bits-1))
+	}
+	signed := int64(v<<(64-bits)) >> (64 - bits)
+	return float64(signed) / float64(int64(1)<<(bits-1))
+}
+
+func (d *decoder) Err() error {
+	return d.err
+}
+
+func (d *decoder) Len() int {
+	return d.frames
+}
+
+func (d *decoder) Position() int {
+	return d.pos
+}
+
+func (d *decoder) Seek(p int) error {
+	seeker, ok := d.r.(io.Seeker)
+	if !ok {
+		return fmt.Errorf("pcm: seek: resource is not io.Seeker")
+	}
+	if p < 0 || p > d.frames {
+		return fmt.Errorf("pcm: seek position %v out of range [%v, %v]", p, 0, d.frames)
+	}
+	delta := int64((p - d.pos) * d.f.Channels * d.bytes)
+	// Account for the bytes already buffered but not yet consumed
+	if _, err := seeker.Seek(delta-int64(d.br.Buffered()), io.SeekCurrent); err != nil {
+		return fmt.Errorf("pcm: seek error: %w", err)
+	}
+	d.br.Reset(d.r)
+	d.pos = p
+	return nil
+}
+
+func (d *decoder) Close() error {
+	if closer, ok := d.r.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("pcm: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+//go:build !tinygo
+
+package audio
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// LoadRange is like LoadAudio but only returns the samples from start to
+// start+length; a length <= 0 reads to the end. If r implements io.Seeker the
+// decoder seeks to start instead of decoding everything before it, which
+// makes short excerpts of long files fast. The returned duration is that of
+// the excerpt, which is shorter than length if the stream ends before.
+func LoadRange(r io.Reader, hint Format, start, length time.Duration) (mono []float64, sampleRate int, duration time.Duration, err error) {
+	seekable := false
+	if rs, ok := r.(io.ReadSeeker); ok {
+		r = struct{ io.ReadSeeker }{rs} // hide Close from the decoders
+		seekable = true
+	} else {
+		r = struct{ io.Reader }{r}
+	}
+
+	streamer, format, err := Decode(r, hint)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer streamer.Close()
+
+	if !seekable {
+		// Hide Seek, the decoders cannot seek without io.Seeker
+		return ReadMonoRange(struct{ beep.Streamer }{streamer}, format, start, length)
+	}
+	return ReadMonoRange(streamer, format, start, length)
+}
+
+// ReadMonoRange is like ReadMono but only reads the samples from start to
+// start+length; a length <= 0 reads to the end. The streamer is positioned
+// with Seek if it implements beep.StreamSeeker, otherwise the samples before
+// start are decoded and dropped.
+func ReadMonoRange(streamer beep.Streamer, format beep.Format, start, length time.Duration) (mono []float64, sampleRate int, duration time.Duration, err error) {
+	if err := skipTo(streamer, format, start); err != nil {
+		return nil, 0, 0, err
+	}
+	if length > 0 {
+		streamer = beep.Take(format.SampleRate.N(length), streamer)
+	}
+	return ReadMono(streamer, format)
+}
+
+// skipTo positions streamer at start with Seek if it implements
+// beep.StreamSeeker, otherwise the samples before start are decoded and dropped
+func skipTo(streamer beep.Streamer, format beep.Format, start time.Duration) error {
+	if start < 0 {
+		return fmt.Errorf("invalid start %v", start)
+	}
+	if format.SampleRate <= 0 {
+		return fmt.Errorf("invalid sample rate %d", format.SampleRate)
+	}
+	from := format.SampleRate.N(start)
+
+	if seeker, ok := streamer.(beep.StreamSeeker); ok {
+		if seeker.Len() > 0 && from > seeker.Len() {
+			return fmt.Errorf("start %v is beyond the end of the stream", start)
+		}
+		return seeker.Seek(from)
+	}
+
+	buf := make([][2]float64, 4096)
+	for skipped := 0; skipped < from; {
+		n, ok := streamer.Stream(buf[:min(len(buf), from-skipped)])
+		skipped += n
+		if !ok {
+			if err := streamer.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("start %v is beyond the end of the stream", start)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+//go:build !tinygo
+
+package audio
+
+import (
+	"fmt"
+	"io"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/faiface/beep"
+)
+
+// FrameReader decodes a streamer lazily into overlapping mono frames, so long
+// files can be analyzed with memory bounded by the frame size
+type FrameReader struct {
+	streamer  beep.Streamer
+	frameSize int
+	hopSize   int
+	window    []float64 // samples of the current frame
+	buf       [][2]float64
+	pending   []float64 // decoded samples not yet in a frame
+	index     int
+	done      bool
+}
+
+// NewFrameReader returns a FrameReader for frames of frameSize samples
+// advancing by hopSize samples, like dft.Frames
+func NewFrameReader(streamer beep.Streamer, frameSize, hopSize int) (*FrameReader, error) {
+	if frameSize <= 0 || hopSize <= 0 {
+		return nil, fmt.Errorf("invalid frame size %d or hop size %d", frameSize, hopSize)
+	}
+	return &FrameReader{
+		streamer:  streamer,
+		frameSize: frameSize,
+		hopSize:   hopSize,
+		buf:       make([][2]float64, 4096),
+	}, nil
+}
+
+// Next returns the next frame and its index. The frame is only valid until the
+// next call. It returns io.EOF when the stream has no full frame left; trailing
+// samples that do not fill a whole frame are dropped.
+func (fr *FrameReader) Next() (frame []float64, index int, err error) {
+	if fr.window == nil {
+		if err := fr.fill(fr.frameSize); err != nil {
+			return nil, 0, err
+		}
+		fr.window = make([]float64, fr.frameSize)
+		copy(fr.window, fr.pending)
+		fr.pending = fr.consume(fr.frameSize)
+		return fr.window, 0, nil
+	}
+
+	if fr.hopSize >= fr.frameSize {
+		// Skip the samples between two frames
+		skip := fr.hopSize - fr.frameSize
+		if err := fr.fill(skip + fr.frameSize); err != nil {
+			return nil, 0, err
+		}
+		copy(fr.window, fr.pending[skip:])
+		fr.pending = fr.consume(skip + fr.frameSize)
+	} else {
+		if err := fr.fill(fr.hopSize); err != nil {
+			return nil, 0, err
+		}
+		copy(fr.window, fr.window[fr.hopSize:])
+		copy(fr.window[fr.frameSize-fr.hopSize:], fr.pending[:fr.hopSize])
+		fr.pending = fr.consume(fr.hopSize)
+	}
+	fr.index++
+	return fr.window, fr.index, nil
+}
+
+// fill decodes until n samples are pending. It returns io.EOF if the stream
+// ends before.
+func (fr *FrameReader) fill(n int) error {
+	for len(fr.pending) < n && !fr.done {
+		count, ok := fr.streamer.Stream(fr.buf)
+		for _, s := range fr.buf[:count] {
+			fr.pending = append(fr.pending, (s[0]+s[1])/2)
+		}
+		if !ok {
+			fr.done = true
+			if err := fr.streamer.Err(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(fr.pending) < n {
+		return io.EOF
+	}
+	return nil
+}
+
+// consume drops the first n pending samples, reusing the backing array
+func (fr *FrameReader) consume(n int) []float64 {
+	rest := copy(fr.pending, fr.pending[n:])
+	return fr.pending[:rest]
+}
+
+// StreamSTFT computes the short-time fourier transform of streamer frame by
+// frame like dft.STFT and passes every frame to fn. Only one frame is held in
+// memory at a time. It stops at the first error returned by fn.
+func StreamSTFT(streamer beep.Streamer, sampleRate, frameSize, hopSize int, fn func(index int, frame dft.Frame) error) error {
+	analyzer, err := dft.NewStreamAnalyzer(sampleRate, frameSize, hopSize, dft.Window{Type: dft.Hanning})
+	if err != nil {
+		return err
+	}
+	buf := make([][2]float64, 4096)
+	mono := make([]float64, len(buf))
+	index := 0
+	for {
+		n, ok := streamer.Stream(buf)
+		for i, s := range buf[:n] {
+			mono[i] = (s[0] + s[1]) / 2
+		}
+		for _, frame := range analyzer.Push(mono[:n]) {
+			if err := fn(index, frame); err != nil {
+				return err
+			}
+			index++
+		}
+		if !ok {
+			return streamer.Err()
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WAVOptions configures the sample format written by WriteWAV
+type WAVOptions struct {
+	BitDepth int  // 8, 16, 24 or 32 bits per sample
+	Float    bool // write 32 bit IEEE float samples, BitDepth is ignored
+}
+
+// DefaultWAVOptions writes 16 bit PCM
+var DefaultWAVOptions = WAVOptions{BitDepth: 16}
+
+// WriteWAV writes channels with samples in [-1..1] as a WAV file. Integer
+// samples outside of that range are clipped, float samples are written as
+// they are. All channels must have the same length.
+//
+// Note that float WAV files can not be read by Decode.
+func WriteWAV(w io.Writer, channels [][]float64, sampleRate int, opts WAVOptions) error {
+	if len(channels) == 0 {
+		return fmt.Errorf("no channels to write")
+	}
+	if sampleRate <= 0 {
+		return fmt.Errorf("invalid sample rate %d", sampleRate)
+	}
+	frames := len(channels[0])
+	for _, ch := range channels {
+		if len(ch) != frames {
+			return fmt.Errorf("channels have different lengths %d and %d", frames, len(ch))
+		}
+	}
+
+	formatTag, bits := uint16(1), opts.BitDepth // PCM
+	if opts.Float {
+		formatTag, bits = 3, 32 // IEEE float
+	}
+	switch bits {
+	case 8, 16, 24, 32:
+	default:
+		return fmt.Errorf("unsupported bit depth %d", bits)
+	}
+	width := bits / 8
+	blockAlign := len(channels) * width
+	dataSize := frames * blockAlign
+	if int64(dataSize)+44 > math.MaxUint32 {
+		return fmt.Errorf("%d frames exceed the size limit of WAV files", frames)
+	}
+
+	bw := bufio.NewWriter(w)
+	header := make([]byte, 0, 44)
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(36+dataSize+dataSize%2))
+	header = append(header, "WAVEfmt "...)
+	header = binary.LittleEndian.AppendUint32(header, 16)
+	header = binary.LittleEndian.AppendUint16(header, formatTag)
+	header = binary.LittleEndian.AppendUint16(header, uint16(len(channels)))
+	header = binary.LittleEndian.AppendUint32(header, uint32(sampleRate))
+	header = binary.LittleEndian.AppendUint32(header, uint32(sampleRate*blockAlign))
+	header = binary.LittleEndian.AppendUint16(header, uint16(blockAlign))
+	header = binary.LittleEndian.AppendUint16(header, uint16(bits))
+	header = append(header, "data"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(dataSize))
+	if _, err := bw.Write(header); err != nil {
+		return err
+	}
+
+	buf := make([]byte, blockAlign)
+	for i := 0; i < frames; i++ {
+		for c, ch := range channels {
+			b := buf[c*width : (c+1)*width]
+			if opts.Float {
+				binary.LittleEndian.PutUint32(b, math.Float32bits(float32(ch[i])))
+				continue
+			}
+			v := quantize(ch[i], bits)
+			switch bits {
+			case 8:
+				b[0] = byte(v + 128) // 8 bit samples are unsigned
+			case 16:
+				binary.LittleEndian.PutUint16(b, uint16(v))
+			case 24:
+				b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+			case 32:
+				binary.LittleEndian.PutUint32(b, uint32(v))
+			}
+		}
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+	if dataSize%2 == 1 {
+		// chunks are padded to an even size
+		if err := bw.WriteByte(0); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// quantize converts x in [-1..1] to a signed integer with the given number of
+// bits, clipping values outside of that range
+func quantize(x float64, bits int) int64 {
+	scale := float64(int64(1) << (bits - 1))
+	v := math.Round(x * scale)
+	return int64(math.Max(-scale, math.Min(scale-1, v)))
+}
@@ -0,0 +1,87 @@
+package dft
+
+import "math"
+
+// AutoCorr computes the autocorrelation of samples for the lags 0..len(samples)-1.
+//
+// The autocorrelation is computed with the Wiener–Khinchin theorem: the signal
+// is zero-padded to at least 2*len(samples)-1 to avoid circular wrap-around,
+// transformed, and the inverse transform of its power spectrum is taken.
+// The result is not normalized, r[0] is the energy of the signal.
+func AutoCorr(samples []float64) []float64 {
+	n := len(samples)
+	if n == 0 {
+		return nil
+	}
+
+	// Zero-pad to avoid circular correlation
+	size := NextPowerOfTwo(2*n - 1)
+	padded := make([]float64, size)
+	copy(padded, samples)
+
+	// Power spectrum
+	spectrum := Forward(padded)
+	for i, c := range spectrum {
+		spectrum[i] = complex(real(c)*real(c)+imag(c)*imag(c), 0)
+	}
+
+	return Inverse(spectrum, size)[:n]
+}
+
+// CrossCorr computes the cross-correlation r[k] = sum a[n]·b[n+k] of a and b
+// for the lags k from -(len(a)-1) to len(b)-1. The lag k is at index
+// k+len(a)-1 of the result, so a peak at lag k > 0 means that b lags a by k
+// samples.
+func CrossCorr(a, b []float64) []float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	n := len(a) + len(b) - 1
+	size := NextPowerOfTwo(n)
+	pa := make([]float64, size)
+	pb := make([]float64, size)
+	copy(pa, a)
+	copy(pb, b)
+
+	ca := Forward(pa)
+	cb := Forward(pb)
+	for i := range ca {
+		ca[i] = complex(real(ca[i]), -imag(ca[i])) * cb[i]
+	}
+	circular := Inverse(ca, size)
+
+	// Negative lags wrap around to the end
+	r := make([]float64, n)
+	for k := -(len(a) - 1); k < len(b); k++ {
+		r[k+len(a)-1] = circular[(k+size)%size]
+	}
+	return r
+}
+
+// EstimateDelay returns the delay of test relative to ref in samples, the lag
+// of the largest absolute cross-correlation within ±maxLag samples, refined to
+// a fraction of a sample by fitting a parabola through the peak. A negative
+// delay means that test leads ref. An inverted polarity does not affect the
+// result.
+func EstimateDelay(ref, test []float64, maxLag int) float64 {
+	r := CrossCorr(ref, test)
+	if r == nil {
+		return 0
+	}
+	zero := len(ref) - 1
+	lo, hi := max(0, zero-maxLag), min(len(r)-1, zero+maxLag)
+	best := lo
+	for i := lo; i <= hi; i++ {
+		if math.Abs(r[i]) > math.Abs(r[best]) {
+			best = i
+		}
+	}
+	lag := float64(best - zero)
+	if best > 0 && best < len(r)-1 {
+		y0, y1, y2 := math.Abs(r[best-1]), math.Abs(r[best]), math.Abs(r[best+1])
+		if d := y0 - 2*y1 + y2; d < 0 {
+			lag += 0.5 * (y0 - y2) / d
+		}
+	}
+	return lag
+}
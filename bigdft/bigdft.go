@@ -0,0 +1,157 @@
+// Package bigdft computes DFTs in arbitrary precision with math/big, as
+// reference spectra that bound the rounding error of the float64 FFTs, e.g.
+// for numerical analysis of new transform sizes or backends. It is O(N²)
+// and orders of magnitude slower than dft.NaiveDFT.
+package bigdft
+
+import (
+	"math"
+	"math/big"
+)
+
+// guardBits are the extra bits of the intermediate results, so the
+// coefficients are accurate to the requested precision
+const guardBits = 32
+
+// Complex is a complex number of big.Float parts
+type Complex struct {
+	Re, Im *big.Float
+}
+
+// Complex128 returns c rounded to a complex128
+func (c Complex) Complex128() complex128 {
+	re, _ := c.Re.Float64()
+	im, _ := c.Im.Float64()
+	return complex(re, im)
+}
+
+// DFT computes the len(samples)/2+1 coefficients of the non-negative
+// frequencies of a real valued signal, like dft.Forward, with prec bits of
+// mantissa, e.g. 128 for about 38 significant digits. The samples are exact
+// in any precision.
+func DFT(samples []float64, prec uint) []Complex {
+	n := len(samples)
+	cos, sin := twiddles(n, prec+guardBits)
+	x := make([]*big.Float, n)
+	for i, v := range samples {
+		x[i] = newFloat(prec + guardBits).SetFloat64(v)
+	}
+
+	coeffs := make([]Complex, n/2+1)
+	term := newFloat(prec + guardBits)
+	for k := range coeffs {
+		re, im := newFloat(prec+guardBits), newFloat(prec+guardBits)
+		for i := range x {
+			// e^(-2πi·ki/N) = cos - i·sin
+			m := k * i % n
+			re.Add(re, term.Mul(x[i], cos[m]))
+			im.Sub(im, term.Mul(x[i], sin[m]))
+		}
+		coeffs[k] = Complex{re.SetPrec(prec), im.SetPrec(prec)}
+	}
+	return coeffs
+}
+
+// RelativeError returns the largest difference of got, e.g. the coefficients
+// of dft.Forward, and the reference want relative to the largest reference
+// coefficient. It returns +Inf if the lengths differ.
+func RelativeError(got []complex128, want []Complex) float64 {
+	if len(got) != len(want) {
+		return math.Inf(1)
+	}
+	prec := uint(0)
+	for _, w := range want {
+		prec = max(prec, w.Re.Prec(), w.Im.Prec())
+	}
+	abs := func(re, im *big.Float) *big.Float {
+		sq := newFloat(prec).Mul(re, re)
+		sq.Add(sq, newFloat(prec).Mul(im, im))
+		return sq.Sqrt(sq)
+	}
+	scale, diff := newFloat(prec), newFloat(prec)
+	for k, w := range want {
+		if a := abs(w.Re, w.Im); a.Cmp(scale) > 0 {
+			scale = a
+		}
+		re := newFloat(prec).SetFloat64(real(got[k]))
+		im := newFloat(prec).SetFloat64(imag(got[k]))
+		re.Sub(re, w.Re)
+		im.Sub(im, w.Im)
+		if d := abs(re, im); d.Cmp(diff) > 0 {
+			diff = d
+		}
+	}
+	if scale.Sign() == 0 {
+		d, _ := diff.Float64()
+		return d
+	}
+	r, _ := diff.Quo(diff, scale).Float64()
+	return r
+}
+
+// Pi returns π with prec bits, from Machin's formula
+// π = 16·atan(1/5) - 4·atan(1/239)
+func Pi(prec uint) *big.Float {
+	p := newFloat(prec+guardBits).Mul(big.NewFloat(16), atanInv(5, prec+guardBits))
+	p.Sub(p, newFloat(prec+guardBits).Mul(big.NewFloat(4), atanInv(239, prec+guardBits)))
+	return p.SetPrec(prec)
+}
+
+// atanInv returns atan(1/x) from its series Σ (-1)^k / ((2k+1)·x^(2k+1))
+func atanInv(x int64, prec uint) *big.Float {
+	sum := newFloat(prec)
+	power := newFloat(prec).Quo(big.NewFloat(1), newFloat(prec).SetInt64(x))
+	x2 := newFloat(prec).SetInt64(x * x)
+	term := newFloat(prec)
+	for k := int64(0); power.Sign() != 0 && power.MantExp(nil) > -int(prec)-8; k++ {
+		term.Quo(power, newFloat(prec).SetInt64(2*k+1))
+		if k%2 == 0 {
+			sum.Add(sum, term)
+		} else {
+			sum.Sub(sum, term)
+		}
+		power.Quo(power, x2)
+	}
+	return sum
+}
+
+// twiddles returns cos(2πm/n) and sin(2πm/n) for m in [0, n)
+func twiddles(n int, prec uint) (cos, sin []*big.Float) {
+	cos, sin = make([]*big.Float, n), make([]*big.Float, n)
+	twoPi := newFloat(prec).Mul(big.NewFloat(2), Pi(prec))
+	for m := range n {
+		// Angles in [-π, π] keep the Taylor series short
+		k := m
+		if 2*m > n {
+			k = m - n
+		}
+		angle := newFloat(prec).Mul(twoPi, newFloat(prec).SetInt64(int64(k)))
+		angle.Quo(angle, newFloat(prec).SetInt64(int64(n)))
+		cos[m], sin[m] = sinCos(angle, prec)
+	}
+	return cos, sin
+}
+
+// sinCos returns cos(x) and sin(x) for |x| <= π from their Taylor series
+func sinCos(x *big.Float, prec uint) (cos, sin *big.Float) {
+	x2 := newFloat(prec).Mul(x, x)
+	cos, sin = newFloat(prec).SetInt64(1), newFloat(prec).Set(x)
+	c, s := newFloat(prec).SetInt64(1), newFloat(prec).Set(x)
+	for k := int64(1); ; k++ {
+		// c = (-1)^k x^2k / (2k)!, s = (-1)^k x^(2k+1) / (2k+1)!
+		c.Mul(c, x2)
+		c.Quo(c, newFloat(prec).SetInt64(-(2*k-1)*(2*k)))
+		s.Mul(s, x2)
+		s.Quo(s, newFloat(prec).SetInt64(-(2*k)*(2*k+1)))
+		if c.Sign() == 0 || c.MantExp(nil) < -int(prec)-8 {
+			break
+		}
+		cos.Add(cos, c)
+		sin.Add(sin, s)
+	}
+	return cos, sin
+}
+
+func newFloat(prec uint) *big.Float {
+	return new(big.Float).SetPrec(prec)
+}
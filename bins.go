@@ -0,0 +1,61 @@
+package dft
+
+// BinToHz returns the frequency in Hz of a bin of an FFT of fftSize samples.
+// The bin may be fractional, e.g. an interpolated peak of InterpolatePeak.
+func BinToHz(bin float64, sampleRate, fftSize int) float64 {
+	return bin * float64(sampleRate) / float64(fftSize)
+}
+
+// HzToBin returns the fractional bin of a frequency in an FFT of fftSize
+// samples, math.Round selects the nearest bin
+func HzToBin(hz float64, sampleRate, fftSize int) float64 {
+	return hz * float64(fftSize) / float64(sampleRate)
+}
+
+// FreqAxis returns the frequencies in Hz of the n/2+1 coefficients that
+// Forward returns for n samples, like numpy.fft.rfftfreq
+func FreqAxis(n, sampleRate int) []float64 {
+	freqs := make([]float64, n/2+1)
+	for i := range freqs {
+		freqs[i] = BinToHz(float64(i), sampleRate, n)
+	}
+	return freqs
+}
+
+// ComplexFreqAxis returns the frequencies in Hz of the n coefficients that
+// ForwardComplex returns for n samples, like numpy.fft.fftfreq: the
+// non-negative frequencies followed by the negative ones. FFTShift orders
+// them from the lowest upwards.
+func ComplexFreqAxis(n, sampleRate int) []float64 {
+	freqs := make([]float64, n)
+	for i := range freqs {
+		k := i
+		if i >= (n+1)/2 {
+			k -= n
+		}
+		freqs[i] = BinToHz(float64(k), sampleRate, n)
+	}
+	return freqs
+}
+
+// FFTShift returns a copy of x, e.g. the coefficients of ForwardComplex or
+// ComplexFreqAxis, with the zero frequency moved to the center and the
+// negative frequencies before it, like numpy.fft.fftshift
+func FFTShift[T any](x []T) []T {
+	n := len(x)
+	shifted := make([]T, n)
+	for i, v := range x {
+		shifted[(i+n/2)%n] = v
+	}
+	return shifted
+}
+
+// IFFTShift undoes FFTShift, also for an odd number of values
+func IFFTShift[T any](x []T) []T {
+	n := len(x)
+	shifted := make([]T, n)
+	for i := range shifted {
+		shifted[i] = x[(i+n/2)%n]
+	}
+	return shifted
+}
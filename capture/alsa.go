@@ -0,0 +1,149 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ALSA records with the arecord tool of alsa-utils. It is Linux only and
+// fails in Open if arecord is not installed.
+type ALSA struct {
+	Command string // path of arecord, default "arecord"
+	PCMList string // path of the kernel's PCM list, default "/proc/asound/pcm"
+}
+
+func (a ALSA) command() string {
+	if a.Command == "" {
+		return "arecord"
+	}
+	return a.Command
+}
+
+// Devices lists the PCM devices of the sound cards that can capture, as read
+// from /proc/asound/pcm. The IDs select them through the plughw plugin, which
+// converts to the requested sample rate and format.
+func (a ALSA) Devices() ([]Device, error) {
+	path := a.PCMList
+	if path == "" {
+		path = "/proc/asound/pcm"
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("alsa: listing devices: %w", err)
+	}
+	defer f.Close()
+	devices, err := parsePCMList(f)
+	if err != nil {
+		return nil, fmt.Errorf("alsa: listing devices: %w", err)
+	}
+	return devices, nil
+}
+
+// parsePCMList parses lines like
+//
+//	00-00: ALC892 Analog : ALC892 Analog : playback 1 : capture 1
+//
+// and returns the devices with capture streams
+func parsePCMList(r io.Reader) ([]Device, error) {
+	var devices []Device
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.Split(sc.Text(), ":")
+		if len(fields) < 3 {
+			continue
+		}
+		var card, dev int
+		if _, err := fmt.Sscanf(fields[0], "%d-%d", &card, &dev); err != nil {
+			return nil, fmt.Errorf("invalid PCM %q", fields[0])
+		}
+		capture := false
+		for _, f := range fields[3:] {
+			capture = capture || strings.HasPrefix(strings.TrimSpace(f), "capture")
+		}
+		if !capture {
+			continue
+		}
+		devices = append(devices, Device{
+			ID:          fmt.Sprintf("plughw:%d,%d", card, dev),
+			Description: strings.TrimSpace(fields[1]),
+		})
+	}
+	return devices, sc.Err()
+}
+
+// Open starts arecord with 32 bit float samples
+func (a ALSA) Open(deviceID string, cfg Config) (Stream, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	args := []string{"-q", "-t", "raw", "-f", "FLOAT_LE",
+		"-r", strconv.Itoa(cfg.SampleRate), "-c", strconv.Itoa(cfg.Channels)}
+	if deviceID != "" {
+		args = append(args, "-D", deviceID)
+	}
+	cmd := exec.Command(a.command(), args...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("alsa: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("alsa: starting %s: %w", a.command(), err)
+	}
+	return &processStream{cmd: cmd, r: bufio.NewReader(stdout), stderr: stderr, channels: cfg.Channels}, nil
+}
+
+// processStream reads little-endian float32 samples from the output of a recorder process
+type processStream struct {
+	cmd      *exec.Cmd
+	r        io.Reader
+	stderr   *bytes.Buffer
+	channels int
+	buf      []byte
+}
+
+func (s *processStream) Read(samples []float64) (int, error) {
+	// Read whole frames only
+	count := len(samples) - len(samples)%s.channels
+	if cap(s.buf) < 4*count {
+		s.buf = make([]byte, 4*count)
+	}
+	buf := s.buf[:4*count]
+	n, err := io.ReadFull(s.r, buf)
+	n -= n % (4 * s.channels)
+	for i := 0; i < n/4; i++ {
+		samples[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:])))
+	}
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n / 4, err
+}
+
+// Close stops the process. Its exit status is only reported if it ended on
+// its own, e.g. because it could not open the device.
+func (s *processStream) Close() error {
+	killErr := s.cmd.Process.Kill()
+	err := s.cmd.Wait()
+	if killErr != nil && !errors.Is(killErr, os.ErrProcessDone) {
+		return fmt.Errorf("alsa: stopping %s: %w", s.cmd.Path, killErr)
+	}
+	var exit *exec.ExitError
+	if err == nil || errors.As(err, &exit) && !exit.Exited() {
+		return nil // killed
+	}
+	if msg := strings.TrimSpace(s.stderr.String()); msg != "" {
+		return fmt.Errorf("alsa: %s: %w: %s", s.cmd.Path, err, msg)
+	}
+	return fmt.Errorf("alsa: %s: %w", s.cmd.Path, err)
+}
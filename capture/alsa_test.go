@@ -0,0 +1,77 @@
+package capture
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParsePCMList(t *testing.T) {
+	list := `00-00: ALC892 Analog : ALC892 Analog : playback 1 : capture 1
+00-01: ALC892 Digital : ALC892 Digital : playback 1
+01-03: HDMI 0 : HDMI 0 : playback 1
+02-00: USB Audio : USB Audio : capture 1
+`
+	devices, err := parsePCMList(strings.NewReader(list))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Device{{"plughw:0,0", "ALC892 Analog"}, {"plughw:2,0", "USB Audio"}}
+	if len(devices) != len(want) {
+		t.Fatalf("devices %+v, want %+v", devices, want)
+	}
+	for i := range want {
+		if devices[i] != want[i] {
+			t.Errorf("device %d: %+v, want %+v", i, devices[i], want[i])
+		}
+	}
+
+	if _, err := parsePCMList(strings.NewReader("card0: x : y : capture 1\n")); err == nil {
+		t.Error("no error for an invalid PCM")
+	}
+}
+
+// fakeRecorder writes a shell script that is run instead of arecord
+func fakeRecorder(t *testing.T, script string) ALSA {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "arecord")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return ALSA{Command: path}
+}
+
+func TestClose(t *testing.T) {
+	cfg := Config{SampleRate: 8000, Channels: 2, BlockSize: 256}
+
+	// A recorder that is still running is stopped without an error
+	stream, err := fakeRecorder(t, "exec cat /dev/zero").Open("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]float64, 101)
+	if n, err := stream.Read(buf); n != 100 || err != nil {
+		t.Errorf("read %d samples with error %v, want 100 whole frames", n, err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Errorf("closing a running recorder: %v", err)
+	}
+
+	// The failure of a recorder that ended on its own is reported
+	stream, err = fakeRecorder(t, "echo 'cannot open device' >&2; exit 1").Open("", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Read(buf); err != io.EOF {
+		t.Errorf("read from a failed recorder: %v, want io.EOF", err)
+	}
+	if err := stream.Close(); err == nil || !strings.Contains(err.Error(), "cannot open device") {
+		t.Errorf("closing a failed recorder: %v, want its error message", err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package capture records live audio input and analyzes it block by block.
+//
+// Recording is done by a Backend. The only backend included is ALSA, which
+// runs the arecord tool of alsa-utils and therefore only works on Linux with
+// arecord installed. A cross-platform backend on malgo (miniaudio) or
+// PortAudio is not implemented yet; both need cgo. Until then, backends for
+// other platforms can be added outside of this package by implementing the
+// Backend interface, Run only depends on the Stream they open.
+package capture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Device is an audio input device
+type Device struct {
+	ID          string // identifier passed to Backend.Open
+	Description string
+}
+
+// Config configures a capture stream
+type Config struct {
+	SampleRate int
+	Channels   int
+	BlockSize  int // frames per analyzed block
+	HopSize    int // frames between the starts of two blocks, 0 for BlockSize
+}
+
+// DefaultConfig records mono at 48 kHz in blocks of 4096 frames
+var DefaultConfig = Config{SampleRate: 48000, Channels: 1, BlockSize: 4096}
+
+func (c Config) validate() error {
+	if c.SampleRate <= 0 || c.Channels <= 0 || c.BlockSize <= 0 {
+		return fmt.Errorf("invalid capture config %+v", c)
+	}
+	return nil
+}
+
+// Stream delivers recorded samples
+type Stream interface {
+	// Read fills samples with interleaved samples in [-1, 1] and returns their
+	// number, which is a multiple of the channel count
+	Read(samples []float64) (n int, err error)
+	Close() error
+}
+
+// Backend records from the audio devices of a host audio system
+type Backend interface {
+	Devices() ([]Device, error)
+	// Open starts recording from the device with the given ID, an empty ID
+	// selects the default device
+	Open(deviceID string, cfg Config) (Stream, error)
+}
+
+// Block is an analyzed block of recorded audio
+type Block struct {
+	Index    int
+	Time     float64 // center of the block in seconds since the start of the recording
+	Spectrum dft.Spectrum
+}
+
+// Run reads from stream, mixes the channels to mono and passes the spectrum of
+// every block of cfg.BlockSize frames, advancing by cfg.HopSize frames, to fn.
+// It returns when ctx is done, the stream ends or fn returns an error. It does
+// not close the stream.
+func Run(ctx context.Context, stream Stream, cfg Config, fn func(Block) error) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	hop := cfg.HopSize
+	if hop <= 0 {
+		hop = cfg.BlockSize
+	}
+	analyzer, err := dft.NewStreamAnalyzer(cfg.SampleRate, cfg.BlockSize, hop, dft.Window{Type: dft.Hanning})
+	if err != nil {
+		return err
+	}
+
+	// Read in pieces of at most a hop to keep the latency low
+	buf := make([]float64, min(hop, cfg.BlockSize)*cfg.Channels)
+	mono := make([]float64, min(hop, cfg.BlockSize))
+	index := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := readFull(stream, buf); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		for i := range mono {
+			mono[i] = 0
+			for c := 0; c < cfg.Channels; c++ {
+				mono[i] += buf[i*cfg.Channels+c]
+			}
+			mono[i] /= float64(cfg.Channels)
+		}
+		for _, frame := range analyzer.Push(mono) {
+			block := Block{
+				Index:    index,
+				Time:     frame.Time,
+				Spectrum: dft.NewSpectrum(frame.Spectrum, cfg.SampleRate, cfg.BlockSize, cfg.BlockSize),
+			}
+			index++
+			if err := fn(block); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readFull reads exactly len(buf) samples. A partial block at the end of the
+// stream is dropped and reported as io.EOF.
+func readFull(stream Stream, buf []float64) error {
+	for filled := 0; filled < len(buf); {
+		n, err := stream.Read(buf[filled:])
+		filled += n
+		if err != nil && filled < len(buf) {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return io.EOF
+			}
+			return err
+		}
+	}
+	return nil
+}
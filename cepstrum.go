@@ -0,0 +1,61 @@
+package dft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// minMagnitude is added to magnitudes before taking the logarithm to avoid log(0)
+const minMagnitude = 1e-12
+
+// RealCepstrum computes the real cepstrum of samples, the inverse FFT of the
+// log magnitude spectrum. Peaks at a quefrency (lag in samples) indicate
+// periodicity such as echoes or the fundamental period of a voiced signal.
+func RealCepstrum(samples []float64) []float64 {
+	n := len(samples)
+	if n == 0 {
+		return nil
+	}
+
+	spectrum := Forward(samples)
+	for i, c := range spectrum {
+		spectrum[i] = complex(math.Log(cmplx.Abs(c)+minMagnitude), 0)
+	}
+	return Inverse(spectrum, n)
+}
+
+// ComplexCepstrum computes the complex cepstrum of samples, the inverse FFT of
+// the complex logarithm of the spectrum using the unwrapped phase.
+//
+// The linear phase component is removed before the inverse transform to avoid
+// a large discontinuity at the Nyquist frequency, the number of samples of
+// circular delay that was removed is returned as delay.
+func ComplexCepstrum(samples []float64) (cepstrum []float64, delay int) {
+	n := len(samples)
+	if n == 0 {
+		return nil, 0
+	}
+
+	spectrum := Forward(samples)
+	phase := make([]float64, len(spectrum))
+	for i, c := range spectrum {
+		phase[i] = cmplx.Phase(c)
+	}
+	phase = UnwrapPhase(phase)
+
+	// Remove linear phase. The last bin is at the Nyquist frequency for even n
+	// and half a bin below it for odd n, its phase is that of a delay at the
+	// angular frequency 2*pi*half/n.
+	half := len(spectrum) - 1
+	if half > 0 {
+		delay = int(math.Round(phase[half] * float64(n) / (2 * math.Pi * float64(half))))
+		for i := range phase {
+			phase[i] -= 2 * math.Pi * float64(delay) * float64(i) / float64(n)
+		}
+	}
+
+	for i, c := range spectrum {
+		spectrum[i] = complex(math.Log(cmplx.Abs(c)+minMagnitude), phase[i])
+	}
+	return Inverse(spectrum, n), delay
+}
@@ -0,0 +1,34 @@
+package dft
+
+import (
+	"math"
+	"testing"
+)
+
+// TestComplexCepstrumDelay checks that a circular delay only changes the
+// returned delay, for even and odd lengths
+func TestComplexCepstrumDelay(t *testing.T) {
+	for _, n := range []int{64, 63, 9} {
+		x := make([]float64, n)
+		for i, v := range []float64{1, -0.6, 0.3, 0.1} {
+			x[i] = v
+		}
+		want, wantDelay := ComplexCepstrum(x)
+		for _, shift := range []int{1, 3} {
+			delayed := make([]float64, n)
+			for i := range x {
+				delayed[(i+shift)%n] = x[i]
+			}
+			got, delay := ComplexCepstrum(delayed)
+			if delay != wantDelay-shift {
+				t.Errorf("n %d, shift %d: delay %d, want %d", n, shift, delay, wantDelay-shift)
+			}
+			for i := range want {
+				if math.Abs(got[i]-want[i]) > 1e-9 {
+					t.Errorf("n %d, shift %d: quefrency %d is %g instead of %g", n, shift, i, got[i], want[i])
+					break
+				}
+			}
+		}
+	}
+}
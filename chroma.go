@@ -0,0 +1,55 @@
+package dft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Frequency range considered for the chromagram
+const (
+	chromaMinHz = 55.0
+	chromaMaxHz = 5000.0
+)
+
+// Chroma folds a magnitude spectrum of the non-negative frequencies of an
+// fftSize point FFT into the 12 pitch classes (index 0 is C). The energy of each
+// bin between 55 Hz and 5 kHz is added to the nearest pitch class for the given
+// reference pitch of A4. The result is normalized to a maximum of 1.
+func Chroma(spectrum []complex128, sampleRate, fftSize int, referenceHz float64) [12]float64 {
+	if referenceHz <= 0 {
+		referenceHz = DefaultReferencePitch
+	}
+
+	var chroma [12]float64
+	freqRes := float64(sampleRate) / float64(fftSize)
+	for i, c := range spectrum {
+		freq := float64(i) * freqRes
+		if freq < chromaMinHz || freq > chromaMaxHz {
+			continue
+		}
+		midi := int(math.Round(69 + 12*math.Log2(freq/referenceHz)))
+		mag := cmplx.Abs(c)
+		chroma[((midi%12)+12)%12] += mag * mag
+	}
+
+	peak := 0.0
+	for _, v := range chroma {
+		peak = math.Max(peak, v)
+	}
+	if peak > 0 {
+		for i := range chroma {
+			chroma[i] /= peak
+		}
+	}
+	return chroma
+}
+
+// Chromagram computes the chroma vector (see Chroma) of every STFT frame of samples
+func Chromagram(samples []float64, sampleRate, frameSize, hopSize int, referenceHz float64) [][12]float64 {
+	frames := STFT(samples, sampleRate, frameSize, hopSize)
+	chromagram := make([][12]float64, len(frames))
+	for i, frame := range frames {
+		chromagram[i] = Chroma(frame.Spectrum, sampleRate, frameSize, referenceHz)
+	}
+	return chromagram
+}
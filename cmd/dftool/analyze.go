@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/audio"
+	"github.com/epikur-io/go-discrete-fourier-transform/export"
+	"github.com/epikur-io/go-discrete-fourier-transform/plot"
+)
+
+// peakFlags are the criteria of the peak detection
+type peakFlags struct {
+	threshold  float64
+	floor      bool
+	prominence float64
+	top        int
+	sort       string
+	ref        float64
+}
+
+func (p *peakFlags) register(fs *flag.FlagSet) {
+	fs.Float64Var(&p.threshold, "mmt", 0.5, "Min. magnitude threshold (for detecting main peaks)")
+	fs.BoolVar(&p.floor, "floor", false, "interpret -mmt as dB above the local noise floor instead of an absolute magnitude")
+	fs.Float64Var(&p.prominence, "prominence", 0, "Min. peak prominence (height above the surrounding minima)")
+	fs.IntVar(&p.top, "top", 0, "report only the N strongest peaks (0 reports all)")
+	fs.StringVar(&p.sort, "sort", "frequency", "order of the reported peaks: frequency or magnitude")
+	fs.Float64Var(&p.ref, "ref", dft.DefaultReferencePitch, "reference pitch of A4 in Hz (for note labels)")
+}
+
+func (p *peakFlags) options() (dft.PeakOptions, error) {
+	order := dft.ByFrequency
+	switch p.sort {
+	case "frequency":
+	case "magnitude":
+		order = dft.ByMagnitude
+	default:
+		return dft.PeakOptions{}, fmt.Errorf("invalid sort order %q", p.sort)
+	}
+
+	neighborhoodHz := 3.0 // filter side lobes ±3Hz
+	opts := dft.PeakOptions{
+		MinHeight:     p.threshold,
+		MinProminence: p.prominence,
+		MinDistanceHz: neighborhoodHz,
+		MaxPeaks:      p.top,
+		Order:         order,
+	}
+	if p.floor {
+		opts.MinHeight = 0
+		opts.MinAboveFloorDB = p.threshold
+	}
+	return opts, nil
+}
+
+// report returns the JSON report of the analysis and its peaks with the flag
+// values of fs, if not nil, as parameters
+func (p *peakFlags) report(fs *flag.FlagSet, input string, result dft.Result, peaks []dft.Peak) export.Report {
+	report := export.NewResultReport(result, peaks)
+	report.Input = input
+	if fs != nil {
+		report.Parameters = map[string]any{}
+		fs.VisitAll(func(f *flag.Flag) {
+			report.Parameters[f.Name] = f.Value.(flag.Getter).Get()
+		})
+	}
+	for i, peak := range peaks {
+		if note, err := dft.NoteFromFrequency(peak.FreqHz, p.ref); err == nil {
+			report.Peaks[i].Note = note.String()
+		}
+	}
+	return report
+}
+
+// printPeaks prints the peaks with their note names
+func (p *peakFlags) printPeaks(peaks []dft.Peak) {
+	fmt.Println("Detected main frequencies:")
+	for _, peak := range peaks {
+		note, err := dft.NoteFromFrequency(peak.FreqHz, p.ref)
+		if err != nil {
+			fmt.Printf("Frequency: %.2f Hz, Magnitude: %.8f\n", peak.FreqHz, peak.Magnitude)
+			continue
+		}
+		fmt.Printf("Frequency: %.2f Hz, Magnitude: %.8f, Note: %s\n", peak.FreqHz, peak.Magnitude, note)
+	}
+}
+
+// spectrumFlags select the window, the FFT size and the detrending of the
+// analysis
+type spectrumFlags struct {
+	window  string
+	fftSize int
+	padding int
+	fastLen bool
+	detrend string
+}
+
+func (sf *spectrumFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&sf.window, "window", "hann", "analysis window: rectangular, hann, hamming, blackman, blackman-harris or kaiser:beta, e.g. kaiser:8.6")
+	fs.IntVar(&sf.fftSize, "fft-size", 0, "FFT size in samples, zero-pads the signal or frame (0 selects the next power of two)")
+	fs.IntVar(&sf.padding, "pad", 0, "zero-padding factor, e.g. 4 interpolates the spectrum 4 times finer without improving the resolution (0 pads to the next power of two, exclusive with -fft-size)")
+	fs.BoolVar(&sf.fastLen, "fast-len", false, "pad to the next length of the form 2^a·3^b·5^c instead of the next power of two, which is faster and smaller for awkward lengths")
+	fs.StringVar(&sf.detrend, "detrend", "none", "remove DC before the FFT: none, mean, linear (mean and drift) of the signal or frame, or dc-block (high-pass filter)")
+}
+
+func (sf *spectrumFlags) options() (dft.SpectrumOptions, error) {
+	window, err := dft.ParseWindow(sf.window)
+	if err != nil {
+		return dft.SpectrumOptions{}, err
+	}
+	if sf.fftSize < 0 {
+		return dft.SpectrumOptions{}, fmt.Errorf("invalid FFT size %d", sf.fftSize)
+	}
+	detrend, err := dft.ParseDetrend(sf.detrend)
+	if err != nil {
+		return dft.SpectrumOptions{}, err
+	}
+	return dft.SpectrumOptions{Window: window, FFTSize: sf.fftSize, Padding: sf.padding, FastLen: sf.fastLen, Detrend: detrend}, nil
+}
+
+// logResolution logs the bin width and the equivalent noise bandwidth of a
+// window of windowSize samples transformed with fftSize points
+func logResolution(in *inputFlags, window dft.Window, sampleRate, windowSize, fftSize int) {
+	in.logf("frequency resolution: %.4g Hz bin spacing, %.4g Hz effective resolution (noise bandwidth of the %s window of %d samples, %d point FFT)",
+		float64(sampleRate)/float64(fftSize), window.ENBW()*float64(sampleRate)/float64(windowSize),
+		window, windowSize, fftSize)
+}
+
+func csvOptions(path string, phase bool) export.CSVOptions {
+	opts := export.DefaultCSVOptions
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		opts.Comma = '\t'
+	}
+	opts.Phase = phase
+	return opts
+}
+
+func runAnalyze(args []string) error {
+	fs := newFlagSet("analyze")
+	var in inputFlags
+	var pf peakFlags
+	var sf spectrumFlags
+	in.register(fs, time.Second)
+	pf.register(fs)
+	sf.register(fs)
+	jsonOutput := fs.Bool("json", false, "print the results as JSON instead of text")
+	csvPath := fs.String("csv", "", "write the spectrum (frequency, magnitude, phase) to this CSV file, tab separated if it ends in .tsv")
+	npzPath := fs.String("npz", "", "write the spectrum to this NumPy .npz file")
+	outputFile := fs.String("out", "", "write the analyzed signal, after resampling and hum removal, to this 16 bit WAV file")
+	plotPath := fs.String("plot", "", "render the spectrum with the detected peaks to this PNG file")
+	bars := fs.Bool("tui", false, "draw the spectrum in the terminal as a bar graph")
+	parseFlags(fs, args)
+
+	opts, err := pf.options()
+	if err != nil {
+		return err
+	}
+	spectrumOpts, err := sf.options()
+	if err != nil {
+		return err
+	}
+	wave, sampleRate, err := in.load()
+	if err != nil {
+		return err
+	}
+
+	if *outputFile != "" {
+		if err := writeWAV(*outputFile, wave, sampleRate, audio.DefaultWAVOptions); err != nil {
+			return err
+		}
+	}
+
+	result, err := dft.Analyze(wave, sampleRate, in.offset(sampleRate), spectrumOpts)
+	if err != nil {
+		return err
+	}
+	result.GainDB = in.gainDB
+	spectrum := result.Spectrum
+	logResolution(&in, spectrumOpts.Window, sampleRate, len(wave), spectrum.FFTSize)
+
+	if *csvPath != "" {
+		err := writeFile(*csvPath, func(w io.Writer) error {
+			return export.WriteSpectrumCSV(w, spectrum, csvOptions(*csvPath, true))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	if *npzPath != "" {
+		err := writeFile(*npzPath, func(w io.Writer) error {
+			return export.WriteSpectrumNPZ(w, spectrum)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write NPZ: %w", err)
+		}
+	}
+	if *bars {
+		term, err := plot.NewTerminal(os.Stdout, sampleRate, spectrum.FFTSize, plot.DefaultTerminalOptions)
+		if err == nil {
+			err = term.Draw(spectrum.Magnitude)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	peaks := dft.FindPeaks(spectrum, opts)
+
+	if *plotPath != "" {
+		plotOpts := plot.DefaultSpectrumOptions
+		plotOpts.Peaks = peaks
+		plotOpts.Title = filepath.Base(in.path)
+		img, err := plot.Spectrum(spectrum, plotOpts)
+		if err != nil {
+			return fmt.Errorf("failed to plot spectrum: %w", err)
+		}
+		err = writeFile(*plotPath, func(w io.Writer) error {
+			return plot.WritePNG(w, img)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write plot: %w", err)
+		}
+	}
+
+	if *jsonOutput {
+		return export.WriteJSON(os.Stdout, pf.report(fs, in.path, result, peaks))
+	}
+	pf.printPeaks(peaks)
+	return nil
+}
+
+// runPeaks prints only the detected peaks, one tab separated line per peak or
+// as JSON array
+func runPeaks(args []string) error {
+	fs := newFlagSet("peaks")
+	var in inputFlags
+	var pf peakFlags
+	var sf spectrumFlags
+	in.register(fs, time.Second)
+	pf.register(fs)
+	sf.register(fs)
+	jsonOutput := fs.Bool("json", false, "print the peaks as JSON array instead of text")
+	parseFlags(fs, args)
+
+	opts, err := pf.options()
+	if err != nil {
+		return err
+	}
+	spectrumOpts, err := sf.options()
+	if err != nil {
+		return err
+	}
+	wave, sampleRate, err := in.load()
+	if err != nil {
+		return err
+	}
+	result, err := dft.Analyze(wave, sampleRate, in.offset(sampleRate), spectrumOpts)
+	if err != nil {
+		return err
+	}
+	result.GainDB = in.gainDB
+	logResolution(&in, spectrumOpts.Window, sampleRate, len(wave), result.FFTSize)
+	peaks := pf.report(fs, in.path, result, dft.FindPeaks(result.Spectrum, opts)).Peaks
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(peaks)
+	}
+	for _, p := range peaks {
+		fmt.Printf("%.2f\t%.8f\t%.2f\t%s\n", p.FreqHz, p.Magnitude, p.MagnitudeDB, p.Note)
+	}
+	return nil
+}
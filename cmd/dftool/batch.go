@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/audio"
+	"github.com/epikur-io/go-discrete-fourier-transform/export"
+)
+
+// batchRecord is the result of a file in batch mode
+type batchRecord struct {
+	Input      string               `json:"input"`
+	Error      string               `json:"error,omitempty"`
+	Duration   float64              `json:"duration_s,omitempty"`
+	Spectrum   *export.SpectrumInfo `json:"spectrum,omitempty"`
+	Provenance *dft.Provenance      `json:"provenance,omitempty"`
+	Peaks      []export.Peak        `json:"peaks"`
+}
+
+// expandPaths resolves files, directories and glob patterns into a list of
+// files. Directories are searched recursively for files with a known audio
+// extension, or for all files if raw is set.
+func expandPaths(args []string, raw bool) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	for _, arg := range args {
+		matches := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(arg); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %q", arg)
+			}
+		}
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(path)
+				continue
+			}
+			err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.Type().IsRegular() && (raw || audio.FormatFromExtension(p) != audio.Unknown) {
+					add(p)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// runBatch analyzes many files concurrently and writes one record per file in
+// the order of the arguments
+func runBatch(args []string) error {
+	fs := newFlagSet("batch")
+	var in inputFlags
+	var pf peakFlags
+	var sf spectrumFlags
+	in.register(fs, 0)
+	pf.register(fs)
+	sf.register(fs)
+	workers := fs.Int("j", runtime.NumCPU(), "number of files analyzed concurrently")
+	format := fs.String("format", "jsonl", "output format: jsonl (one JSON record per file) or csv (one summary line per file)")
+	outputFile := fs.String("o", "", "write the records to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: dftool batch [flags] files, directories or patterns...\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+
+	paths := fs.Args()
+	if in.path != "" {
+		paths = append([]string{in.path}, paths...)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no input files")
+	}
+	if *format != "jsonl" && *format != "csv" {
+		return fmt.Errorf("invalid output format %q", *format)
+	}
+	opts, err := pf.options()
+	if err != nil {
+		return err
+	}
+	spectrumOpts, err := sf.options()
+	if err != nil {
+		return err
+	}
+	files, err := expandPaths(paths, in.raw != "")
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if *outputFile != "" {
+		f, err := os.Create(*outputFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	write := writeJSONRecord(out)
+	if *format == "csv" {
+		write = writeCSVRecord(out)
+	}
+
+	// The files are decoded concurrently, show the progress over the files
+	var bar *progressBar
+	if in.progress {
+		bar = newProgressBar("analyzing")
+	}
+	in.quiet = true
+	records := make([]chan batchRecord, len(files))
+	for i := range records {
+		records[i] = make(chan batchRecord, 1)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range max(*workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				records[i] <- analyzeFile(&in, &pf, files[i], spectrumOpts, opts)
+			}
+		}()
+	}
+	go func() {
+		for i := range files {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	start := time.Now()
+	failed := 0
+	for i, ch := range records {
+		r := <-ch
+		if r.Error != "" {
+			failed++
+		}
+		if err := write(r); err != nil {
+			return err
+		}
+		if bar != nil {
+			bar.update(dft.Progress{Done: int64(i + 1), Total: int64(len(files)), Elapsed: time.Since(start)})
+		}
+	}
+	if bar != nil {
+		bar.finish()
+	}
+	wg.Wait()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed", failed, len(files))
+	}
+	return nil
+}
+
+// analyzeFile detects the peaks of a file, errors are stored in the record
+func analyzeFile(in *inputFlags, pf *peakFlags, path string, spectrumOpts dft.SpectrumOptions, opts dft.PeakOptions) batchRecord {
+	r := batchRecord{Input: path, Peaks: []export.Peak{}}
+	// The workers share the flags, loading stores the trimmed silence
+	fileIn := *in
+	in = &fileIn
+	wave, sampleRate, err := in.loadFile(path)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	if len(wave) == 0 {
+		r.Error = "no samples"
+		return r
+	}
+	result, err := dft.Analyze(wave, sampleRate, in.offset(sampleRate), spectrumOpts)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	result.GainDB = in.gainDB
+	report := pf.report(nil, path, result, dft.FindPeaks(result.Spectrum, opts))
+	r.Duration = float64(len(wave)) / float64(sampleRate)
+	r.Spectrum = &report.Spectrum
+	r.Provenance = report.Provenance
+	r.Peaks = report.Peaks
+	return r
+}
+
+func writeJSONRecord(w io.Writer) func(batchRecord) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return func(r batchRecord) error {
+		return enc.Encode(r)
+	}
+}
+
+// writeCSVRecord writes a header and a summary line per file with the
+// strongest peak
+func writeCSVRecord(w io.Writer) func(batchRecord) error {
+	cw := csv.NewWriter(w)
+	header := true
+	return func(r batchRecord) error {
+		if header {
+			cw.Write([]string{"input", "sample_rate", "duration_s", "peaks", "strongest_hz", "strongest_db", "strongest_note", "error"})
+			header = false
+		}
+		row := []string{r.Input, "", "", "", "", "", "", r.Error}
+		if r.Spectrum != nil {
+			row[1] = strconv.Itoa(r.Spectrum.SampleRate)
+			row[2] = strconv.FormatFloat(r.Duration, 'f', -1, 64)
+			row[3] = strconv.Itoa(len(r.Peaks))
+		}
+		if len(r.Peaks) > 0 {
+			strongest := r.Peaks[0]
+			for _, p := range r.Peaks[1:] {
+				if p.Magnitude > strongest.Magnitude {
+					strongest = p
+				}
+			}
+			row[4] = strconv.FormatFloat(strongest.FreqHz, 'f', 3, 64)
+			row[5] = strconv.FormatFloat(strongest.MagnitudeDB, 'f', 2, 64)
+			row[6] = strongest.Note
+		}
+		cw.Write(row)
+		cw.Flush()
+		return cw.Error()
+	}
+}
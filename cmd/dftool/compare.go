@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/measure"
+)
+
+// loadPair loads a reference and a test file with the same input flags and
+// checks that their sample rates match
+func loadPair(in *inputFlags, refPath, testPath string) (ref, test []float64, sampleRate int, err error) {
+	ref, sampleRate, err = in.loadFile(refPath)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("%s: %w", refPath, err)
+	}
+	test, testRate, err := in.loadFile(testPath)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("%s: %w", testPath, err)
+	}
+	if testRate != sampleRate {
+		return nil, nil, 0, fmt.Errorf("the sample rates differ (%d Hz and %d Hz), resample both with -rate", sampleRate, testRate)
+	}
+	return ref, test, sampleRate, nil
+}
+
+// runCompare compares the frequency response of a test recording to a
+// reference, e.g. the output and input of an audio chain
+func runCompare(args []string) error {
+	fs := newFlagSet("compare")
+	var in inputFlags
+	in.register(fs, 0)
+	fftSize := fs.Int("fft-size", measure.DefaultCompareOptions.FFTSize, "frame size of the averaged spectra in samples")
+	maxDelay := fs.Float64("max-delay", measure.DefaultCompareOptions.MaxDelay, "largest delay between the files searched for in seconds")
+	bandsPerOctave := fs.Int("bands-per-octave", measure.DefaultCompareOptions.BandsPerOctave, "width of the reported bands, 1 for octaves or 3 for third octaves")
+	minHz := fs.Float64("min-freq", measure.DefaultCompareOptions.MinHz, "lowest band center in Hz")
+	maxHz := fs.Float64("max-freq", 0, "highest band center in Hz (0 up to the Nyquist frequency)")
+	jsonOutput := fs.Bool("json", false, "print the result as JSON instead of a table")
+	csvPath := fs.String("csv", "", "write the transfer function (frequency, dB, phase, coherence) to this CSV file")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: dftool compare [flags] reference test\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected a reference and a test file")
+	}
+	ref, test, sampleRate, err := loadPair(&in, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	c, err := measure.Compare(ref, test, sampleRate, measure.CompareOptions{
+		FFTSize:        *fftSize,
+		MaxDelay:       *maxDelay,
+		BandsPerOctave: *bandsPerOctave,
+		MinHz:          *minHz,
+		MaxHz:          *maxHz,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *csvPath != "" {
+		err := writeFile(*csvPath, func(w io.Writer) error {
+			cw := csv.NewWriter(w)
+			cw.Write([]string{"freq_hz", "transfer_db", "phase_rad", "coherence"})
+			for k, f := range c.Freqs {
+				cw.Write([]string{
+					strconv.FormatFloat(f, 'f', -1, 64),
+					strconv.FormatFloat(c.TransferDB[k], 'g', 6, 64),
+					strconv.FormatFloat(c.TransferPhase[k], 'g', 6, 64),
+					strconv.FormatFloat(c.Coherence[k], 'g', 6, 64),
+				})
+			}
+			cw.Flush()
+			return cw.Error()
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			DelayS         float64                 `json:"delay_s"`
+			GainDB         float64                 `json:"gain_db"`
+			MaxDeviationDB float64                 `json:"max_deviation_db"`
+			Bands          []measure.BandDeviation `json:"bands"`
+		}{c.Delay, c.GainDB, c.MaxDeviationDB, c.Bands})
+	}
+
+	fmt.Printf("delay: %.3f ms\ngain: %.2f dB\nmax. deviation: %.2f dB\n\n", c.Delay*1000, c.GainDB, c.MaxDeviationDB)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "band Hz\tlevel dB\tdeviation dB\tcoherence\t")
+	for _, b := range c.Bands {
+		fmt.Fprintf(w, "%.0f\t%.2f\t%+.2f\t%.3f\t\n", b.CenterHz, b.LevelDB, b.DeviationDB, b.Coherence)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Config files set the flags of the commands, so an analysis setup can be
+// versioned and shared. Keys are flag names. Keys at the top level apply to
+// every command that has such a flag, keys in a section named after a command
+// only to that command and take precedence. Flags given on the command line
+// override the file.
+//
+// Two small formats are understood, TOML (the default):
+//
+//	mmt = 0.05
+//	channel = "left"
+//
+//	[spectrogram]
+//	frame = 4096
+//	cmap = "magma"
+//
+// and YAML for files ending in .yaml or .yml:
+//
+//	mmt: 0.05
+//	spectrogram:
+//	  frame: 4096
+//
+// Arrays like [50, 120, 300] are passed to the flag as comma separated list.
+
+// configEntry is a key-value pair of a config file
+type configEntry struct {
+	section string
+	key     string
+	value   string
+	line    int
+}
+
+// parseFlags parses the command line of a command. Flags that are not given
+// on the command line are read from the file of the -config flag.
+func parseFlags(fs *flag.FlagSet, args []string) {
+	path := fs.String("config", "", "read flag values from this TOML or YAML file, flags on the command line take precedence")
+	fs.Parse(args)
+	if *path == "" {
+		return
+	}
+	if err := applyConfig(fs, *path); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		os.Exit(2)
+	}
+}
+
+// applyConfig sets the flags of fs that were not set on the command line from
+// the config file at path
+func applyConfig(fs *flag.FlagSet, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	entries, err := parseConfig(string(data), ext == ".yaml" || ext == ".yml")
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	command := strings.TrimPrefix(fs.Name(), "dftool ")
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	// Apply the top level first, so that the command section overrides it
+	for _, section := range []string{"", command} {
+		for _, e := range entries {
+			if e.section != section || set[e.key] || e.key == "config" {
+				continue
+			}
+			if fs.Lookup(e.key) == nil {
+				if section == "" {
+					continue
+				}
+				return fmt.Errorf("%s:%d: unknown flag %q of command %s", path, e.line, e.key, command)
+			}
+			if err := fs.Set(e.key, e.value); err != nil {
+				return fmt.Errorf("%s:%d: invalid value for %s: %v", path, e.line, e.key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseConfig parses the TOML or YAML subset described above
+func parseConfig(data string, yaml bool) ([]configEntry, error) {
+	var entries []configEntry
+	section := ""
+	for i, line := range strings.Split(data, "\n") {
+		indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		var key, raw string
+		var ok bool
+		if yaml {
+			key, raw, ok = strings.Cut(line, ":")
+			raw = strings.TrimSpace(raw)
+			if ok && !indented && (raw == "" || raw[0] == '#') {
+				section = strings.TrimSpace(key)
+				continue
+			}
+			if !indented {
+				section = ""
+			}
+		} else {
+			if line[0] == '[' && strings.HasSuffix(line, "]") {
+				section = strings.TrimSpace(line[1 : len(line)-1])
+				continue
+			}
+			key, raw, ok = strings.Cut(line, "=")
+		}
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected key and value", i+1)
+		}
+		value, err := parseConfigValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		entries = append(entries, configEntry{section: section, key: key, value: value, line: i + 1})
+	}
+	return entries, nil
+}
+
+// parseConfigValue returns the flag value of a quoted or bare string, number,
+// boolean or array, followed by an optional comment
+func parseConfigValue(raw string) (string, error) {
+	switch {
+	case raw == "":
+		return "", nil
+	case raw[0] == '"' || raw[0] == '\'':
+		end := closingQuote(raw)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", raw)
+		}
+		if rest := strings.TrimSpace(raw[end+1:]); rest != "" && rest[0] != '#' {
+			return "", fmt.Errorf("unexpected %q after string", rest)
+		}
+		if raw[0] == '\'' {
+			return raw[1:end], nil
+		}
+		return strconv.Unquote(raw[:end+1])
+	case raw[0] == '[':
+		end := strings.LastIndexByte(raw, ']')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated array %s", raw)
+		}
+		var items []string
+		for _, item := range strings.Split(raw[1:end], ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			v, err := parseConfigValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, v)
+		}
+		return strings.Join(items, ","), nil
+	}
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = raw[:i]
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// closingQuote returns the index of the quote that ends the string at the
+// start of s, or -1
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && s[0] == '"':
+			i++
+		case s[i] == s[0]:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/features"
+)
+
+// runDistance prints the spectral distance of two recordings, of their
+// average spectra and of their spectrograms aligned by DTW
+func runDistance(args []string) error {
+	fs := newFlagSet("distance")
+	var in inputFlags
+	in.register(fs, 0)
+	metric := fs.String("metric", "lsd", "distance of two spectra: cosine, lsd (log-spectral distance in dB) or itakura-saito")
+	frameSize := fs.Int("frame", 2048, "STFT frame size in samples")
+	hopSize := fs.Int("hop", 512, "STFT hop size in samples")
+	radius := fs.Float64("radius", 0, "largest deviation of the DTW path from the diagonal in seconds (0 for no limit)")
+	pathFile := fs.String("path", "", "write the DTW path (time in the first and in the second file) to this CSV file")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: dftool distance [flags] first second\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected two files")
+	}
+	d, err := features.ParseDistance(*metric)
+	if err != nil {
+		return err
+	}
+	if *frameSize <= 0 || *hopSize <= 0 {
+		return fmt.Errorf("invalid frame size %d or hop size %d", *frameSize, *hopSize)
+	}
+	a, b, sampleRate, err := loadPair(&in, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	pa := features.PowerFrames(dft.STFT(a, sampleRate, *frameSize, *hopSize))
+	pb := features.PowerFrames(dft.STFT(b, sampleRate, *frameSize, *hopSize))
+	if len(pa) == 0 || len(pb) == 0 {
+		return fmt.Errorf("a file is shorter than a frame of %d samples", *frameSize)
+	}
+
+	alignment, err := features.DTW(pa, pb, d, int(*radius*float64(sampleRate)/float64(*hopSize)))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("average spectrum: %.4g\n", d(average(pa), average(pb)))
+	fmt.Printf("aligned frames: %.4g (%d steps, total %.4g)\n", alignment.Normalized(), len(alignment.Path), alignment.Cost)
+
+	if *pathFile != "" {
+		return writeFile(*pathFile, func(w io.Writer) error {
+			cw := csv.NewWriter(w)
+			cw.Write([]string{"first_s", "second_s"})
+			for _, p := range alignment.Path {
+				cw.Write([]string{
+					strconv.FormatFloat(dft.FrameTime(p[0], sampleRate, *frameSize, *hopSize), 'f', 4, 64),
+					strconv.FormatFloat(dft.FrameTime(p[1], sampleRate, *frameSize, *hopSize), 'f', 4, 64),
+				})
+			}
+			cw.Flush()
+			return cw.Error()
+		})
+	}
+	return nil
+}
+
+// average returns the mean of equally long spectra
+func average(spectra [][]float64) []float64 {
+	mean := make([]float64, len(spectra[0]))
+	for _, s := range spectra {
+		for k, v := range s {
+			mean[k] += v / float64(len(spectra))
+		}
+	}
+	return mean
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/fingerprint"
+)
+
+// duplicate is a pair of recordings with matching fingerprints
+type duplicate struct {
+	A        string  `json:"a"`
+	B        string  `json:"b"`
+	Score    int     `json:"score"`
+	Coverage float64 `json:"coverage"`
+	Offset   float64 `json:"offset_s"` // position of the start of A within B
+}
+
+// runDupes fingerprints audio files and reports the pairs that contain the
+// same recording
+func runDupes(args []string) error {
+	fs := newFlagSet("dupes")
+	var in inputFlags
+	in.register(fs, 0)
+	workers := fs.Int("j", runtime.NumCPU(), "number of files fingerprinted concurrently")
+	minScore := fs.Int("min-score", 20, "least number of time aligned fingerprints of a match")
+	minCoverage := fs.Float64("min-coverage", 0.05, "least share of the fingerprints of the shorter file that match")
+	jsonOutput := fs.Bool("json", false, "print the duplicates as JSON array instead of a table")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: dftool dupes [flags] files, directories or patterns...\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+
+	paths := fs.Args()
+	if in.path != "" {
+		paths = append([]string{in.path}, paths...)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no input files")
+	}
+	files, err := expandPaths(paths, in.raw != "")
+	if err != nil {
+		return err
+	}
+
+	var bar *progressBar
+	if in.progress {
+		bar = newProgressBar("fingerprinting")
+	}
+	in.quiet = true
+	opts := fingerprint.DefaultOptions
+	prints := make([][]fingerprint.Fingerprint, len(files))
+	errs := make([]error, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+	start := time.Now()
+	for range max(*workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				samples, sampleRate, err := in.loadFile(files[i])
+				if err == nil {
+					prints[i], err = fingerprint.Compute(samples, sampleRate, opts)
+				}
+				errs[i] = err
+				if bar != nil {
+					mu.Lock()
+					done++
+					bar.update(dft.Progress{Done: int64(done), Total: int64(len(files)), Elapsed: time.Since(start)})
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if bar != nil {
+		bar.finish()
+	}
+
+	// IDs of the index are the positions in files
+	ix := fingerprint.NewIndex(opts)
+	failed := 0
+	for i, path := range files {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "dftool: %s: %v\n", path, errs[i])
+			failed++
+		}
+		ix.Add(path, prints[i])
+	}
+	dupes := []duplicate{}
+	for i, fps := range prints {
+		for _, m := range ix.Lookup(fps, *minScore) {
+			if m.ID <= i || m.Coverage < *minCoverage {
+				continue
+			}
+			dupes = append(dupes, duplicate{files[i], m.Name, m.Score, m.Coverage, m.Offset})
+		}
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(dupes); err != nil {
+			return err
+		}
+	} else if len(dupes) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "file\tduplicate\tscore\tcoverage\toffset")
+		for _, d := range dupes {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%.0f%%\t%s\n", d.A, d.B, d.Score, d.Coverage*100, formatOffset(d.Offset))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed", failed, len(files))
+	}
+	return nil
+}
+
+// formatOffset formats a signed offset in seconds as time
+func formatOffset(s float64) string {
+	if s < 0 {
+		return "-" + formatTime(seconds(-s))
+	}
+	return formatTime(seconds(s))
+}
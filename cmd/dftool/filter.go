@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/audio"
+	"github.com/epikur-io/go-discrete-fourier-transform/filter"
+)
+
+var bandTypes = map[string]filter.BandType{
+	"lowpass":  filter.LowPass,
+	"highpass": filter.HighPass,
+	"bandpass": filter.BandPass,
+	"bandstop": filter.BandStop,
+}
+
+// runFilter writes the input, after resampling and hum removal, filtered with
+// a windowed-sinc FIR filter to a WAV file
+func runFilter(args []string) error {
+	fs := newFlagSet("filter")
+	var in inputFlags
+	in.register(fs, 0)
+	bandName := fs.String("type", "", "filter type: lowpass, highpass, bandpass or bandstop (empty applies only -rate and -dehum)")
+	cutoff := fs.Float64("cutoff", 0, "cutoff frequency in Hz, the lower edge of band filters")
+	cutoffHigh := fs.Float64("cutoff-high", 0, "upper edge of band filters in Hz")
+	transition := fs.Float64("transition", 100, "width of the transition band in Hz, narrower bands need longer filters")
+	zeroPhase := fs.Bool("zero-phase", false, "filter forward and backward to cancel the phase shift (squares the magnitude response)")
+	bitDepth := fs.Int("bits", 16, "bits per sample of the output: 8, 16, 24 or 32")
+	outputFile := fs.String("out", "", "path of the written WAV file")
+	parseFlags(fs, args)
+
+	if *outputFile == "" {
+		return fmt.Errorf("missing output file")
+	}
+	band, ok := bandTypes[*bandName]
+	if !ok && *bandName != "" {
+		return fmt.Errorf("invalid filter type %q", *bandName)
+	}
+
+	wave, sampleRate, err := in.load()
+	if err != nil {
+		return err
+	}
+
+	if *bandName != "" {
+		// Blackman attenuates the stopband by about 74 dB
+		taps, err := filter.DesignFIR(filter.FIRSpec{
+			Band:         band,
+			SampleRate:   sampleRate,
+			CutoffHz:     *cutoff,
+			CutoffHighHz: *cutoffHigh,
+			TransitionHz: *transition,
+			Window:       dft.Window{Type: dft.Blackman},
+		})
+		if err != nil {
+			return err
+		}
+		fir := filter.FIR(taps)
+		if *zeroPhase {
+			wave = filter.FiltFilt(fir, wave)
+		} else {
+			wave = fir.Filter(wave)
+		}
+	}
+	return writeWAV(*outputFile, wave, sampleRate, audio.WAVOptions{BitDepth: *bitDepth})
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/lpc"
+	"github.com/epikur-io/go-discrete-fourier-transform/vad"
+)
+
+// runFormants prints the formant frequencies of a speech recording per frame
+func runFormants(args []string) error {
+	fs := newFlagSet("formants")
+	var in inputFlags
+	in.register(fs, 0)
+	frame := fs.Duration("frame", 25*time.Millisecond, "length of the analysis frames")
+	hop := fs.Duration("hop", 10*time.Millisecond, "distance of the analysis frames")
+	maxHz := fs.Float64("max-freq", lpc.DefaultFormantOptions.MaxHz, "upper limit of the formants in Hz, 5000 for male and 5500 for female voices")
+	order := fs.Int("order", 0, "order of the LPC model (0 for 2 + 2·max-freq/1000)")
+	count := fs.Int("count", lpc.DefaultFormantOptions.Count, "number of formants per frame")
+	speechOnly := fs.Bool("vad", false, "only analyze the frames detected as speech")
+	jsonOutput := fs.Bool("json", false, "print the frames as JSON array instead of CSV")
+	parseFlags(fs, args)
+
+	samples, sampleRate, err := in.load()
+	if err != nil {
+		return err
+	}
+	opts := lpc.DefaultFormantOptions
+	opts.MaxHz = *maxHz
+	opts.Order = *order
+	opts.Count = *count
+	frameSize := int(frame.Seconds() * float64(sampleRate))
+	hopSize := int(hop.Seconds() * float64(sampleRate))
+	frames, err := lpc.Track(samples, sampleRate, frameSize, hopSize, opts)
+	if err != nil {
+		return err
+	}
+	if *speechOnly {
+		segments, err := vad.Detect(samples, sampleRate, vad.DefaultOptions)
+		if err != nil {
+			return err
+		}
+		speech := frames[:0]
+		for _, f := range frames {
+			if vad.IsSpeech(segments, f.Time) {
+				speech = append(speech, f)
+			}
+		}
+		frames = speech
+	}
+	// Times refer to the start of the file, not of the analyzed range
+	offset := in.start().Seconds()
+	for i := range frames {
+		frames[i].Time += offset
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(frames)
+	}
+	w := csv.NewWriter(os.Stdout)
+	header := []string{"time_s"}
+	for k := 1; k <= opts.Count; k++ {
+		header = append(header, fmt.Sprintf("f%d_hz", k), fmt.Sprintf("b%d_hz", k))
+	}
+	w.Write(header)
+	for _, f := range frames {
+		record := []string{strconv.FormatFloat(f.Time, 'f', 4, 64)}
+		for k := range opts.Count {
+			// Frames with fewer formants leave their columns empty
+			if k < len(f.Formants) {
+				record = append(record, strconv.FormatFloat(f.Formants[k].FreqHz, 'f', 1, 64), strconv.FormatFloat(f.Formants[k].BandwidthHz, 'f', 1, 64))
+			} else {
+				record = append(record, "", "")
+			}
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/audio"
+	"github.com/epikur-io/go-discrete-fourier-transform/siggen"
+)
+
+func maxAbs(samples []float64) float64 {
+	peak := 0.0
+	for _, v := range samples {
+		peak = math.Max(peak, math.Abs(v))
+	}
+	return peak
+}
+
+// parseFloats parses a comma separated list of numbers
+func parseFloats(s string) ([]float64, error) {
+	var values []float64
+	for _, item := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// runGenerate writes a test signal of the siggen package to a WAV file
+func runGenerate(args []string) error {
+	fs := newFlagSet("generate")
+	typeName := fs.String("type", "sine", "signal: sine, square, sawtooth, triangle (summed over -freqs), impulse, white, pink or brown noise, or an exponential sweep between the two -freqs")
+	freqList := fs.String("freqs", "50,120,300", "comma separated frequencies of the periodic waves in Hz")
+	ampList := fs.String("amps", "0.4,0.2,0.32", "comma separated amplitudes of the periodic waves, the amplitude of an impulse or the RMS level of noise")
+	phaseList := fs.String("phases", "", "comma separated start phases of the periodic waves in degrees (default 0)")
+	phaseMode := fs.String("phase-mode", "", "set the phases of sine multitones: schroeder (low crest factor) or random")
+	seed := fs.Uint64("seed", 1, "seed of the noise generator and of random phases")
+	sampleRate := fs.Int("rate", 1024, "sample rate in Hz")
+	duration := fs.Float64("duration", 15, "duration in seconds")
+	pad := fs.Float64("pad", 0, "append this many seconds of silence, e.g. to record the decay after a sweep")
+	bitDepth := fs.Int("bits", 16, "bits per sample: 8, 16, 24 or 32")
+	float := fs.Bool("float", false, "write 32 bit float samples, which are not clipped but can't be read back by dftool")
+	outputFile := fs.String("out", "", "path of the written WAV file")
+	parseFlags(fs, args)
+
+	if *outputFile == "" {
+		return fmt.Errorf("missing output file")
+	}
+	freqs, err := parseFloats(*freqList)
+	if err != nil {
+		return fmt.Errorf("invalid frequencies: %w", err)
+	}
+	amplitudes, err := parseFloats(*ampList)
+	if err != nil {
+		return fmt.Errorf("invalid amplitudes: %w", err)
+	}
+	phases := make([]float64, len(freqs))
+	if *phaseList != "" {
+		if phases, err = parseFloats(*phaseList); err != nil {
+			return fmt.Errorf("invalid phases: %w", err)
+		}
+	}
+	if *sampleRate <= 0 || *duration <= 0 {
+		return fmt.Errorf("invalid sample rate %d or duration %g", *sampleRate, *duration)
+	}
+
+	var wave []float64
+	if color, err := siggen.ParseNoiseColor(*typeName); err == nil {
+		wave = siggen.Noise(color, amplitudes[0], *sampleRate, *duration, *seed)
+	} else if *typeName == "impulse" {
+		wave = siggen.Impulse(amplitudes[0], 0, *sampleRate, *duration)
+	} else if *typeName == "sweep" {
+		if len(freqs) != 2 {
+			return fmt.Errorf("a sweep needs a start and an end frequency, e.g. -freqs 20,20000")
+		}
+		sweep := siggen.Sweep{StartHz: freqs[0], EndHz: freqs[1], Duration: *duration, Amplitude: amplitudes[0], SampleRate: *sampleRate, Fade: siggen.DefaultSweep.Fade}
+		if wave, err = sweep.Generate(); err != nil {
+			return err
+		}
+	} else {
+		waveform, err := siggen.ParseWaveform(*typeName)
+		if err != nil {
+			return fmt.Errorf("invalid signal type %q", *typeName)
+		}
+		if len(freqs) != len(amplitudes) || len(freqs) != len(phases) {
+			return fmt.Errorf("got %d frequencies, %d amplitudes and %d phases", len(freqs), len(amplitudes), len(phases))
+		}
+		tones := make([]siggen.Tone, len(freqs))
+		for i := range freqs {
+			tones[i] = siggen.Tone{FreqHz: freqs[i], Amplitude: amplitudes[i], Phase: phases[i] * math.Pi / 180}
+		}
+		switch *phaseMode {
+		case "":
+		case "schroeder":
+			tones = siggen.SchroederPhases(tones)
+		case "random":
+			tones = siggen.RandomPhases(tones, *seed)
+		default:
+			return fmt.Errorf("invalid phase mode %q", *phaseMode)
+		}
+		if *phaseMode != "" && waveform != siggen.Sine {
+			return fmt.Errorf("-phase-mode requires -type sine")
+		}
+		if waveform == siggen.Sine {
+			wave = siggen.Multitone(tones, *sampleRate, *duration)
+		} else {
+			wave = make([]float64, int(float64(*sampleRate)**duration))
+			for _, t := range tones {
+				for i, v := range siggen.Periodic(waveform, t, *sampleRate, *duration) {
+					wave[i] += v
+				}
+			}
+		}
+	}
+
+	if *pad > 0 {
+		wave = append(wave, make([]float64, int(*pad*float64(*sampleRate)))...)
+	}
+
+	opts := audio.WAVOptions{BitDepth: *bitDepth, Float: *float}
+	if peak := maxAbs(wave); peak > 1 && !*float {
+		log.Printf("the peak level of %.2f exceeds full scale and is clipped, lower the amplitudes or use -float", peak)
+	}
+	return writeWAV(*outputFile, wave, *sampleRate, opts)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/health"
+)
+
+// runHealth prints the clipped regions, DC offset and dropouts of all
+// channels of a file
+func runHealth(args []string) error {
+	fs := newFlagSet("health")
+	var in inputFlags
+	in.register(fs, 0)
+	clipLevel := fs.Float64("clip-level", health.DefaultOptions.ClipLevelDB, "least level of clipped samples in dBFS")
+	minClip := fs.Int("min-clip", health.DefaultOptions.MinClipRun, "least number of equal samples of a clipped region")
+	dcLimit := fs.Float64("dc-limit", health.DefaultOptions.DCLimitDB, "highest tolerated DC offset in dBFS")
+	minDropout := fs.Float64("min-dropout", health.DefaultOptions.MinDropout, "shortest dropout in seconds")
+	jsonOutput := fs.Bool("json", false, "print the report as JSON")
+	parseFlags(fs, args)
+
+	// The report of this command replaces the warnings
+	in.health = false
+	channels, sampleRate, err := in.loadChannels()
+	if err != nil {
+		return err
+	}
+	opts := health.Options{ClipLevelDB: *clipLevel, MinClipRun: *minClip, DCLimitDB: *dcLimit, MinDropout: *minDropout}
+	report, err := health.Check(channels, sampleRate, opts)
+	if err != nil {
+		return err
+	}
+	report.Shift(in.start().Seconds())
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "channel\tproblem\tstart\tend\tdetail")
+		for i, c := range report.Channels {
+			for _, r := range c.Clips {
+				fmt.Fprintf(w, "%d\tclipping\t%s\t%s\t%d samples\n", i, formatTime(seconds(r.Start)), formatTime(seconds(r.End)),
+					int(r.Duration()*float64(sampleRate)+0.5))
+			}
+			if c.HasDCOffset {
+				fmt.Fprintf(w, "%d\tDC offset\t\t\t%.4g (%.1f dBFS)\n", i, c.DCOffset, c.DCOffsetDB)
+			}
+			for _, r := range c.Dropouts {
+				fmt.Fprintf(w, "%d\tdropout\t%s\t%s\t%.1f ms\n", i, formatTime(seconds(r.Start)), formatTime(seconds(r.End)), r.Duration()*1000)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if !report.OK() {
+		return fmt.Errorf("the input has capture problems")
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/audio"
+	"github.com/epikur-io/go-discrete-fourier-transform/audio/pcm"
+	"github.com/epikur-io/go-discrete-fourier-transform/filter"
+	"github.com/epikur-io/go-discrete-fourier-transform/health"
+	"github.com/epikur-io/go-discrete-fourier-transform/loudness"
+	"github.com/epikur-io/go-discrete-fourier-transform/resample"
+	"github.com/epikur-io/go-discrete-fourier-transform/silence"
+)
+
+// inputFlags selects and preprocesses the analyzed part of an audio file
+type inputFlags struct {
+	path        string
+	from        timeValue
+	to          timeValue
+	duration    timeValue
+	raw         string
+	rawRate     int
+	rawChannels int
+	channel     string
+	rate        int
+	dehum       bool
+	health      bool // warn about clipping, DC offset and dropouts
+	preEmphasis float64
+	normalize   string
+	gainDB      float64 // applied by -normalize
+	trim        bool
+	trimDB      float64
+	trimmed     time.Duration // leading silence removed by -trim
+	progress    bool
+	quiet       bool // don't log the processing steps
+}
+
+// register adds the input flags to fs, a duration of 0 reads to the end of
+// the file
+func (in *inputFlags) register(fs *flag.FlagSet, duration time.Duration) {
+	in.duration.d = duration
+	fs.StringVar(&in.path, "input", "", "path of the input audio file")
+	fs.Var(&in.from, "from", "start of the analyzed range as [[hh:]mm:]ss[.ms], e.g. 1:23.5, or as duration like 83.5s")
+	fs.Var(&in.to, "to", "end of the analyzed range, like -from (replaces -duration)")
+	fs.Var(&in.duration, "duration", "length of the analyzed range, e.g. 10s or 0:10 (0 reads to the end)")
+	fs.StringVar(&in.raw, "raw", "", "read the input as header-less PCM with this encoding, e.g. s16le, s24be, u8 or f32le")
+	fs.IntVar(&in.rawRate, "raw-rate", 48000, "sample rate of raw PCM input in Hz")
+	fs.IntVar(&in.rawChannels, "raw-channels", 1, "number of interleaved channels of raw PCM input")
+	fs.StringVar(&in.channel, "channel", "mid", "analyzed channel: mid (mono downmix), side, left, right or a channel index starting at 0")
+	fs.IntVar(&in.rate, "rate", 0, "resample the input to this sample rate in Hz before the analysis (0 keeps the original rate)")
+	fs.BoolVar(&in.dehum, "dehum", false, "detect and remove 50/60 Hz mains hum and its harmonics before the analysis")
+	fs.Float64Var(&in.preEmphasis, "pre-emphasis", 0, fmt.Sprintf("apply the pre-emphasis y[n] = x[n] - a·x[n-1] with this coefficient a before the analysis, e.g. %g for speech (0 disables it)", filter.DefaultPreEmphasis))
+	fs.StringVar(&in.normalize, "normalize", "none", "bring the input to a common level before the analysis: none, peak, rms or lufs, with an optional target like peak:-3 (default -1 dBFS, -20 dBFS and -23 LUFS)")
+	fs.BoolVar(&in.trim, "trim", false, "remove the leading and trailing silence before the analysis")
+	fs.Float64Var(&in.trimDB, "trim-threshold", silence.DefaultOptions.ThresholdDB, "level of silence for -trim in dBFS")
+	fs.BoolVar(&in.health, "health", true, "check the input for clipping, DC offset and dropouts and warn before the analysis")
+	fs.BoolVar(&in.progress, "progress", isTerminal(os.Stderr), "show a progress bar with ETA on stderr for long operations (default on a terminal)")
+}
+
+func (in *inputFlags) logf(format string, args ...any) {
+	if !in.quiet {
+		log.Printf(format, args...)
+	}
+}
+
+// progressBar returns a progress bar for an operation, or nil if progress is
+// not shown
+func (in *inputFlags) progressBar(label string) *progressBar {
+	if !in.progress || in.quiet {
+		return nil
+	}
+	return newProgressBar(label)
+}
+
+// timeRange returns the start and the length of the analyzed range, a length
+// of 0 reads to the end
+func (in *inputFlags) timeRange() (start, length time.Duration, err error) {
+	start, length = in.from.d, in.duration.d
+	if in.to.set {
+		if in.duration.set {
+			return 0, 0, fmt.Errorf("-to and -duration cannot be combined")
+		}
+		if in.to.d <= start {
+			return 0, 0, fmt.Errorf("-to %s is not after -from %s", formatTime(in.to.d), formatTime(start))
+		}
+		length = in.to.d - start
+	}
+	return start, length, nil
+}
+
+// start returns the position of the first analyzed sample in the file, after
+// the trimmed silence
+func (in *inputFlags) start() time.Duration {
+	return in.from.d + in.trimmed
+}
+
+// offset returns the position of the analyzed range in samples at sampleRate
+func (in *inputFlags) offset(sampleRate int) int {
+	return int(math.Round(in.start().Seconds() * float64(sampleRate)))
+}
+
+// load reads the selected channel and range of the input file, resamples it
+// and removes hum as requested
+func (in *inputFlags) load() (samples []float64, sampleRate int, err error) {
+	if in.path == "" {
+		return nil, 0, fmt.Errorf("missing input file")
+	}
+	return in.loadFile(in.path)
+}
+
+// loadFile is load for the file at path
+func (in *inputFlags) loadFile(path string) (samples []float64, sampleRate int, err error) {
+	channel, err := audio.ParseChannel(in.channel)
+	if err != nil {
+		return nil, 0, err
+	}
+	channels, sampleRate, err := in.decode(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if samples, err = audio.SelectChannel(channels, channel); err != nil {
+		return nil, 0, err
+	}
+
+	if in.rate > 0 && in.rate != sampleRate {
+		samples, err = resample.Resample(samples, sampleRate, in.rate)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to resample audio: %w", err)
+		}
+		in.logf("resampled from %d Hz to %d Hz", sampleRate, in.rate)
+		sampleRate = in.rate
+	}
+
+	if in.dehum {
+		var mainsHz float64
+		samples, mainsHz, err = filter.RemoveHum(samples, sampleRate, 10, 30)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to remove hum: %w", err)
+		}
+		if mainsHz > 0 {
+			in.logf("removed %.0f Hz mains hum", mainsHz)
+		} else {
+			in.logf("no mains hum detected")
+		}
+	}
+
+	if in.preEmphasis != 0 {
+		if samples, err = filter.PreEmphasis(samples, in.preEmphasis); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := in.normalizeLevel([][]float64{samples}, sampleRate); err != nil {
+		return nil, 0, err
+	}
+	return samples, sampleRate, nil
+}
+
+// loadChannels reads all channels of the analyzed range of the input file
+// and resamples them as requested, e.g. for measurements that weight the
+// channels such as loudness
+func (in *inputFlags) loadChannels() (channels [][]float64, sampleRate int, err error) {
+	if in.path == "" {
+		return nil, 0, fmt.Errorf("missing input file")
+	}
+	channels, sampleRate, err = in.decode(in.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if in.rate > 0 && in.rate != sampleRate {
+		for i, c := range channels {
+			if channels[i], err = resample.Resample(c, sampleRate, in.rate); err != nil {
+				return nil, 0, fmt.Errorf("failed to resample audio: %w", err)
+			}
+		}
+		in.logf("resampled from %d Hz to %d Hz", sampleRate, in.rate)
+		sampleRate = in.rate
+	}
+	if err := in.normalizeLevel(channels, sampleRate); err != nil {
+		return nil, 0, err
+	}
+	return channels, sampleRate, nil
+}
+
+// normalizeLevel applies -normalize to the channels in place and records the
+// gain
+func (in *inputFlags) normalizeLevel(channels [][]float64, sampleRate int) error {
+	n, err := loudness.ParseNormalization(in.normalize)
+	if err != nil {
+		return err
+	}
+	if n.Mode == loudness.NoNormalization {
+		return nil
+	}
+	if in.gainDB, err = loudness.Normalize(channels, sampleRate, n); err != nil {
+		return err
+	}
+	in.logf("normalized to %s with a gain of %+.1f dB", n, in.gainDB)
+	return nil
+}
+
+// decode reads the channels of the analyzed range of the file at path
+func (in *inputFlags) decode(path string) (channels [][]float64, sampleRate int, err error) {
+	// Only decode the analyzed part of the file
+	start, length, err := in.timeRange()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var progress dft.ProgressFunc
+	bar := in.progressBar("decoding")
+	if bar != nil {
+		progress = bar.update
+	}
+	if in.raw != "" {
+		var format pcm.Format
+		if format, err = pcm.ParseFormat(in.raw, in.rawRate, in.rawChannels); err != nil {
+			return nil, 0, err
+		}
+		channels, sampleRate, err = loadRaw(path, format, start, length, progress)
+	} else {
+		channels, sampleRate, err = loadAudio(path, start, length, progress)
+	}
+	if bar != nil {
+		bar.finish()
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load audio file: %w", err)
+	}
+	n := 0
+	if len(channels) > 0 {
+		n = len(channels[0])
+	}
+	end := start + samplesTime(n, sampleRate)
+
+	// sanity check, before the range is logged as analyzed
+	if n == 0 {
+		return nil, 0, fmt.Errorf("no audio after %s, the input is shorter", formatTime(start))
+	}
+	if length > 0 && n < int(length.Seconds()*float64(sampleRate)) {
+		return nil, 0, fmt.Errorf("the input ends at %s, before the end of the range %s to %s", formatTime(end), formatTime(start), formatTime(start+length))
+	}
+	in.logf("analyzed range: %s to %s (%v)", formatTime(start), formatTime(end), end-start)
+	in.logf("sampleRate: %d", sampleRate)
+	if in.trim {
+		opts := silence.DefaultOptions
+		opts.ThresholdDB = in.trimDB
+		first, last, err := silence.Trim(channels, sampleRate, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := range channels {
+			channels[i] = channels[i][first:last]
+		}
+		in.trimmed = samplesTime(first, sampleRate)
+		in.logf("trimmed the silence, the material spans %s to %s", formatTime(start+in.trimmed), formatTime(start+samplesTime(last, sampleRate)))
+	}
+	if in.health {
+		report, err := in.checkHealth(channels, sampleRate)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, p := range report.Problems() {
+			in.logf("warning: %s", p)
+		}
+	}
+	return channels, sampleRate, nil
+}
+
+// checkHealth checks the decoded channels, the times of the report refer to
+// the start of the file
+func (in *inputFlags) checkHealth(channels [][]float64, sampleRate int) (health.Report, error) {
+	report, err := health.Check(channels, sampleRate, health.DefaultOptions)
+	if err != nil {
+		return health.Report{}, err
+	}
+	report.Shift(in.start().Seconds())
+	return report, nil
+}
+
+// loadAudio returns the channels of an audio file from start to start+length
+// (a length <= 0 reads to the end) and its sample rate. progress may be nil.
+func loadAudio(path string, start, length time.Duration, progress dft.ProgressFunc) ([][]float64, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	// The content decides the format, the extension is only a fallback
+	return audio.LoadChannelsProgress(f, audio.FormatFromExtension(path), start, length, progress)
+}
+
+// loadRaw is like loadAudio for header-less PCM data in the given format
+func loadRaw(path string, f pcm.Format, start, length time.Duration, progress dft.ProgressFunc) ([][]float64, int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	streamer, format, err := pcm.Decode(file, f)
+	if err != nil {
+		return nil, 0, err
+	}
+	return audio.ReadChannelsRangeProgress(streamer, format, start, length, progress)
+}
+
+// writeFile creates path and passes it to write
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeWAV writes a mono signal to a WAV file
+func writeWAV(path string, samples []float64, sampleRate int, opts audio.WAVOptions) error {
+	err := writeFile(path, func(w io.Writer) error {
+		return audio.WriteWAV(w, [][]float64{samples}, sampleRate, opts)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write WAV file: %w", err)
+	}
+	return nil
+}
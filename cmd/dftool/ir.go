@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/audio"
+	"github.com/epikur-io/go-discrete-fourier-transform/measure"
+	"github.com/epikur-io/go-discrete-fourier-transform/siggen"
+)
+
+// runIR deconvolves the recording of a sweep written by "generate -type sweep"
+// and writes the impulse response to a WAV file
+func runIR(args []string) error {
+	fs := newFlagSet("ir")
+	var in inputFlags
+	in.register(fs, 0)
+	freqList := fs.String("sweep", "20,20000", "start and end frequency of the sweep in Hz")
+	sweepDuration := fs.Float64("sweep-duration", siggen.DefaultSweep.Duration, "duration of the sweep in seconds")
+	amplitude := fs.Float64("amp", siggen.DefaultSweep.Amplitude, "amplitude the sweep was generated with, a system with unity gain then has an impulse of height 1")
+	length := fs.Float64("length", 1, "length of the written impulse response in seconds")
+	harmonics := fs.Int("harmonics", 0, "also write the impulse responses of the harmonic distortion products 2 to N to files with suffix _h2, _h3, ...")
+	bitDepth := fs.Int("bits", 24, "bits per sample of the output: 8, 16, 24 or 32")
+	outputFile := fs.String("out", "", "path of the written WAV file")
+	parseFlags(fs, args)
+
+	if *outputFile == "" {
+		return fmt.Errorf("missing output file")
+	}
+	freqs, err := parseFloats(*freqList)
+	if err != nil || len(freqs) != 2 {
+		return fmt.Errorf("invalid sweep range %q, expected start and end frequency", *freqList)
+	}
+	if *length <= 0 {
+		return fmt.Errorf("invalid length %g", *length)
+	}
+
+	recorded, sampleRate, err := in.load()
+	if err != nil {
+		return err
+	}
+	sweep := siggen.Sweep{
+		StartHz:    freqs[0],
+		EndHz:      freqs[1],
+		Duration:   *sweepDuration,
+		Amplitude:  *amplitude,
+		SampleRate: sampleRate,
+		Fade:       siggen.DefaultSweep.Fade,
+	}
+	response, err := measure.DeconvolveSweep(recorded, sweep)
+	if err != nil {
+		return err
+	}
+
+	n := int(*length * float64(sampleRate))
+	ir := response.Linear[:min(n, len(response.Linear))]
+	if len(ir) < n {
+		in.logf("the recording ends %.3f s after the sweep, the impulse response is cut short", float64(len(ir))/float64(sampleRate))
+	}
+	peak, at := 0.0, 0
+	for i, v := range ir {
+		if math.Abs(v) > peak {
+			peak, at = math.Abs(v), i
+		}
+	}
+	in.logf("impulse peak %.4g (%.1f dB) after %.2f ms", peak, 20*math.Log10(peak), float64(at)*1000/float64(sampleRate))
+	if err := writeIR(*outputFile, ir, sampleRate, *bitDepth); err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(*outputFile)
+	for h := 2; h <= *harmonics; h++ {
+		hir, err := response.Harmonic(h, n)
+		if err != nil {
+			return err
+		}
+		path := fmt.Sprintf("%s_h%d%s", strings.TrimSuffix(*outputFile, ext), h, ext)
+		if err := writeIR(path, hir, sampleRate, *bitDepth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeIR writes an impulse response and warns if it clips
+func writeIR(path string, ir []float64, sampleRate, bitDepth int) error {
+	if peak := maxAbs(ir); peak > 1 {
+		log.Printf("%s: the peak of %.2f exceeds full scale and is clipped, a larger -amp scales it down", path, peak)
+	}
+	return writeWAV(path, ir, sampleRate, audio.WAVOptions{BitDepth: bitDepth})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/loudness"
+)
+
+// runLoudness measures the EBU R128 loudness of all channels of a file and
+// checks it against the delivery targets
+func runLoudness(args []string) error {
+	fs := newFlagSet("loudness")
+	var in inputFlags
+	in.register(fs, 0)
+	target := fs.Float64("target", loudness.TargetLUFS, "target integrated loudness in LUFS")
+	tolerance := fs.Float64("tolerance", loudness.TargetTolerance, "permitted deviation from the target in LU")
+	maxPeak := fs.Float64("max-true-peak", loudness.MaxTruePeakDBTP, "highest permitted true peak in dBTP")
+	jsonOutput := fs.Bool("json", false, "print the result as JSON")
+	parseFlags(fs, args)
+
+	channels, sampleRate, err := in.loadChannels()
+	if err != nil {
+		return err
+	}
+	r, err := loudness.Measure(channels, sampleRate, nil)
+	if err != nil {
+		return err
+	}
+	loudnessOK := math.Abs(r.Integrated-*target) <= *tolerance
+	peakOK := r.TruePeak <= *maxPeak
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(struct {
+			loudness.Result
+			Compliant bool `json:"compliant"`
+		}{r, loudnessOK && peakOK}); err != nil {
+			return err
+		}
+	} else {
+		check := func(ok bool) string {
+			if ok {
+				return "ok"
+			}
+			return "FAIL"
+		}
+		fmt.Printf("integrated:      %6.1f LUFS  %s (target %.1f ±%.1f)\n", r.Integrated, check(loudnessOK), *target, *tolerance)
+		fmt.Printf("loudness range:  %6.1f LU\n", r.Range)
+		fmt.Printf("momentary max.:  %6.1f LUFS\n", r.MomentaryMax)
+		fmt.Printf("short-term max.: %6.1f LUFS\n", r.ShortTermMax)
+		fmt.Printf("true peak:       %6.1f dBTP  %s (max. %.1f)\n", r.TruePeak, check(peakOK), *maxPeak)
+		fmt.Printf("sample peak:     %6.1f dBFS\n", r.SamplePeak)
+	}
+	if !loudnessOK || !peakOK {
+		return fmt.Errorf("the programme does not meet the loudness targets")
+	}
+	return nil
+}
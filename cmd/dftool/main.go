@@ -0,0 +1,106 @@
+// Command dftool analyzes, generates and filters signals with the library.
+//
+// Usage:
+//
+//	dftool <command> [flags]
+//
+// The commands are:
+//
+//	analyze      spectrum and peaks of an audio file, with JSON, CSV, NumPy and PNG output
+//	peaks        print the peaks of an audio file
+//	batch        peaks of many files, directories or glob patterns as JSON lines or CSV
+//	spectrogram  STFT of an audio file as PNG, CSV, NumPy, waterfall or terminal output
+//	generate     write a test signal, noise or sweep to a WAV file
+//	ir           impulse response from the recording of a sweep
+//	room         reverberation time and clarity of an impulse response
+//	filter       filter an audio file and write the result to a WAV file
+//	compare      frequency response of a test recording relative to a reference
+//	null         subtract two aligned takes and analyze the residual
+//	dupes        find audio files that contain the same recording
+//	distance     spectral distance of two recordings, aligned by DTW
+//	vad          find the speech regions of a recording
+//	formants     formant frequencies of speech per frame
+//	loudness     EBU R128 loudness and true peak of all channels
+//	truepeak     sample and true peak levels of all channels
+//	health       clipping, DC offset and dropouts of all channels
+//	silence      find the silent regions of a recording
+//	verify       cross-check the FFTs against a naive DFT
+//	serve        web interface and gRPC service
+//
+// Run "dftool <command> -h" for the flags of a command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"analyze", "spectrum and peaks of an audio file, with JSON, CSV, NumPy and PNG output", runAnalyze},
+	{"peaks", "print the peaks of an audio file", runPeaks},
+	{"batch", "peaks of many files, directories or glob patterns as JSON lines or CSV", runBatch},
+	{"spectrogram", "STFT of an audio file as PNG, CSV, NumPy, waterfall or terminal output", runSpectrogram},
+	{"generate", "write a test signal, noise or sweep to a WAV file", runGenerate},
+	{"ir", "impulse response from the recording of a sweep", runIR},
+	{"room", "reverberation time and clarity of an impulse response", runRoom},
+	{"filter", "filter an audio file and write the result to a WAV file", runFilter},
+	{"compare", "frequency response of a test recording relative to a reference", runCompare},
+	{"null", "subtract two aligned takes and analyze the residual", runNull},
+	{"dupes", "find audio files that contain the same recording", runDupes},
+	{"distance", "spectral distance of two recordings, aligned by DTW", runDistance},
+	{"vad", "find the speech regions of a recording", runVAD},
+	{"formants", "formant frequencies of speech per frame", runFormants},
+	{"loudness", "EBU R128 loudness and true peak of all channels", runLoudness},
+	{"truepeak", "sample and true peak levels of all channels", runTruePeak},
+	{"health", "clipping, DC offset and dropouts of all channels", runHealth},
+	{"silence", "find the silent regions of a recording", runSilence},
+	{"verify", "cross-check the FFTs against a naive DFT", runVerify},
+	{"serve", "web interface and gRPC service", runServe},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: dftool <command> [flags]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun \"dftool <command> -h\" for the flags of a command.\n")
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("dftool: ")
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if name == "-h" || name == "-help" || name == "--help" || name == "help" {
+		usage()
+		return
+	}
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		if err := c.run(os.Args[2:]); err != nil {
+			log.Fatalf("%s: %v", name, err)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "dftool: unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+// newFlagSet returns the flag set of a command
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("dftool "+name, flag.ExitOnError)
+}
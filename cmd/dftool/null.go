@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/audio"
+	"github.com/epikur-io/go-discrete-fourier-transform/measure"
+)
+
+// runNull subtracts two aligned and level matched takes and analyzes what
+// remains
+func runNull(args []string) error {
+	fs := newFlagSet("null")
+	var in inputFlags
+	in.register(fs, 0)
+	fftSize := fs.Int("fft-size", measure.DefaultCompareOptions.FFTSize, "frame size of the averaged spectra in samples")
+	maxDelay := fs.Float64("max-delay", measure.DefaultCompareOptions.MaxDelay, "largest delay between the files searched for in seconds")
+	bandsPerOctave := fs.Int("bands-per-octave", measure.DefaultCompareOptions.BandsPerOctave, "width of the reported bands, 1 for octaves or 3 for third octaves")
+	minHz := fs.Float64("min-freq", measure.DefaultCompareOptions.MinHz, "lowest band center in Hz")
+	maxHz := fs.Float64("max-freq", 0, "highest band center in Hz (0 up to the Nyquist frequency)")
+	jsonOutput := fs.Bool("json", false, "print the result as JSON instead of a table")
+	csvPath := fs.String("csv", "", "write the residual spectrum relative to the reference (frequency, dB) to this CSV file")
+	out := fs.String("out", "", "write the residual to this WAV file")
+	bits := fs.Int("bits", 24, "bit depth of the residual WAV file (16, 24 or 32)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: dftool null [flags] reference test\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected a reference and a test file")
+	}
+	ref, test, sampleRate, err := loadPair(&in, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	r, err := measure.NullTest(ref, test, sampleRate, measure.CompareOptions{
+		FFTSize:        *fftSize,
+		MaxDelay:       *maxDelay,
+		BandsPerOctave: *bandsPerOctave,
+		MinHz:          *minHz,
+		MaxHz:          *maxHz,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *out != "" {
+		// The residual is written without gain so its level stays comparable
+		// to the reference
+		err := writeWAV(*out, r.Residual, sampleRate, audio.WAVOptions{BitDepth: *bits})
+		if err != nil {
+			return fmt.Errorf("failed to write the residual: %w", err)
+		}
+		in.logf("wrote the residual to %s", *out)
+	}
+	if *csvPath != "" {
+		err := writeFile(*csvPath, func(w io.Writer) error {
+			cw := csv.NewWriter(w)
+			cw.Write([]string{"freq_hz", "residual_db"})
+			for k, f := range r.Freqs {
+				cw.Write([]string{
+					strconv.FormatFloat(f, 'f', -1, 64),
+					strconv.FormatFloat(r.ResidualDB[k], 'g', 6, 64),
+				})
+			}
+			cw.Flush()
+			return cw.Error()
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			DelayS   float64            `json:"delay_s"`
+			GainDB   float64            `json:"gain_db"`
+			Inverted bool               `json:"inverted"`
+			DepthDB  float64            `json:"depth_db"`
+			Bands    []measure.NullBand `json:"bands"`
+		}{r.Delay, r.GainDB, r.Inverted, r.DepthDB, r.Bands})
+	}
+
+	polarity := "normal"
+	if r.Inverted {
+		polarity = "inverted"
+	}
+	fmt.Printf("delay: %.3f ms\ngain: %+.2f dB\npolarity: %s\nnull depth: %.1f dB\n\n", r.Delay*1000, r.GainDB, polarity, r.DepthDB)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "band Hz\tresidual dB\t")
+	for _, b := range r.Bands {
+		fmt.Fprintf(w, "%.0f\t%.1f\t\n", b.CenterHz, b.DepthDB)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// progressBar draws the progress of an operation with percentage and ETA on
+// stderr. It redraws at most ten times per second and only appears for
+// operations that take longer than that.
+type progressBar struct {
+	label string
+	last  time.Time
+	drawn bool
+}
+
+func newProgressBar(label string) *progressBar {
+	return &progressBar{label: label, last: time.Now()}
+}
+
+// update is a dft.ProgressFunc
+func (b *progressBar) update(p dft.Progress) {
+	complete := p.Total > 0 && p.Done >= p.Total
+	if time.Since(b.last) < 100*time.Millisecond && !(complete && b.drawn) {
+		return
+	}
+	b.last = time.Now()
+	b.drawn = true
+
+	if p.Total <= 0 {
+		fmt.Fprintf(os.Stderr, "\r%s: %d done, %s elapsed\033[K", b.label, p.Done, formatTime(p.Elapsed))
+		return
+	}
+	const width = 30
+	filled := int(p.Fraction() * width)
+	eta := "ETA " + formatTime(p.ETA())
+	if complete {
+		eta = "took " + formatTime(p.Elapsed)
+	}
+	fmt.Fprintf(os.Stderr, "\r%s [%s%s] %3.0f%% %s\033[K", b.label,
+		strings.Repeat("#", filled), strings.Repeat("-", width-filled), p.Fraction()*100, eta)
+}
+
+// finish ends the line of the bar, if it was drawn
+func (b *progressBar) finish() {
+	if b.drawn {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// isTerminal reports whether f is a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/measure"
+)
+
+// roomRecord is the JSON form of measure.RoomAcoustics
+type roomRecord struct {
+	Band    string  `json:"band"`
+	EDT     float64 `json:"edt_s"`
+	T20     float64 `json:"t20_s"`
+	T30     float64 `json:"t30_s"`
+	RT60    float64 `json:"rt60_s"`
+	C50     float64 `json:"c50_db"`
+	C80     float64 `json:"c80_db"`
+	NoiseDB float64 `json:"noise_db"`
+}
+
+// runRoom prints the reverberation times and clarity indices of an impulse
+// response per octave band
+func runRoom(args []string) error {
+	fs := newFlagSet("room")
+	var in inputFlags
+	in.register(fs, 0)
+	bandList := fs.String("bands", "63,125,250,500,1000,2000,4000,8000", "comma separated center frequencies of the octave bands in Hz")
+	jsonOutput := fs.Bool("json", false, "print the results as JSON array instead of a table")
+	parseFlags(fs, args)
+
+	bands, err := parseFloats(*bandList)
+	if err != nil {
+		return fmt.Errorf("invalid bands: %w", err)
+	}
+	ir, sampleRate, err := in.load()
+	if err != nil {
+		return err
+	}
+	results, err := measure.AnalyzeRoom(ir, sampleRate, measure.RoomOptions{Bands: bands})
+	if err != nil {
+		return err
+	}
+
+	records := make([]roomRecord, len(results))
+	for i, r := range results {
+		band := "broadband"
+		if r.CenterHz > 0 {
+			band = fmt.Sprintf("%g Hz", r.CenterHz)
+		}
+		records[i] = roomRecord{band, r.EDT, r.T20, r.T30, r.RT60, r.C50, r.C80, r.NoiseDB}
+	}
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+
+	// A decay time of 0 means the range was not covered above the noise
+	seconds := func(v float64) string {
+		if v == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.3f", v)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "band\tEDT s\tT20 s\tT30 s\tC50 dB\tC80 dB\tnoise dB\t")
+	for _, r := range records {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.1f\t%.1f\t%.1f\t\n", r.Band, seconds(r.EDT), seconds(r.T20), seconds(r.T30), r.C50, r.C80, r.NoiseDB)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/rpc"
+	"github.com/epikur-io/go-discrete-fourier-transform/serve"
+)
+
+// runServe serves the interactive web page and optionally the gRPC service
+func runServe(args []string) error {
+	fs := newFlagSet("serve")
+	var in inputFlags
+	in.register(fs, 0)
+	addr := fs.String("addr", "localhost:8080", "listen address")
+	grpcAddr := fs.String("grpc", "", "also serve the gRPC analysis service on this address, e.g. localhost:9090")
+	parseFlags(fs, args)
+
+	server := serve.New(serve.DefaultOptions)
+	if in.path != "" {
+		samples, sampleRate, err := in.load()
+		if err != nil {
+			return err
+		}
+		if err := server.Load(filepath.Base(in.path), samples, sampleRate); err != nil {
+			return err
+		}
+	}
+
+	if *grpcAddr != "" {
+		go func() {
+			log.Printf("serving gRPC on %s", *grpcAddr)
+			log.Fatalln(rpc.New(rpc.DefaultOptions).ListenAndServe(*grpcAddr))
+		}()
+	}
+
+	log.Printf("serving on http://%s", *addr)
+	return http.ListenAndServe(*addr, server)
+}
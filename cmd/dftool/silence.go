@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/silence"
+)
+
+// runSilence prints the silent regions of a recording
+func runSilence(args []string) error {
+	fs := newFlagSet("silence")
+	var in inputFlags
+	in.register(fs, 0)
+	threshold := fs.Float64("threshold", silence.DefaultOptions.ThresholdDB, "level of silence in dBFS")
+	minDuration := fs.Float64("min-duration", silence.DefaultOptions.MinDuration, "shortest pause inside the material in seconds")
+	all := fs.Bool("all", false, "also list the regions between the pauses")
+	jsonOutput := fs.Bool("json", false, "print the regions as JSON array instead of a table")
+	parseFlags(fs, args)
+
+	channels, sampleRate, err := in.loadChannels()
+	if err != nil {
+		return err
+	}
+	opts := silence.DefaultOptions
+	opts.ThresholdDB = *threshold
+	opts.MinDuration = *minDuration
+	regions, err := silence.Detect(channels, sampleRate, opts)
+	if err != nil {
+		return err
+	}
+
+	quiet := 0.0
+	listed := []silence.Region{}
+	for _, r := range regions {
+		// Times refer to the start of the file, not of the analyzed range
+		r.Start += in.start().Seconds()
+		r.End += in.start().Seconds()
+		if r.Silent {
+			quiet += r.Duration()
+		}
+		if r.Silent || *all {
+			listed = append(listed, r)
+		}
+	}
+	in.logf("silence: %.1f s of %.1f s", quiet, float64(len(channels[0]))/float64(sampleRate))
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(listed)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "start\tend\tduration\tregion")
+	for _, r := range listed {
+		region := "material"
+		if r.Silent {
+			region = "silence"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2f s\t%s\n", formatTime(seconds(r.Start)), formatTime(seconds(r.End)), r.Duration(), region)
+	}
+	return w.Flush()
+}
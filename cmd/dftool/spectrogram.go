@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/export"
+	"github.com/epikur-io/go-discrete-fourier-transform/plot"
+)
+
+func runSpectrogram(args []string) error {
+	fs := newFlagSet("spectrogram")
+	var in inputFlags
+	var sf spectrumFlags
+	in.register(fs, 0)
+	sf.register(fs)
+	frameSize := fs.Int("frame", 2048, "samples per frame, sets the time resolution")
+	hopSize := fs.Int("hop", 512, "samples between the starts of two frames, at most -frame")
+	pngPath := fs.String("png", "", "render the spectrogram to this PNG file")
+	csvPath := fs.String("csv", "", "write the spectrogram (frames by bins) to this CSV file, tab separated if it ends in .tsv")
+	npzPath := fs.String("npz", "", "write the spectrogram (frames by bins) to this NumPy .npz file")
+	waterfallPath := fs.String("waterfall", "", "render an animated waterfall to this GIF file, or to a sequence of PNG files for a pattern like frames/%04d.png")
+	tui := fs.Bool("tui", false, "print the spectrogram in the terminal as scrolling waterfall")
+	colorMapName := fs.String("cmap", "viridis", "color map of the spectrogram: viridis, magma, inferno or gray")
+	freqScaleName := fs.String("freq-scale", "log", "frequency axis of the spectrogram: linear, log or mel")
+	minDB := fs.Float64("min-db", -120, "level in dB at the bottom of the color map of the spectrogram")
+	parseFlags(fs, args)
+
+	if *pngPath == "" && *csvPath == "" && *npzPath == "" && *waterfallPath == "" && !*tui {
+		return fmt.Errorf("no output, use -png, -csv, -npz, -waterfall or -tui")
+	}
+	if *frameSize <= 0 || *hopSize <= 0 {
+		return fmt.Errorf("invalid frame size %d or hop size %d", *frameSize, *hopSize)
+	}
+	if *hopSize > *frameSize {
+		return fmt.Errorf("hop size %d is larger than the frame size %d, samples between the frames would be skipped", *hopSize, *frameSize)
+	}
+	if sf.fftSize != 0 && sf.fftSize < *frameSize {
+		return fmt.Errorf("FFT size %d is smaller than the frame size %d", sf.fftSize, *frameSize)
+	}
+	spectrumOpts, err := sf.options()
+	if err != nil {
+		return err
+	}
+	plotOpts := plot.DefaultSpectrogramOptions
+	plotOpts.MinDB = *minDB
+	plotOpts.Title = filepath.Base(in.path)
+	if plotOpts.ColorMap, err = plot.ParseColorMap(*colorMapName); err != nil {
+		return err
+	}
+	if plotOpts.FreqScale, err = plot.ParseScale(*freqScaleName); err != nil {
+		return err
+	}
+
+	wave, sampleRate, err := in.load()
+	if err != nil {
+		return err
+	}
+	bar := in.progressBar("transforming")
+	if bar != nil {
+		spectrumOpts.Progress = bar.update
+	}
+	// The frames scale like Hanning windowed frames of the FFT size
+	result, err := dft.AnalyzeFrames(wave, sampleRate, in.offset(sampleRate), *frameSize, *hopSize, spectrumOpts)
+	if bar != nil {
+		bar.finish()
+	}
+	if err != nil {
+		return err
+	}
+	frames, fftSize := result.Frames, result.FFTSize
+	if len(frames) == 0 {
+		return fmt.Errorf("the signal of %d samples is shorter than a frame of %d samples", len(wave), *frameSize)
+	}
+	logResolution(&in, spectrumOpts.Window, sampleRate, *frameSize, fftSize)
+	in.logf("time resolution: %.4g ms frames every %.4g ms (%.0f%% overlap)",
+		float64(*frameSize)*1000/float64(sampleRate), float64(*hopSize)*1000/float64(sampleRate),
+		result.Overlap()*100)
+
+	if *csvPath != "" {
+		err := writeFile(*csvPath, func(w io.Writer) error {
+			return export.WriteSpectrogramCSV(w, frames, sampleRate, fftSize, csvOptions(*csvPath, false))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	if *npzPath != "" {
+		err := writeFile(*npzPath, func(w io.Writer) error {
+			return export.WriteSpectrogramNPZ(w, frames, sampleRate, fftSize)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write NPZ: %w", err)
+		}
+	}
+	if *pngPath != "" {
+		img, err := plot.Spectrogram(frames, sampleRate, fftSize, plotOpts)
+		if err != nil {
+			return fmt.Errorf("failed to plot spectrogram: %w", err)
+		}
+		err = writeFile(*pngPath, func(w io.Writer) error {
+			return plot.WritePNG(w, img)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write spectrogram: %w", err)
+		}
+	}
+	if *tui {
+		opts := plot.DefaultTerminalOptions
+		opts.Waterfall = true
+		term, err := plot.NewTerminal(os.Stdout, sampleRate, fftSize, opts)
+		if err != nil {
+			return err
+		}
+		for _, f := range frames {
+			if err := term.Draw(dft.NewSpectrum(f.Spectrum, sampleRate, fftSize, fftSize).Magnitude); err != nil {
+				return err
+			}
+		}
+	}
+	if *waterfallPath != "" {
+		if err := writeWaterfall(*waterfallPath, frames, sampleRate, fftSize, *hopSize); err != nil {
+			return fmt.Errorf("failed to write waterfall: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeWaterfall renders up to 200 images of a scrolling waterfall of frames
+// and writes them as animated GIF or, if path contains a % verb, as numbered
+// PNG files
+func writeWaterfall(path string, frames []dft.Frame, sampleRate, frameSize, hopSize int) error {
+	w, err := plot.NewWaterfall(sampleRate, frameSize, plot.DefaultWaterfallOptions)
+	if err != nil {
+		return err
+	}
+	step := max(1, len(frames)/200)
+	var images []*image.Paletted
+	for i, f := range frames {
+		w.Push(dft.NewSpectrum(f.Spectrum, sampleRate, frameSize, frameSize).Magnitude)
+		if (i+1)%step == 0 {
+			images = append(images, w.Image())
+		}
+	}
+
+	if strings.Contains(path, "%") {
+		for i, img := range images {
+			err := writeFile(fmt.Sprintf(path, i), func(w io.Writer) error {
+				return plot.WritePNG(w, img)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// GIF delays are in hundredths of a second
+	delay := max(2, step*hopSize*100/sampleRate)
+	return writeFile(path, func(out io.Writer) error {
+		return plot.WriteGIF(out, images, delay)
+	})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeValue is a flag holding a position or length in the audio, given as
+// [[hh:]mm:]ss[.ms] like 1:23.5, as Go duration like 10s or 1m30s, or as
+// plain seconds
+type timeValue struct {
+	d   time.Duration
+	set bool // given on the command line or in a config file
+}
+
+func (t *timeValue) String() string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(t.d)
+}
+
+func (t *timeValue) Set(s string) error {
+	d, err := parseTime(s)
+	if err != nil {
+		return err
+	}
+	t.d, t.set = d, true
+	return nil
+}
+
+func (t *timeValue) Get() any {
+	return t.d.Seconds()
+}
+
+// parseTime parses the formats of timeValue
+func parseTime(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty time")
+	}
+	if strings.HasPrefix(s, "-") {
+		return 0, fmt.Errorf("negative time %q", s)
+	}
+	if !strings.Contains(s, ":") {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d, nil
+		}
+		sec, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsInf(sec, 0) || math.IsNaN(sec) || sec < 0 {
+			return 0, fmt.Errorf("invalid time %q, expected e.g. 1:23.5, 10s or 83.5", s)
+		}
+		return seconds(sec), nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time %q, expected [[hh:]mm:]ss[.ms]", s)
+	}
+	field := parts[len(parts)-1]
+	sec, err := strconv.ParseFloat(field, 64)
+	// No field may have a sign, NaN fails the range check
+	if err != nil || strings.HasPrefix(field, "-") || strings.HasPrefix(field, "+") || !(sec >= 0 && sec < 60) {
+		return 0, fmt.Errorf("invalid seconds in time %q", s)
+	}
+	total := sec
+	for i, unit := range []float64{60, 3600}[:len(parts)-1] {
+		v, err := strconv.ParseUint(parts[len(parts)-2-i], 10, 32)
+		// The minutes of hh:mm:ss are below 60, leading fields are unbounded
+		if err != nil || (i == 0 && len(parts) == 3 && v >= 60) {
+			return 0, fmt.Errorf("invalid time %q, expected [[hh:]mm:]ss[.ms]", s)
+		}
+		total += float64(v) * unit
+	}
+	return seconds(total), nil
+}
+
+// seconds converts seconds to a Duration, rounded to the nanosecond
+func seconds(sec float64) time.Duration {
+	return time.Duration(math.Round(sec * float64(time.Second)))
+}
+
+// formatTime formats d as [h:]mm:ss.mmm
+func formatTime(d time.Duration) string {
+	ms := d.Round(time.Millisecond).Milliseconds()
+	h, m, s := ms/3600000, ms/60000%60, float64(ms%60000)/1000
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%06.3f", h, m, s)
+	}
+	return fmt.Sprintf("%d:%06.3f", m, s)
+}
+
+// samplesTime returns the duration of n samples
+func samplesTime(n, sampleRate int) time.Duration {
+	return time.Duration(n) * time.Second / time.Duration(sampleRate)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"1:23.5":   83500 * time.Millisecond,
+		"1:02:03":  time.Hour + 2*time.Minute + 3*time.Second,
+		"90:00":    90 * time.Minute,
+		"10s":      10 * time.Second,
+		"1m30s":    90 * time.Second,
+		"83.5":     83500 * time.Millisecond,
+		"0:0.25":   250 * time.Millisecond,
+		" 0:05 ":   5 * time.Second,
+		"0":        0,
+		"00:00:00": 0,
+	} {
+		got, err := parseTime(s)
+		if err != nil || got != want {
+			t.Errorf("parseTime(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{
+		"", "-1", "-1s", "1:-5", "0:-5", "0:-0", "1:+5", "-1:05", "1:-2:05",
+		"NaN", "nan", "0:NaN", "inf", "0:inf", "1:60", "1:60:00", "1:2:3:4", "abc",
+	} {
+		if got, err := parseTime(s); err == nil {
+			t.Errorf("parseTime(%q) = %v, want an error", s, got)
+		}
+	}
+}
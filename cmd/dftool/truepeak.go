@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/loudness"
+)
+
+// runTruePeak prints the sample and true peak levels of all channels of a
+// file and checks them against the highest permitted true peak
+func runTruePeak(args []string) error {
+	fs := newFlagSet("truepeak")
+	var in inputFlags
+	in.register(fs, 0)
+	maxPeak := fs.Float64("max-true-peak", loudness.MaxTruePeakDBTP, "highest permitted true peak in dBTP")
+	jsonOutput := fs.Bool("json", false, "print the levels as JSON array instead of a table")
+	parseFlags(fs, args)
+
+	channels, _, err := in.loadChannels()
+	if err != nil {
+		return err
+	}
+	peaks := loudness.Peaks(channels)
+	ok := true
+	for _, p := range peaks {
+		ok = ok && p.TruePeak <= *maxPeak
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(peaks); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "channel\tsample peak\ttrue peak\tinter-sample overs\tcheck")
+		for c, p := range peaks {
+			status := "ok"
+			if p.TruePeak > *maxPeak {
+				status = "FAIL"
+			}
+			fmt.Fprintf(w, "%d\t%.2f dBFS\t%.2f dBTP\t%d\t%s\n", c, p.SamplePeak, p.TruePeak, p.InterSampleOvers, status)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if !ok {
+		return fmt.Errorf("the true peak exceeds %.1f dBTP", *maxPeak)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/audio"
+	"github.com/epikur-io/go-discrete-fourier-transform/vad"
+)
+
+// runVAD prints the speech and non-speech regions of a recording
+func runVAD(args []string) error {
+	fs := newFlagSet("vad")
+	var in inputFlags
+	in.register(fs, 0)
+	energyDB := fs.Float64("energy", vad.DefaultOptions.EnergyDB, "least level of speech above the noise floor in dB")
+	minSpeech := fs.Float64("min-speech", vad.DefaultOptions.MinSpeech, "shortest speech region in seconds")
+	minSilence := fs.Float64("min-silence", vad.DefaultOptions.MinSilence, "shortest pause between speech regions in seconds")
+	padding := fs.Float64("padding", vad.DefaultOptions.Padding, "extend speech regions by this many seconds on both sides")
+	all := fs.Bool("all", false, "also list the non-speech regions")
+	jsonOutput := fs.Bool("json", false, "print the regions as JSON array instead of a table")
+	out := fs.String("out", "", "write the speech regions, joined, to this WAV file")
+	parseFlags(fs, args)
+
+	samples, sampleRate, err := in.load()
+	if err != nil {
+		return err
+	}
+	opts := vad.DefaultOptions
+	opts.EnergyDB = *energyDB
+	opts.MinSpeech = *minSpeech
+	opts.MinSilence = *minSilence
+	opts.Padding = *padding
+	segments, err := vad.Detect(samples, sampleRate, opts)
+	if err != nil {
+		return err
+	}
+
+	speech := 0.0
+	listed := []vad.Segment{}
+	for _, s := range segments {
+		// Times refer to the start of the file, not of the analyzed range
+		s.Start += in.start().Seconds()
+		s.End += in.start().Seconds()
+		if s.Speech {
+			speech += s.Duration()
+		}
+		if s.Speech || *all {
+			listed = append(listed, s)
+		}
+	}
+	in.logf("speech: %.1f s of %.1f s", speech, float64(len(samples))/float64(sampleRate))
+
+	if *out != "" {
+		var joined []float64
+		for _, part := range vad.Speech(samples, sampleRate, segments) {
+			joined = append(joined, part...)
+		}
+		if err := writeWAV(*out, joined, sampleRate, audio.DefaultWAVOptions); err != nil {
+			return fmt.Errorf("failed to write the speech: %w", err)
+		}
+	}
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(listed)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "start\tend\tduration\tregion")
+	for _, s := range listed {
+		region := "silence"
+		if s.Speech {
+			region = "speech"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2f s\t%s\n", formatTime(seconds(s.Start)), formatTime(seconds(s.End)), s.Duration(), region)
+	}
+	return w.Flush()
+}
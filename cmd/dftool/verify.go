@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/bigdft"
+	"github.com/epikur-io/go-discrete-fourier-transform/fixed"
+)
+
+// Tolerated errors of the fixed-point FFTs relative to the largest
+// coefficient, the rounding of Q15 and Q31 values accumulates over the stages
+const (
+	fixed16MaxErr = 2e-2
+	fixed32MaxErr = 1e-6
+)
+
+// verifyCheck is a transform check of the verify command, it returns the
+// largest relative error
+type verifyCheck struct {
+	name  string
+	check func() (float64, error)
+}
+
+// runVerify cross-checks the FFTs of the package against the naive DFT on
+// random signals
+func runVerify(args []string) error {
+	fs := newFlagSet("verify")
+	sizes := fs.String("sizes", joinInts(dft.DefaultVerifyOptions.Sizes), "comma separated transform lengths")
+	trials := fs.Int("trials", dft.DefaultVerifyOptions.Trials, "random signals per length")
+	seed := fs.Int64("seed", dft.DefaultVerifyOptions.Seed, "seed of the random signals")
+	maxErr := fs.Float64("max-err", dft.DefaultVerifyOptions.MaxErr, "highest tolerated error relative to the largest coefficient or sample")
+	bits := fs.Uint("bits", 0, "also check Forward against an arbitrary precision DFT with this many bits, e.g. 128, which bounds its true error (0 skips the slow check)")
+	parseFlags(fs, args)
+
+	opts := dft.VerifyOptions{Trials: *trials, Seed: *seed, MaxErr: *maxErr}
+	values, err := parseFloats(*sizes)
+	if err != nil {
+		return fmt.Errorf("invalid sizes: %w", err)
+	}
+	for _, v := range values {
+		if v < 1 || v != float64(int(v)) {
+			return fmt.Errorf("invalid size %g", v)
+		}
+		opts.Sizes = append(opts.Sizes, int(v))
+	}
+
+	checks := []verifyCheck{
+		{"Forward", func() (float64, error) { return dft.VerifyForward(dft.Forward, opts) }},
+		{"Inverse", func() (float64, error) { return dft.VerifyInverse(dft.Inverse, opts) }},
+		{"ForwardComplex", func() (float64, error) { return dft.VerifyForwardComplex(dft.ForwardComplex, opts) }},
+	}
+	// The fixed-point FFTs only transform powers of two, at their precision
+	fixedOpts := opts
+	fixedOpts.Sizes = nil
+	for _, n := range opts.Sizes {
+		if n&(n-1) == 0 {
+			fixedOpts.Sizes = append(fixedOpts.Sizes, n)
+		}
+	}
+	opts16, opts32 := fixedOpts, fixedOpts
+	opts16.MaxErr = math.Max(opts.MaxErr, fixed16MaxErr)
+	opts32.MaxErr = math.Max(opts.MaxErr, fixed32MaxErr)
+	checks = append(checks,
+		verifyCheck{"fixed.Transform[int16]", func() (float64, error) { return dft.VerifyForward(fixed.Transform[int16], opts16) }},
+		verifyCheck{"fixed.Transform[int32]", func() (float64, error) { return dft.VerifyForward(fixed.Transform[int32], opts32) }},
+	)
+	if *bits > 0 {
+		checks = append(checks, verifyCheck{fmt.Sprintf("Forward (%d bit DFT)", *bits), func() (float64, error) { return verifyPrecise(opts, *bits) }})
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "transform\tmax. error\tresult")
+	failed := 0
+	for _, c := range checks {
+		e, err := c.check()
+		result := "ok"
+		if err != nil {
+			result = "FAIL: " + err.Error()
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%.3g\t%s\n", c.name, e, result)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d transforms differ from the naive DFT", failed, len(checks))
+	}
+	return nil
+}
+
+// joinInts formats values as comma separated list
+func joinInts(values []int) string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = strconv.Itoa(v)
+	}
+	return strings.Join(items, ",")
+}
+
+// verifyPrecise is dft.VerifyForward of dft.Forward with a reference DFT of
+// prec bits instead of the float64 naive DFT
+func verifyPrecise(opts dft.VerifyOptions, prec uint) (float64, error) {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	worst := 0.0
+	for _, n := range opts.Sizes {
+		for trial := range opts.Trials {
+			x := make([]float64, n)
+			for i := range x {
+				x[i] = rng.NormFloat64()
+			}
+			e := bigdft.RelativeError(dft.Forward(x), bigdft.DFT(x, prec))
+			if e > opts.MaxErr {
+				return worst, fmt.Errorf("size %d, trial %d: relative error %.3g exceeds %.3g", n, trial+1, e, opts.MaxErr)
+			}
+			worst = math.Max(worst, e)
+		}
+	}
+	return worst, nil
+}
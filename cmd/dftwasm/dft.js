@@ -0,0 +1,38 @@
+// dft.js runs the analysis of the web interface in the browser, with the Go
+// code compiled to WebAssembly. It expects next to it dftwasm.wasm, built
+// with
+//
+//   GOOS=js GOARCH=wasm go build -o dftwasm.wasm ./cmd/dftwasm
+//
+// and wasm_exec.js of the same Go version, from $(go env GOROOT)/lib/wasm.
+//
+//   import { analyze } from "./dft.js";
+//   const result = await analyze(audioBuffer.getChannelData(0), audioBuffer.sampleRate);
+//   console.log(result.peaks);
+import "./wasm_exec.js";
+
+let ready;
+
+// load instantiates the module once, analyze calls it on first use. Pass
+// the URL of dftwasm.wasm if it is served from another location.
+export function load(url = new URL("dftwasm.wasm", import.meta.url)) {
+  if (!ready) {
+    const go = new Go();
+    ready = WebAssembly.instantiateStreaming(fetch(url), go.importObject).then(({ instance }) => {
+      // run returns once main blocks, after dftAnalyze is registered
+      go.run(instance);
+    });
+  }
+  return ready;
+}
+
+// analyze returns the spectrum, peaks and spectrogram of the mono samples,
+// the JSON document of /api/analysis of "dftool serve"
+export async function analyze(float32Array, sampleRate) {
+  await load();
+  const result = globalThis.dftAnalyze(float32Array, sampleRate);
+  if (typeof result !== "string") {
+    throw new Error(result.error);
+  }
+  return JSON.parse(result);
+}
@@ -0,0 +1,74 @@
+//go:build js && wasm
+
+// Command dftwasm runs the analysis of the web interface, serve.Analyze, in
+// the browser. Built with
+//
+//	GOOS=js GOARCH=wasm go build -o dftwasm.wasm ./cmd/dftwasm
+//
+// it registers the global function dftAnalyze(samples, sampleRate), which
+// returns the same JSON document as /api/analysis of "dftool serve", or an
+// object with an error message. dft.js wraps it as analyze(float32Array,
+// sampleRate).
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"syscall/js"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/serve"
+)
+
+func main() {
+	js.Global().Set("dftAnalyze", js.FuncOf(analyze))
+	// The function must outlive main
+	select {}
+}
+
+// analyze is dftAnalyze: args are a Float32Array of mono samples and the
+// sample rate in Hz
+func analyze(_ js.Value, args []js.Value) any {
+	data, err := analyzeJSON(args)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	return string(data)
+}
+
+func analyzeJSON(args []js.Value) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("expected samples and sample rate, got %d arguments", len(args))
+	}
+	samples, err := float32Array(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if args[1].Type() != js.TypeNumber || args[1].Int() <= 0 {
+		return nil, fmt.Errorf("invalid sample rate %s", args[1].String())
+	}
+	a, err := serve.Analyze("", samples, args[1].Int(), serve.DefaultOptions)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(a)
+}
+
+// float32Array copies the samples of a Float32Array in one call through the
+// bytes of its buffer instead of one call per sample
+func float32Array(v js.Value) ([]float64, error) {
+	if !v.InstanceOf(js.Global().Get("Float32Array")) {
+		return nil, fmt.Errorf("samples must be a Float32Array")
+	}
+	bytes := js.Global().Get("Uint8Array").New(v.Get("buffer"), v.Get("byteOffset"), v.Get("byteLength"))
+	buf := make([]byte, bytes.Length())
+	js.CopyBytesToGo(buf, bytes)
+	samples := make([]float64, len(buf)/4)
+	for i := range samples {
+		// Typed arrays have the byte order of the platform, little endian
+		// everywhere that runs WebAssembly
+		samples[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:])))
+	}
+	return samples, nil
+}
@@ -0,0 +1,165 @@
+// Command libdft is the C interface of the library, for programs in C, C++,
+// Python (ctypes), Rust and other languages with a C FFI. Built with
+//
+//	go build -buildmode=c-shared -o libdft.so ./cmd/libdft
+//
+// it is a shared library and the header libdft.h with the functions
+//
+//	dft_analyze  amplitude spectrum of a signal
+//	dft_stft     amplitude spectrogram, frame after frame
+//	dft_peaks    spectral peaks above the noise floor
+//
+// The spectra are those of dft.Analyze and dft.AnalyzeFrames with a Hann
+// window, zero-padded to the next power of two. The caller allocates the
+// outputs: like snprintf the functions return the number of values of the
+// complete result and write at most capacity of them, so a call with a
+// capacity of 0 returns the required size. On failure they return -1 and
+// dft_last_error describes the error.
+package main
+
+/*
+#include <stdlib.h>
+
+// dft_peak is a spectral peak of dft_peaks
+typedef struct {
+	double freq_hz;      // interpolated frequency
+	double magnitude;    // interpolated amplitude, a full scale sine has 1
+	double magnitude_db; // magnitude in dB relative to 1
+	double phase;        // phase of the peak bin in radians
+} dft_peak;
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// peakDistanceHz is the minimum distance of two peaks of dft_peaks, which
+// drops the side lobes like dftool analyze
+const peakDistanceHz = 3
+
+var (
+	errMu   sync.Mutex
+	lastErr *C.char // freed when replaced
+)
+
+// setError records err for dft_last_error and returns -1
+func setError(err error) C.int {
+	errMu.Lock()
+	defer errMu.Unlock()
+	C.free(unsafe.Pointer(lastErr))
+	lastErr = C.CString(err.Error())
+	return -1
+}
+
+// dft_last_error returns the message of the last failed call, or NULL. The
+// string is valid until the next failure and must not be freed.
+//
+//export dft_last_error
+func dft_last_error() *C.char {
+	errMu.Lock()
+	defer errMu.Unlock()
+	return lastErr
+}
+
+// goSamples returns the n samples at ptr without copying
+func goSamples(ptr *C.double, n, sampleRate C.int) ([]float64, error) {
+	if ptr == nil || n <= 0 {
+		return nil, fmt.Errorf("no samples")
+	}
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate %d", sampleRate)
+	}
+	return unsafe.Slice((*float64)(unsafe.Pointer(ptr)), int(n)), nil
+}
+
+// output copies values to the capacity values at ptr and returns their
+// number
+func output[T any](ptr *T, capacity C.int, values []T) C.int {
+	if ptr != nil && capacity > 0 {
+		copy(unsafe.Slice(ptr, int(capacity)), values)
+	}
+	return C.int(len(values))
+}
+
+// dft_analyze writes the magnitudes of the n/2+1 bins of the amplitude
+// spectrum of n samples, zero-padded to the next power of two. The bin
+// spacing is sampleRate divided by the padded length.
+//
+//export dft_analyze
+func dft_analyze(samples *C.double, n, sampleRate C.int, magnitude *C.double, capacity C.int) C.int {
+	wave, err := goSamples(samples, n, sampleRate)
+	if err != nil {
+		return setError(err)
+	}
+	result, err := dft.Analyze(wave, int(sampleRate), 0, dft.DefaultSpectrumOptions)
+	if err != nil {
+		return setError(err)
+	}
+	return output(magnitude, capacity, cDoubles(result.Spectrum.Magnitude))
+}
+
+// dft_stft writes the amplitude spectra of the frames of frameSize samples,
+// hopSize samples apart, one row of *bins magnitudes per frame. It returns
+// the number of values, frames times bins.
+//
+//export dft_stft
+func dft_stft(samples *C.double, n, sampleRate, frameSize, hopSize C.int, bins *C.int, magnitude *C.double, capacity C.int) C.int {
+	wave, err := goSamples(samples, n, sampleRate)
+	if err != nil {
+		return setError(err)
+	}
+	result, err := dft.AnalyzeFrames(wave, int(sampleRate), 0, int(frameSize), int(hopSize), dft.DefaultSpectrumOptions)
+	if err != nil {
+		return setError(err)
+	}
+	rowLen := result.FFTSize/2 + 1
+	if bins != nil {
+		*bins = C.int(rowLen)
+	}
+	mag := make([]float64, 0, len(result.Frames)*rowLen)
+	for _, f := range result.Frames {
+		mag = append(mag, dft.NewSpectrum(f.Spectrum, result.SampleRate, result.FFTSize, result.FrameSize).Magnitude...)
+	}
+	return output(magnitude, capacity, cDoubles(mag))
+}
+
+// dft_peaks writes the peaks of the spectrum of dft_analyze that rise
+// minAboveFloorDB above the local noise floor, in ascending frequency
+//
+//export dft_peaks
+func dft_peaks(samples *C.double, n, sampleRate C.int, minAboveFloorDB C.double, peaks *C.dft_peak, capacity C.int) C.int {
+	wave, err := goSamples(samples, n, sampleRate)
+	if err != nil {
+		return setError(err)
+	}
+	result, err := dft.Analyze(wave, int(sampleRate), 0, dft.DefaultSpectrumOptions)
+	if err != nil {
+		return setError(err)
+	}
+	found := dft.FindPeaks(result.Spectrum, dft.PeakOptions{
+		MinAboveFloorDB: float64(minAboveFloorDB),
+		MinDistanceHz:   peakDistanceHz,
+		Order:           dft.ByFrequency,
+	})
+	out := make([]C.dft_peak, len(found))
+	for i, p := range found {
+		out[i] = C.dft_peak{
+			freq_hz:      C.double(p.FreqHz),
+			magnitude:    C.double(p.Magnitude),
+			magnitude_db: C.double(p.MagnitudeDB),
+			phase:        C.double(p.Phase),
+		}
+	}
+	return output(peaks, capacity, out)
+}
+
+func cDoubles(values []float64) []C.double {
+	return unsafe.Slice((*C.double)(unsafe.Pointer(unsafe.SliceData(values))), len(values))
+}
+
+func main() {}
@@ -0,0 +1,166 @@
+package dft
+
+import "math"
+
+// ConvolveOA returns the full linear convolution of signal and impulseResponse
+// (length len(signal)+len(impulseResponse)-1) using FFT based overlap-add.
+//
+// The FFT size is chosen automatically as the power of two that minimizes the
+// cost per output sample, which for long signals is a few times the length of
+// the impulse response.
+func ConvolveOA(signal, impulseResponse []float64) []float64 {
+	n, m := len(signal), len(impulseResponse)
+	if n == 0 || m == 0 {
+		return []float64{}
+	}
+
+	fftSize := overlapAddSize(n, m)
+	block := fftSize - m + 1
+
+	padded := make([]float64, fftSize)
+	copy(padded, impulseResponse)
+	irSpectrum := Forward(padded)
+
+	out := make([]float64, n+m-1)
+	for start := 0; start < n; start += block {
+		end := start + block
+		if end > n {
+			end = n
+		}
+		for i := range padded {
+			padded[i] = 0
+		}
+		copy(padded, signal[start:end])
+
+		spectrum := Forward(padded)
+		for k := range spectrum {
+			spectrum[k] *= irSpectrum[k]
+		}
+		seq := Inverse(spectrum, fftSize)
+		for i := 0; i < fftSize && start+i < len(out); i++ {
+			out[start+i] += seq[i]
+		}
+	}
+	return out
+}
+
+// overlapAddSize chooses the FFT size for overlap-add convolution of a signal of
+// length n with an impulse response of length m
+func overlapAddSize(n, m int) int {
+	minSize := NextPowerOfTwo(2*m - 1)
+	maxSize := NextPowerOfTwo(n + m - 1)
+	if maxSize < minSize {
+		maxSize = minSize
+	}
+
+	best, bestCost := minSize, math.Inf(1)
+	for size := minSize; size <= maxSize; size *= 2 {
+		block := size - m + 1
+		blocks := math.Ceil(float64(n) / float64(block))
+		cost := blocks * float64(size) * math.Log2(float64(size))
+		if cost < bestCost {
+			best, bestCost = size, cost
+		}
+	}
+	return best
+}
+
+// blockBuffer collects pushed samples into fixed size blocks, processes them
+// and hands out the output with a latency of one block
+type blockBuffer struct {
+	blockSize int
+	input     []float64 // samples of the incomplete block
+	output    []float64 // computed samples not yet returned
+	process   func(block []float64) []float64
+}
+
+func (b *blockBuffer) reset() {
+	b.input = make([]float64, 0, b.blockSize)
+	b.output = make([]float64, b.blockSize)
+}
+
+func (b *blockBuffer) push(in []float64) []float64 {
+	for _, v := range in {
+		b.input = append(b.input, v)
+		if len(b.input) == b.blockSize {
+			b.output = append(b.output, b.process(b.input)...)
+			b.input = b.input[:0]
+		}
+	}
+
+	out := make([]float64, len(in))
+	copy(out, b.output)
+	b.output = b.output[len(in):]
+	return out
+}
+
+// Convolver convolves a stream of samples with an impulse response using FFT
+// based overlap-save. Samples can be pushed in chunks of any size, the output is
+// delayed by the block size.
+type Convolver struct {
+	blockBuffer
+	irSpectrum []complex128
+	irLength   int
+	fftSize    int
+	history    []float64 // last fftSize input samples
+}
+
+// NewConvolver creates a Convolver for impulseResponse that processes blocks of
+// blockSize samples. Smaller blocks lower the latency, larger blocks lower the
+// CPU usage per sample.
+func NewConvolver(impulseResponse []float64, blockSize int) *Convolver {
+	if blockSize < 1 {
+		blockSize = 1
+	}
+	// An empty impulse response yields silence, the FFT must still hold a
+	// block
+	fftSize := NextPowerOfTwo(blockSize + max(len(impulseResponse), 1) - 1)
+	padded := make([]float64, fftSize)
+	copy(padded, impulseResponse)
+
+	c := &Convolver{
+		irSpectrum: Forward(padded),
+		irLength:   len(impulseResponse),
+		fftSize:    fftSize,
+	}
+	c.blockBuffer = blockBuffer{blockSize: blockSize, process: c.processBlock}
+	c.Reset()
+	return c
+}
+
+// Latency returns the delay of the output in samples
+func (c *Convolver) Latency() int {
+	return c.blockSize
+}
+
+// Reset clears the internal state as if no samples were processed
+func (c *Convolver) Reset() {
+	c.history = make([]float64, c.fftSize)
+	c.reset()
+}
+
+// Process pushes in and returns the same number of output samples
+func (c *Convolver) Process(in []float64) []float64 {
+	return c.push(in)
+}
+
+// Flush returns the remaining output including the tail of the impulse
+// response, as if silence was pushed until the convolution has decayed.
+func (c *Convolver) Flush() []float64 {
+	return c.Process(make([]float64, c.irLength-1+c.blockSize))
+}
+
+// processBlock convolves a complete input block
+func (c *Convolver) processBlock(block []float64) []float64 {
+	copy(c.history, c.history[c.blockSize:])
+	copy(c.history[c.fftSize-c.blockSize:], block)
+
+	spectrum := Forward(c.history)
+	for k := range spectrum {
+		spectrum[k] *= c.irSpectrum[k]
+	}
+	seq := Inverse(spectrum, c.fftSize)
+
+	// The last blockSize samples are free of circular wrap-around
+	return seq[c.fftSize-c.blockSize:]
+}
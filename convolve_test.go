@@ -0,0 +1,37 @@
+package dft
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvolverEmptyImpulseResponse(t *testing.T) {
+	for _, blockSize := range []int{1, 2, 3, 64} {
+		for _, process := range []func([]float64) []float64{
+			NewConvolver(nil, blockSize).Process,
+			NewPartitionedConvolver(nil, blockSize).Process,
+		} {
+			out := process([]float64{1, 2, 3, 4, 5, 6, 7})
+			for i, v := range out {
+				if v != 0 {
+					t.Fatalf("block size %d: sample %d is %g instead of silence", blockSize, i, v)
+				}
+			}
+		}
+	}
+}
+
+func TestConvolver(t *testing.T) {
+	x := []float64{1, -2, 3, 0.5, 7, -1, 0.25, 4, -3, 2}
+	ir := []float64{0.5, 0.25, -0.125}
+	want := ConvolveOA(x, ir)
+	for _, blockSize := range []int{1, 3, 4} {
+		c := NewConvolver(ir, blockSize)
+		out := append(c.Process(x), c.Flush()...)[c.Latency():]
+		for i, w := range want {
+			if math.Abs(out[i]-w) > 1e-12 {
+				t.Errorf("block size %d: sample %d is %g instead of %g", blockSize, i, out[i], w)
+			}
+		}
+	}
+}
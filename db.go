@@ -0,0 +1,86 @@
+package dft
+
+import "math"
+
+// DBScale is the reference of a decibel value
+type DBScale int
+
+const (
+	DBFS DBScale = iota // relative to full scale, a sine with a magnitude of 1 is 0 dBFS
+	DBV                 // relative to 1 V RMS
+	DBu                 // relative to 0.7746 V RMS (1 mW into 600 Ω)
+)
+
+// DefaultDBFloor is the lowest value returned by ToDB if no floor is configured
+const DefaultDBFloor = -200.0
+
+// Reference values of the decibel scales
+const (
+	dbvReference = 1.0
+	dbuReference = 0.7745966692414834 // sqrt(0.6)
+)
+
+// DBOptions configures the conversion of magnitudes to decibels
+type DBOptions struct {
+	Scale DBScale
+
+	// Reference overrides the reference value of the scale: a magnitude for
+	// DBFS, volts RMS for DBV and DBu.
+	Reference float64
+
+	// VoltsFullScale is the peak voltage that corresponds to a magnitude of 1,
+	// used for DBV and DBu. Defaults to 1 V.
+	VoltsFullScale float64
+
+	// Floor is the lowest returned value in dB, silence is clamped to it.
+	// Defaults to DefaultDBFloor.
+	Floor float64
+}
+
+// AmplitudeToDB converts a single magnitude to decibels
+func AmplitudeToDB(mag float64, opts DBOptions) float64 {
+	floor := opts.Floor
+	if floor == 0 {
+		floor = DefaultDBFloor
+	}
+
+	var value, reference float64
+	switch opts.Scale {
+	case DBV, DBu:
+		// magnitudes are sine amplitudes, the voltage scales are RMS
+		volts := opts.VoltsFullScale
+		if volts == 0 {
+			volts = 1
+		}
+		value = mag * volts / math.Sqrt2
+		reference = dbvReference
+		if opts.Scale == DBu {
+			reference = dbuReference
+		}
+	default:
+		value = mag
+		reference = 1
+	}
+	if opts.Reference > 0 {
+		reference = opts.Reference
+	}
+
+	if value <= 0 {
+		return floor
+	}
+	return math.Max(floor, 20*math.Log10(value/reference))
+}
+
+// ToDB converts magnitudes to decibels
+func ToDB(mag []float64, opts DBOptions) []float64 {
+	db := make([]float64, len(mag))
+	for i, m := range mag {
+		db[i] = AmplitudeToDB(m, opts)
+	}
+	return db
+}
+
+// DB returns the magnitude spectrum of s in decibels, see ToDB
+func (s Spectrum) DB(opts DBOptions) []float64 {
+	return ToDB(s.Magnitude, opts)
+}
@@ -0,0 +1,102 @@
+// Package denoise implements STFT based noise reduction.
+package denoise
+
+import (
+	"fmt"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// NoiseProfile is the mean power of every frequency bin of a noise-only segment
+type NoiseProfile struct {
+	SampleRate int
+	FrameSize  int
+	HopSize    int
+	Power      []float64 // mean |X[k]|² per bin
+}
+
+// SubtractionOptions configures SpectralSubtract
+type SubtractionOptions struct {
+	// OverSubtraction is the factor the noise power is multiplied with before it
+	// is subtracted. Values above 1 (typically 1..4) remove more noise and
+	// reduce musical noise at the cost of speech or music distortion.
+	OverSubtraction float64
+
+	// Floor is the share of the noise power that is kept in bins that would
+	// become negative after subtraction (typically 0.001..0.1). A small floor
+	// masks the residual musical noise.
+	Floor float64
+}
+
+// DefaultSubtractionOptions are moderate settings for SpectralSubtract
+var DefaultSubtractionOptions = SubtractionOptions{OverSubtraction: 2, Floor: 0.01}
+
+// LearnNoiseProfile learns the noise profile from a noise-only segment, e.g. the
+// silence before the program material. Use the same frame and hop size that is
+// used for denoising.
+func LearnNoiseProfile(noise []float64, sampleRate, frameSize, hopSize int) (NoiseProfile, error) {
+	frames := dft.STFT(noise, sampleRate, frameSize, hopSize)
+	if len(frames) == 0 {
+		return NoiseProfile{}, fmt.Errorf("noise segment is shorter than a frame")
+	}
+
+	power := make([]float64, frameSize/2+1)
+	for _, frame := range frames {
+		for k, c := range frame.Spectrum {
+			power[k] += real(c)*real(c) + imag(c)*imag(c)
+		}
+	}
+	for k := range power {
+		power[k] /= float64(len(frames))
+	}
+
+	return NoiseProfile{
+		SampleRate: sampleRate,
+		FrameSize:  frameSize,
+		HopSize:    hopSize,
+		Power:      power,
+	}, nil
+}
+
+// SpectralSubtract removes the noise described by profile from samples with
+// power spectral subtraction (Berouti et al., 1979). The noisy phase is kept
+// and the signal is reconstructed with dft.ISTFT.
+func SpectralSubtract(samples []float64, profile NoiseProfile, opts SubtractionOptions) []float64 {
+	return applyGain(samples, profile, func(frame []complex128, out []float64) {
+		for k, c := range frame {
+			power := real(c)*real(c) + imag(c)*imag(c)
+			noise := profile.Power[k]
+			clean := power - opts.OverSubtraction*noise
+			if clean < opts.Floor*noise {
+				clean = opts.Floor * noise
+			}
+			if power > 0 {
+				out[k] = math.Sqrt(clean / power)
+			} else {
+				out[k] = 0
+			}
+		}
+	})
+}
+
+// applyGain computes the STFT of samples, multiplies every bin with the gain
+// computed by gain for each frame and reconstructs the signal. The signal is
+// padded by a frame on both sides so the whole signal is covered by windows.
+func applyGain(samples []float64, profile NoiseProfile, gain func(frame []complex128, out []float64)) []float64 {
+	frameSize, hopSize := profile.FrameSize, profile.HopSize
+	padded := make([]float64, len(samples)+2*frameSize)
+	copy(padded[frameSize:], samples)
+
+	frames := dft.STFT(padded, profile.SampleRate, frameSize, hopSize)
+	g := make([]float64, frameSize/2+1)
+	for _, frame := range frames {
+		gain(frame.Spectrum, g)
+		for k := range frame.Spectrum {
+			frame.Spectrum[k] *= complex(g[k], 0)
+		}
+	}
+
+	out := dft.ISTFT(frames, frameSize, hopSize, len(padded))
+	return out[frameSize : frameSize+len(samples)]
+}
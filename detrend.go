@@ -0,0 +1,132 @@
+package dft
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DCBlockPole is the pole of the DC blocking filter of BlockDC. Its cutoff is
+// about sampleRate/6300, e.g. 7.6 Hz at 48 kHz and 0.016 Hz at 100 Hz.
+const DCBlockPole = 0.999
+
+// Detrend is a preprocessing step that removes the DC component or a slow
+// drift of a signal before the FFT. Otherwise the leakage of a DC offset or a
+// trend, e.g. of sensor data, covers the lowest bins.
+type Detrend int
+
+const (
+	NoDetrend     Detrend = iota
+	DetrendMean           // subtract the mean
+	DetrendLinear         // subtract the least squares line
+	DetrendDC             // apply the DC blocking filter of BlockDC
+)
+
+var detrendNames = map[Detrend]string{
+	NoDetrend:     "none",
+	DetrendMean:   "mean",
+	DetrendLinear: "linear",
+	DetrendDC:     "dc-block",
+}
+
+// String returns the name of the detrending, e.g. "linear"
+func (d Detrend) String() string {
+	return detrendNames[d]
+}
+
+// ParseDetrend parses a name as returned by Detrend.String, an empty string
+// is "none"
+func ParseDetrend(s string) (Detrend, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "" {
+		return NoDetrend, nil
+	}
+	for d, n := range detrendNames {
+		if n == name {
+			return d, nil
+		}
+	}
+	return NoDetrend, fmt.Errorf("unknown detrending %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler with the format of String
+func (d Detrend) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler with ParseDetrend
+func (d *Detrend) UnmarshalText(text []byte) error {
+	v, err := ParseDetrend(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Apply removes the trend from wave in place. The mean and the line are
+// those of wave, e.g. of a single frame.
+func (d Detrend) Apply(wave []float64) {
+	switch d {
+	case DetrendMean:
+		RemoveMean(wave)
+	case DetrendLinear:
+		RemoveLinearTrend(wave)
+	case DetrendDC:
+		BlockDC(wave, DCBlockPole)
+	}
+}
+
+// RemoveMean subtracts the mean of wave from each sample
+func RemoveMean(wave []float64) {
+	if len(wave) == 0 {
+		return
+	}
+	mean := 0.0
+	for _, v := range wave {
+		mean += v
+	}
+	mean /= float64(len(wave))
+	for i := range wave {
+		wave[i] -= mean
+	}
+}
+
+// RemoveLinearTrend subtracts the least squares line through wave, which
+// removes the mean and a constant drift
+func RemoveLinearTrend(wave []float64) {
+	n := float64(len(wave))
+	if n < 2 {
+		RemoveMean(wave)
+		return
+	}
+	// With x centered on the middle sample slope and intercept decouple
+	center := (n - 1) / 2
+	var sum, sumXY, sumXX float64
+	for i, v := range wave {
+		x := float64(i) - center
+		sum += v
+		sumXY += x * v
+		sumXX += x * x
+	}
+	mean, slope := sum/n, sumXY/sumXX
+	for i := range wave {
+		wave[i] -= mean + slope*(float64(i)-center)
+	}
+}
+
+// BlockDC filters wave in place with the DC blocker
+// y[n] = x[n] - x[n-1] + pole·y[n-1], a high-pass with a zero at DC. pole is
+// in (0..1), closer to 1 lowers the cutoff and lengthens the settling. The
+// filter starts settled on the first sample so a DC offset does not cause a
+// transient.
+func BlockDC(wave []float64, pole float64) {
+	if len(wave) == 0 {
+		return
+	}
+	prevX, prevY := wave[0], 0.0
+	for i, x := range wave {
+		y := x - prevX + pole*prevY
+		prevX, prevY = x, y
+		wave[i] = y
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/iq"
+)
+
+// Example of the spectrum of an SDR capture.
+// This example finds the strongest signals in a file of interleaved I/Q samples.
+
+func main() {
+	inputFile := flag.String("input", "", "path for input I/Q file")
+	formatName := flag.String("format", "", "sample format: cu8, cs8, cs16 or cf32 (default: from the file extension)")
+	sampleRate := flag.Int("rate", 2048000, "sample rate in Hz")
+	centerHz := flag.Float64("center", 0, "center frequency the receiver was tuned to in Hz")
+	fftSize := flag.Int("fft", 65536, "number of samples analyzed from the start of the file")
+	topN := flag.Int("top", 10, "report only the N strongest peaks (0 reports all)")
+	minAboveFloor := flag.Float64("floor", 10, "min. peak height above the local noise floor in dB")
+	flag.Parse()
+
+	if *inputFile == "" {
+		log.Fatalln("missing input file")
+	}
+
+	var format iq.Format
+	var err error
+	if *formatName != "" {
+		format, err = iq.ParseFormat(*formatName)
+	} else {
+		format, err = iq.FormatFromExtension(*inputFile)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	f, err := os.Open(*inputFile)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer f.Close()
+	samples, err := iq.Load(f, format)
+	if err != nil {
+		log.Fatalln("failed to load I/Q file:", err)
+	}
+	if len(samples) > *fftSize {
+		samples = samples[:*fftSize]
+	}
+	log.Println("samples:", len(samples))
+
+	spectrum := dft.ComputeIQSpectrum(samples, *sampleRate, *centerHz)
+	peaks := spectrum.FindPeaks(dft.PeakOptions{
+		MinAboveFloorDB: *minAboveFloor,
+		MaxPeaks:        *topN,
+		Order:           dft.ByMagnitude,
+	})
+
+	fmt.Println("Detected signals:")
+	for _, p := range peaks {
+		fmt.Printf("Frequency: %.0f Hz, Level: %.1f dBFS\n", p.FreqHz, p.MagnitudeDB)
+	}
+}
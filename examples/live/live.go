@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/capture"
+	"github.com/epikur-io/go-discrete-fourier-transform/metrics"
+	"github.com/epikur-io/go-discrete-fourier-transform/plot"
+	"github.com/epikur-io/go-discrete-fourier-transform/serve"
+)
+
+// Example of a live spectrum analysis.
+// This example records from an input device and prints the strongest frequency of every block.
+
+func main() {
+	list := flag.Bool("list", false, "list the input devices and exit")
+	device := flag.String("device", "", "input device (default: the system default)")
+	sampleRate := flag.Int("rate", capture.DefaultConfig.SampleRate, "sample rate in Hz")
+	blockSize := flag.Int("block", capture.DefaultConfig.BlockSize, "samples per analyzed block")
+	traceMode := flag.String("mode", "clear", "trace mode: clear, average, max-hold or min-hold")
+	alpha := flag.Float64("alpha", 0.2, "weight of the latest block in average mode")
+	minMagnitude := flag.Float64("mmt", 0.01, "Min. magnitude of the reported peak")
+	display := flag.String("display", "text", "output: text (strongest peak per block), bars (spectrum bar graph) or waterfall")
+	serveAddr := flag.String("serve", "", "also stream the spectra over WebSocket and serve the web page on this address, e.g. localhost:8080")
+	metricsAddr := flag.String("metrics", "", "serve Prometheus metrics at /metrics on this address, e.g. localhost:9100")
+	bands := flag.String("bands", "", "frequency bands of the metrics as name:low-high,..., e.g. low:20-250,high:250-8000 (default: low, mid and high)")
+	flag.Parse()
+
+	var backend capture.Backend = capture.ALSA{}
+	if *list {
+		devices, err := backend.Devices()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		for _, d := range devices {
+			fmt.Printf("%s\t%s\n", d.ID, d.Description)
+		}
+		return
+	}
+
+	mode, err := dft.ParseTraceMode(*traceMode)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	trace := dft.NewTrace(mode, *alpha)
+
+	cfg := capture.Config{SampleRate: *sampleRate, Channels: 1, BlockSize: *blockSize}
+
+	var term *plot.Terminal
+	switch *display {
+	case "text":
+	case "bars", "waterfall":
+		opts := plot.DefaultTerminalOptions
+		opts.Waterfall = *display == "waterfall"
+		term, err = plot.NewTerminal(os.Stdout, cfg.SampleRate, cfg.BlockSize, opts)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	default:
+		log.Fatalf("invalid display %q", *display)
+	}
+
+	var live *serve.Stream
+	if *serveAddr != "" {
+		live = serve.NewStream()
+		server := serve.New(serve.DefaultOptions)
+		server.Handle("GET /api/stream", live)
+		go func() {
+			log.Fatalln(http.ListenAndServe(*serveAddr, server))
+		}()
+		log.Printf("serving on http://%s/?live", *serveAddr)
+	}
+
+	var exporter *metrics.Exporter
+	if *metricsAddr != "" {
+		opts := metrics.DefaultOptions
+		if *bands != "" {
+			if opts.Bands, err = metrics.ParseBands(*bands); err != nil {
+				log.Fatalln(err)
+			}
+		}
+		exporter = metrics.NewExporter(opts)
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", exporter)
+		go func() {
+			log.Fatalln(http.ListenAndServe(*metricsAddr, mux))
+		}()
+		log.Printf("serving metrics on http://%s/metrics", *metricsAddr)
+	}
+
+	stream, err := backend.Open(*device, cfg)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer stream.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err = capture.Run(ctx, stream, cfg, func(b capture.Block) error {
+		b.Spectrum.Magnitude = trace.Update(b.Spectrum.Magnitude)
+		if exporter != nil {
+			exporter.Update(b.Spectrum)
+		}
+		if live != nil {
+			live.Publish(b.Index, b.Time, b.Spectrum, dft.FindPeaks(b.Spectrum, serve.DefaultOptions.Peaks))
+		}
+		if term != nil {
+			return term.Draw(b.Spectrum.Magnitude)
+		}
+		peaks := dft.FindPeaks(b.Spectrum, dft.PeakOptions{MinHeight: *minMagnitude, MaxPeaks: 1, Order: dft.ByMagnitude})
+		if len(peaks) == 0 {
+			fmt.Println("-")
+			return nil
+		}
+		p := peaks[0]
+		note, err := dft.NoteFromFrequency(p.FreqHz, dft.DefaultReferencePitch)
+		if err != nil {
+			fmt.Printf("Frequency: %.1f Hz, Magnitude: %.3f\n", p.FreqHz, p.Magnitude)
+			return nil
+		}
+		fmt.Printf("Frequency: %.1f Hz, Magnitude: %.3f, Note: %s\n", p.FreqHz, p.Magnitude, note)
+		return nil
+	})
+	if err != nil && err != context.Canceled {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/audio"
+	"github.com/epikur-io/go-discrete-fourier-transform/rpc"
+	"github.com/epikur-io/go-discrete-fourier-transform/serve"
+)
+
+// Example of the web interface.
+// This example serves an interactive spectrum and spectrogram of an audio file, more files can be uploaded on the page.
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "listen address")
+	inputFile := flag.String("input", "", "path of the audio file shown at start (optional)")
+	grpcAddr := flag.String("grpc", "", "also serve the gRPC analysis service on this address, e.g. localhost:9090")
+	flag.Parse()
+
+	server := serve.New(serve.DefaultOptions)
+	if *inputFile != "" {
+		f, err := os.Open(*inputFile)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		samples, sampleRate, _, err := audio.LoadAudio(f, audio.FormatFromExtension(*inputFile))
+		f.Close()
+		if err != nil {
+			log.Fatalln("failed to load audio file:", err)
+		}
+		if err := server.Load(filepath.Base(*inputFile), samples, sampleRate); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	if *grpcAddr != "" {
+		go func() {
+			log.Printf("serving gRPC on %s", *grpcAddr)
+			log.Fatalln(rpc.New(rpc.DefaultOptions).ListenAndServe(*grpcAddr))
+		}()
+	}
+
+	log.Printf("serving on http://%s", *addr)
+	log.Fatalln(http.ListenAndServe(*addr, server))
+}
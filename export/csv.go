@@ -0,0 +1,100 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"math/cmplx"
+	"strconv"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// CSVOptions configures the CSV writers
+type CSVOptions struct {
+	Comma rune // field delimiter, defaults to ','. Use '\t' for TSV
+	Phase bool // add the phase in radians to spectrum rows
+	DB    bool // write magnitudes in dB relative to 1.0 instead of linear amplitudes
+}
+
+// DefaultCSVOptions writes comma separated linear magnitudes
+var DefaultCSVOptions = CSVOptions{Comma: ','}
+
+func newCSVWriter(w io.Writer, opts CSVOptions) *csv.Writer {
+	cw := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+	return cw
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func magnitudes(s dft.Spectrum, opts CSVOptions) []float64 {
+	if opts.DB {
+		return s.DB(dft.DBOptions{})
+	}
+	return s.Magnitude
+}
+
+// WriteSpectrumCSV writes one row per bin of s with its frequency in Hz and
+// magnitude, and optionally its phase, after a header row
+func WriteSpectrumCSV(w io.Writer, s dft.Spectrum, opts CSVOptions) error {
+	cw := newCSVWriter(w, opts)
+	header := []string{"frequency_hz", "magnitude"}
+	if opts.DB {
+		header[1] = "magnitude_db"
+	}
+	if opts.Phase {
+		header = append(header, "phase")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	mag := magnitudes(s, opts)
+	freqs := dft.FreqAxis(s.FFTSize, s.SampleRate)
+	for i := range mag {
+		row := []string{formatFloat(freqs[i]), formatFloat(mag[i])}
+		if opts.Phase {
+			row = append(row, formatFloat(cmplx.Phase(s.Coefficients[i])))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSpectrogramCSV writes the magnitudes of STFT frames as a frames-by-bins
+// table. The header row holds the bin frequencies in Hz and every following
+// row starts with the time of the frame in seconds. frameSize is the frame size
+// the STFT was computed with.
+func WriteSpectrogramCSV(w io.Writer, frames []dft.Frame, sampleRate, frameSize int, opts CSVOptions) error {
+	cw := newCSVWriter(w, opts)
+	bins := frameSize/2 + 1
+	row := make([]string, bins+1)
+	row[0] = "time_s"
+	for i, f := range dft.FreqAxis(frameSize, sampleRate) {
+		row[i+1] = formatFloat(f)
+	}
+	if err := cw.Write(row); err != nil {
+		return err
+	}
+
+	for _, f := range frames {
+		mag := magnitudes(dft.NewSpectrum(f.Spectrum, sampleRate, frameSize, frameSize), opts)
+		row = row[:1]
+		row[0] = formatFloat(f.Time)
+		for _, m := range mag {
+			row = append(row, formatFloat(m))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
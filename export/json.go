@@ -0,0 +1,84 @@
+// Package export writes analysis results in formats that other tools can read,
+// e.g. JSON for scripts and services.
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// SpectrumInfo describes the spectrum the peaks were detected in
+type SpectrumInfo struct {
+	SampleRate   int     `json:"sample_rate"`
+	FFTSize      int     `json:"fft_size"`
+	SignalLength int     `json:"signal_length"` // number of samples before zero-padding
+	FreqRes      float64 `json:"freq_res_hz"`   // bin spacing
+	Resolution   float64 `json:"resolution_hz"` // noise bandwidth of the window, not improved by zero-padding
+	Window       string  `json:"window"`
+}
+
+// Peak is a detected spectral peak
+type Peak struct {
+	FreqHz      float64 `json:"freq_hz"`
+	Magnitude   float64 `json:"magnitude"`
+	MagnitudeDB float64 `json:"magnitude_db"`
+	Phase       float64 `json:"phase"`
+	Bin         int     `json:"bin"`
+	Prominence  float64 `json:"prominence,omitempty"`
+	Note        string  `json:"note,omitempty"`
+}
+
+// Report is the result of a spectrum analysis
+type Report struct {
+	Input      string          `json:"input,omitempty"`
+	Parameters map[string]any  `json:"parameters,omitempty"` // settings used for the analysis, e.g. flag values
+	Spectrum   SpectrumInfo    `json:"spectrum"`
+	Provenance *dft.Provenance `json:"provenance,omitempty"` // set by NewResultReport
+	Peaks      []Peak          `json:"peaks"`
+}
+
+// NewReport creates a Report of the peaks found in s. The spectrum is assumed
+// to be computed with ComputeSpectrum, i.e. with a Hanning window.
+func NewReport(s dft.Spectrum, peaks []dft.Peak) Report {
+	r := Report{
+		Spectrum: SpectrumInfo{
+			SampleRate:   s.SampleRate,
+			FFTSize:      s.FFTSize,
+			SignalLength: s.SignalLength,
+			FreqRes:      s.FreqRes(),
+			Resolution:   dft.Window{Type: dft.Hanning}.ENBW() * float64(s.SampleRate) / float64(s.SignalLength),
+			Window:       dft.Window{Type: dft.Hanning}.String(),
+		},
+		Peaks: make([]Peak, len(peaks)),
+	}
+	for i, p := range peaks {
+		r.Peaks[i] = Peak{
+			FreqHz:      p.FreqHz,
+			Magnitude:   p.Magnitude,
+			MagnitudeDB: p.MagnitudeDB,
+			Phase:       p.Phase,
+			Bin:         p.BinIndex,
+			Prominence:  p.Prominence,
+		}
+	}
+	return r
+}
+
+// NewResultReport is NewReport for the spectrum of r, including its window
+// and provenance
+func NewResultReport(r dft.Result, peaks []dft.Peak) Report {
+	report := NewReport(r.Spectrum, peaks)
+	report.Spectrum.Window = r.Window.String()
+	report.Spectrum.Resolution = r.Resolution()
+	report.Provenance = &r.Provenance
+	return report
+}
+
+// WriteJSON writes r as indented JSON to w
+func WriteJSON(w io.Writer, r Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
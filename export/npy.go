@@ -0,0 +1,158 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// npyMagic starts every .npy file, followed by the format version 1.0
+const npyMagic = "\x93NUMPY\x01\x00"
+
+// WriteNPY writes data as a NumPy .npy array of float64 values. The shape
+// defaults to a one-dimensional array of len(data) values, multi-dimensional
+// arrays are stored in row-major order.
+func WriteNPY(w io.Writer, data []float64, shape ...int) error {
+	if err := writeNPYHeader(w, "<f8", len(data), shape); err != nil {
+		return err
+	}
+	buf := make([]byte, 8*len(data))
+	for i, v := range data {
+		binary.LittleEndian.PutUint64(buf[8*i:], math.Float64bits(v))
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// WriteComplexNPY is like WriteNPY for complex128 values, e.g. FFT coefficients
+func WriteComplexNPY(w io.Writer, data []complex128, shape ...int) error {
+	if err := writeNPYHeader(w, "<c16", len(data), shape); err != nil {
+		return err
+	}
+	buf := make([]byte, 16*len(data))
+	for i, v := range data {
+		binary.LittleEndian.PutUint64(buf[16*i:], math.Float64bits(real(v)))
+		binary.LittleEndian.PutUint64(buf[16*i+8:], math.Float64bits(imag(v)))
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func writeNPYHeader(w io.Writer, descr string, n int, shape []int) error {
+	if shape == nil {
+		shape = []int{n}
+	}
+	size := 1
+	dims := make([]string, len(shape))
+	for i, d := range shape {
+		size *= d
+		dims[i] = fmt.Sprint(d)
+	}
+	if size != n {
+		return fmt.Errorf("shape %v does not match %d values", shape, n)
+	}
+	tuple := "(" + strings.Join(dims, ", ") + ")"
+	if len(shape) == 1 {
+		tuple = "(" + dims[0] + ",)"
+	}
+
+	// The header is padded with spaces so the data is 64 byte aligned
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': %s, }", descr, tuple)
+	total := len(npyMagic) + 2 + len(header) + 1
+	header += strings.Repeat(" ", (64-total%64)%64) + "\n"
+
+	buf := make([]byte, len(npyMagic)+2, len(npyMagic)+2+len(header))
+	copy(buf, npyMagic)
+	binary.LittleEndian.PutUint16(buf[len(npyMagic):], uint16(len(header)))
+	buf = append(buf, header...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// NPZWriter writes several named arrays into a NumPy .npz archive, which is
+// loaded with numpy.load
+type NPZWriter struct {
+	zw *zip.Writer
+}
+
+// NewNPZWriter creates an NPZWriter writing to w. Close must be called to
+// complete the archive.
+func NewNPZWriter(w io.Writer) *NPZWriter {
+	return &NPZWriter{zw: zip.NewWriter(w)}
+}
+
+// Add adds the float64 array name, see WriteNPY
+func (z *NPZWriter) Add(name string, data []float64, shape ...int) error {
+	f, err := z.zw.Create(name + ".npy")
+	if err != nil {
+		return err
+	}
+	return WriteNPY(f, data, shape...)
+}
+
+// AddComplex adds the complex128 array name, see WriteComplexNPY
+func (z *NPZWriter) AddComplex(name string, data []complex128, shape ...int) error {
+	f, err := z.zw.Create(name + ".npy")
+	if err != nil {
+		return err
+	}
+	return WriteComplexNPY(f, data, shape...)
+}
+
+// Close finishes the archive, it does not close the underlying writer
+func (z *NPZWriter) Close() error {
+	return z.zw.Close()
+}
+
+// WriteSpectrumNPZ writes s as an .npz archive with the arrays frequency (Hz),
+// magnitude, coefficients and the scalar sample_rate
+func WriteSpectrumNPZ(w io.Writer, s dft.Spectrum) error {
+	freqs := dft.FreqAxis(s.FFTSize, s.SampleRate)
+
+	z := NewNPZWriter(w)
+	if err := z.Add("frequency", freqs); err != nil {
+		return err
+	}
+	if err := z.Add("magnitude", s.Magnitude); err != nil {
+		return err
+	}
+	if err := z.AddComplex("coefficients", s.Coefficients); err != nil {
+		return err
+	}
+	// an empty shape stores a scalar
+	if err := z.Add("sample_rate", []float64{float64(s.SampleRate)}, []int{}...); err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+// WriteSpectrogramNPZ writes the magnitudes of STFT frames as an .npz archive
+// with the arrays time (s), frequency (Hz) and magnitude with the shape
+// (frames, bins). frameSize is the frame size the STFT was computed with.
+func WriteSpectrogramNPZ(w io.Writer, frames []dft.Frame, sampleRate, frameSize int) error {
+	bins := frameSize/2 + 1
+	times := make([]float64, len(frames))
+	freqs := dft.FreqAxis(frameSize, sampleRate)
+	mag := make([]float64, 0, len(frames)*bins)
+	for i, f := range frames {
+		times[i] = f.Time
+		mag = append(mag, dft.NewSpectrum(f.Spectrum, sampleRate, frameSize, frameSize).Magnitude...)
+	}
+
+	z := NewNPZWriter(w)
+	if err := z.Add("time", times); err != nil {
+		return err
+	}
+	if err := z.Add("frequency", freqs); err != nil {
+		return err
+	}
+	if err := z.Add("magnitude", mag, len(frames), bins); err != nil {
+		return err
+	}
+	return z.Close()
+}
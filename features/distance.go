@@ -0,0 +1,201 @@
+package features
+
+import (
+	"fmt"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// powerFloor is added to powers before taking ratios, about -200 dB
+const powerFloor = 1e-20
+
+// Distance is a dissimilarity of two spectra of the same length, 0 for
+// identical spectra
+type Distance func(p, q []float64) float64
+
+// ParseDistance returns the distance with the given name: cosine, lsd
+// (log-spectral distance) or itakura-saito
+func ParseDistance(name string) (Distance, error) {
+	switch name {
+	case "cosine":
+		return CosineDistance, nil
+	case "lsd":
+		return LogSpectralDistance, nil
+	case "itakura-saito", "is":
+		return ItakuraSaito, nil
+	}
+	return nil, fmt.Errorf("unknown distance %q", name)
+}
+
+// Power returns the power spectrum of FFT coefficients
+func Power(spectrum []complex128) []float64 {
+	p := make([]float64, len(spectrum))
+	for k, c := range spectrum {
+		p[k] = real(c)*real(c) + imag(c)*imag(c)
+	}
+	return p
+}
+
+// PowerFrames returns the power spectra of STFT frames, the input of the
+// distances and of DTW
+func PowerFrames(frames []dft.Frame) [][]float64 {
+	p := make([][]float64, len(frames))
+	for i, f := range frames {
+		p[i] = Power(f.Spectrum)
+	}
+	return p
+}
+
+// CosineDistance is 1 minus the cosine of the angle between p and q. It
+// ignores the level and compares the shape of the spectra, it is 0 for
+// proportional spectra and at most 1 for non-negative ones. Silence has a
+// distance of 1 to everything but silence.
+func CosineDistance(p, q []float64) float64 {
+	var pq, pp, qq float64
+	for k := range p {
+		pq += p[k] * q[k]
+		pp += p[k] * p[k]
+		qq += q[k] * q[k]
+	}
+	if pp == 0 || qq == 0 {
+		if pp == qq {
+			return 0
+		}
+		return 1
+	}
+	return 1 - pq/math.Sqrt(pp*qq)
+}
+
+// LogSpectralDistance is the RMS difference of the power spectra p and q in
+// dB. It is symmetric and weighs quiet and loud bins alike.
+func LogSpectralDistance(p, q []float64) float64 {
+	if len(p) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for k := range p {
+		d := 10 * math.Log10((p[k]+powerFloor)/(q[k]+powerFloor))
+		sum += d * d
+	}
+	return math.Sqrt(sum / float64(len(p)))
+}
+
+// ItakuraSaito is the Itakura–Saito divergence of the power spectrum p from
+// the model q, the mean of p/q - log(p/q) - 1 over the bins. It is not
+// symmetric: bins where q underestimates p cost more than the reverse, which
+// suits comparing a spectrum against a smooth model such as an LPC envelope.
+// It does not change if both spectra are scaled by the same factor.
+func ItakuraSaito(p, q []float64) float64 {
+	if len(p) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for k := range p {
+		r := (p[k] + powerFloor) / (q[k] + powerFloor)
+		sum += r - math.Log(r) - 1
+	}
+	return sum / float64(len(p))
+}
+
+// MeanDistance returns the mean distance of the frames of two spectrograms of
+// the same length, compared frame by frame
+func MeanDistance(a, b [][]float64, d Distance) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("the spectrograms have %d and %d frames, align them with DTW", len(a), len(b))
+	}
+	if len(a) == 0 {
+		return 0, nil
+	}
+	sum := 0.0
+	for i := range a {
+		sum += d(a[i], b[i])
+	}
+	return sum / float64(len(a)), nil
+}
+
+// Alignment is the result of DTW
+type Alignment struct {
+	Cost float64  // sum of the frame distances along the path
+	Path [][2]int // indices of the aligned frames of a and b, from the first to the last frames
+}
+
+// Normalized returns the cost per step of the path, which is comparable
+// between sequences of different lengths
+func (a Alignment) Normalized() float64 {
+	if len(a.Path) == 0 {
+		return 0
+	}
+	return a.Cost / float64(len(a.Path))
+}
+
+// DTW aligns two sequences of spectra by dynamic time warping: it finds the
+// monotonic path from the first to the last frames of both that minimizes
+// the sum of the frame distances, allowing each frame to repeat. If radius is
+// positive, the path stays within radius frames of the diagonal (a
+// Sakoe–Chiba band), which bounds the warping and reduces the cost from
+// len(a)·len(b) to about len(a)·(2·radius+1) distance evaluations.
+func DTW(a, b [][]float64, d Distance, radius int) (Alignment, error) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return Alignment{}, fmt.Errorf("empty sequence")
+	}
+
+	// Row i stores the accumulated costs of the columns lo[i]..hi[i]
+	lo := make([]int, n)
+	hi := make([]int, n)
+	cost := make([][]float64, n)
+	for i := range n {
+		lo[i], hi[i] = 0, m-1
+		if radius > 0 {
+			center := i * m / n
+			if n > 1 {
+				center = int(math.Round(float64(i) * float64(m-1) / float64(n-1)))
+			}
+			lo[i], hi[i] = max(0, center-radius), min(m-1, center+radius)
+		}
+		cost[i] = make([]float64, hi[i]-lo[i]+1)
+	}
+	at := func(i, j int) float64 {
+		if i < 0 || j < lo[i] || j > hi[i] {
+			return math.Inf(1)
+		}
+		return cost[i][j-lo[i]]
+	}
+	for i := range n {
+		for j := lo[i]; j <= hi[i]; j++ {
+			prev := 0.0
+			if i > 0 || j > 0 {
+				prev = math.Min(at(i-1, j-1), math.Min(at(i-1, j), at(i, j-1)))
+			}
+			cost[i][j-lo[i]] = prev + d(a[i], b[j])
+		}
+	}
+	total := at(n-1, m-1)
+	if math.IsInf(total, 1) {
+		return Alignment{}, fmt.Errorf("no path within %d frames of the diagonal", radius)
+	}
+
+	// Trace the cheapest predecessors back from the end
+	path := [][2]int{{n - 1, m - 1}}
+	for i, j := n-1, m-1; i > 0 || j > 0; {
+		diag, up, left := at(i-1, j-1), at(i-1, j), at(i, j-1)
+		switch {
+		case i == 0:
+			j--
+		case j == 0:
+			i--
+		case diag <= up && diag <= left:
+			i, j = i-1, j-1
+		case up <= left:
+			i--
+		default:
+			j--
+		}
+		path = append(path, [2]int{i, j})
+	}
+	for l, r := 0, len(path)-1; l < r; l, r = l+1, r-1 {
+		path[l], path[r] = path[r], path[l]
+	}
+	return Alignment{Cost: total, Path: path}, nil
+}
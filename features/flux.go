@@ -0,0 +1,52 @@
+package features
+
+import (
+	"math"
+	"math/cmplx"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// SpectralFlux returns the frame to frame spectral flux of STFT frames: the sum
+// of the positive changes of the log compressed magnitudes. Log compression
+// makes the flux less dominated by loud partials. The first frame is compared
+// against silence.
+func SpectralFlux(frames []dft.Frame) []float64 {
+	flux := make([]float64, len(frames))
+	var prev []float64
+	for i, frame := range frames {
+		if prev == nil {
+			prev = make([]float64, len(frame.Spectrum))
+		}
+		mag := make([]float64, len(frame.Spectrum))
+		for k, c := range frame.Spectrum {
+			mag[k] = math.Log1p(cmplx.Abs(c))
+		}
+		for k := range mag {
+			if k < len(prev) {
+				if d := mag[k] - prev[k]; d > 0 {
+					flux[i] += d
+				}
+			}
+		}
+		prev = mag
+	}
+	return flux
+}
+
+// Novelty computes the novelty curve of samples, the spectral flux of their STFT
+// normalized to [0..1]. Use dft.FrameTime to get the time of each value.
+func Novelty(samples []float64, sampleRate, frameSize, hopSize int) []float64 {
+	flux := SpectralFlux(dft.STFT(samples, sampleRate, frameSize, hopSize))
+
+	peak := 0.0
+	for _, v := range flux {
+		peak = math.Max(peak, v)
+	}
+	if peak > 0 {
+		for i := range flux {
+			flux[i] /= peak
+		}
+	}
+	return flux
+}
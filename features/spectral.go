@@ -0,0 +1,122 @@
+// Package features computes spectral features for classification and
+// machine learning pipelines.
+package features
+
+import (
+	"math"
+	"math/cmplx"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// DefaultRolloff is the commonly used energy share for the spectral rolloff
+const DefaultRolloff = 0.85
+
+// Descriptors are the spectral shape descriptors of a single spectrum.
+// Frequencies are in Hz, moments treat the magnitude spectrum as a distribution.
+type Descriptors struct {
+	Centroid float64 // center of mass of the spectrum
+	Spread   float64 // standard deviation around the centroid
+	Skewness float64 // asymmetry around the centroid
+	Kurtosis float64 // peakedness around the centroid
+	Rolloff  float64 // frequency below which the rolloff share of the energy lies
+	Flatness float64 // geometric mean / arithmetic mean of the power spectrum, 1 for white noise
+	Crest    float64 // maximum / arithmetic mean of the magnitude spectrum
+}
+
+// Series holds the descriptors of all STFT frames of a signal as time series,
+// one value per frame in each slice.
+type Series struct {
+	Time     []float64 // center of each frame in seconds
+	Centroid []float64
+	Spread   []float64
+	Skewness []float64
+	Kurtosis []float64
+	Rolloff  []float64
+	Flatness []float64
+	Crest    []float64
+}
+
+// Describe computes the spectral descriptors of the non-negative frequency
+// coefficients of an fftSize point FFT. rolloff is the energy share used for
+// the rolloff frequency, see DefaultRolloff.
+func Describe(spectrum []complex128, sampleRate, fftSize int, rolloff float64) Descriptors {
+	freqRes := float64(sampleRate) / float64(fftSize)
+	mag := make([]float64, len(spectrum))
+	sumMag, sumPower, maxMag := 0.0, 0.0, 0.0
+	for i, c := range spectrum {
+		mag[i] = cmplx.Abs(c)
+		sumMag += mag[i]
+		sumPower += mag[i] * mag[i]
+		maxMag = math.Max(maxMag, mag[i])
+	}
+	if sumMag == 0 {
+		return Descriptors{}
+	}
+
+	d := Descriptors{}
+	for i, m := range mag {
+		d.Centroid += float64(i) * freqRes * m
+	}
+	d.Centroid /= sumMag
+
+	m2, m3, m4 := 0.0, 0.0, 0.0
+	for i, m := range mag {
+		dev := float64(i)*freqRes - d.Centroid
+		m2 += dev * dev * m
+		m3 += dev * dev * dev * m
+		m4 += dev * dev * dev * dev * m
+	}
+	d.Spread = math.Sqrt(m2 / sumMag)
+	if d.Spread > 0 {
+		d.Skewness = m3 / sumMag / math.Pow(d.Spread, 3)
+		d.Kurtosis = m4 / sumMag / math.Pow(d.Spread, 4)
+	}
+
+	cumulative := 0.0
+	for i, m := range mag {
+		cumulative += m * m
+		if cumulative >= rolloff*sumPower {
+			d.Rolloff = float64(i) * freqRes
+			break
+		}
+	}
+
+	// Geometric mean in the log domain, silent bins make the spectrum maximally tonal
+	logSum := 0.0
+	for _, m := range mag {
+		logSum += math.Log(m*m + 1e-24)
+	}
+	n := float64(len(mag))
+	d.Flatness = math.Exp(logSum/n) / (sumPower / n)
+	d.Crest = maxMag / (sumMag / n)
+
+	return d
+}
+
+// Spectral computes the spectral descriptors of every STFT frame of samples
+func Spectral(samples []float64, sampleRate, frameSize, hopSize int, rolloff float64) Series {
+	frames := dft.STFT(samples, sampleRate, frameSize, hopSize)
+	s := Series{
+		Time:     make([]float64, len(frames)),
+		Centroid: make([]float64, len(frames)),
+		Spread:   make([]float64, len(frames)),
+		Skewness: make([]float64, len(frames)),
+		Kurtosis: make([]float64, len(frames)),
+		Rolloff:  make([]float64, len(frames)),
+		Flatness: make([]float64, len(frames)),
+		Crest:    make([]float64, len(frames)),
+	}
+	for i, frame := range frames {
+		d := Describe(frame.Spectrum, sampleRate, frameSize, rolloff)
+		s.Time[i] = frame.Time
+		s.Centroid[i] = d.Centroid
+		s.Spread[i] = d.Spread
+		s.Skewness[i] = d.Skewness
+		s.Kurtosis[i] = d.Kurtosis
+		s.Rolloff[i] = d.Rolloff
+		s.Flatness[i] = d.Flatness
+		s.Crest[i] = d.Crest
+	}
+	return s
+}
@@ -0,0 +1,37 @@
+// Package dft provides discrete fourier transform based signal analysis
+// helpers built on top of the Gonum DSP fourier package. Built with TinyGo or
+// the purego tag, the package has no dependencies and uses its own FFT.
+package dft
+
+// NextPowerOfTwo returns the smallest power of two that is >= n
+func NextPowerOfTwo(n int) int {
+	size := 1
+	for size < n {
+		size *= 2
+	}
+	return size
+}
+
+// NextFastLen returns the smallest 5-smooth number 2^a·3^b·5^c that is >= n.
+// The FFT of such lengths is about as fast as of a power of two, yet they
+// are denser: 48000 is one, while the next power of two is 65536.
+func NextFastLen(n int) int {
+	if n <= 6 {
+		return max(n, 1)
+	}
+	best := NextPowerOfTwo(n)
+	for p5 := 1; p5 < best; p5 *= 5 {
+		for p35 := p5; p35 < best; p35 *= 3 {
+			// The smallest multiple of p35 by a power of two that is >= n
+			m := p35
+			for m < n {
+				m *= 2
+			}
+			if m == n {
+				return n
+			}
+			best = min(best, m)
+		}
+	}
+	return best
+}
@@ -0,0 +1,45 @@
+//go:build !tinygo && !purego
+
+package dft
+
+import (
+	"gonum.org/v1/gonum/dsp/fourier"
+)
+
+// Forward computes the FFT of a real valued signal and returns the
+// len(samples)/2+1 coefficients of the non-negative frequencies.
+func Forward(samples []float64) []complex128 {
+	fft := fourier.NewFFT(len(samples))
+	return fft.Coefficients(nil, samples)
+}
+
+// Inverse computes the inverse FFT of the coefficients returned by Forward
+// for a real valued signal of length n. The result is normalized by n so that
+// Inverse(Forward(x), len(x)) reproduces x.
+func Inverse(coeffs []complex128, n int) []float64 {
+	fft := fourier.NewFFT(n)
+	seq := fft.Sequence(nil, coeffs)
+	for i := range seq {
+		seq[i] /= float64(n)
+	}
+	return seq
+}
+
+// ForwardComplex computes the FFT of a complex valued signal, e.g. I/Q samples,
+// and returns all len(samples) coefficients in FFT order: the non-negative
+// frequencies followed by the negative frequencies.
+func ForwardComplex(samples []complex128) []complex128 {
+	fft := fourier.NewCmplxFFT(len(samples))
+	return fft.Coefficients(nil, samples)
+}
+
+// InverseComplex computes the inverse FFT of the coefficients returned by
+// ForwardComplex, normalized by their number.
+func InverseComplex(coeffs []complex128) []complex128 {
+	fft := fourier.NewCmplxFFT(len(coeffs))
+	seq := fft.Sequence(nil, coeffs)
+	for i := range seq {
+		seq[i] /= complex(float64(len(coeffs)), 0)
+	}
+	return seq
+}
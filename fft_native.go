@@ -0,0 +1,178 @@
+package dft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// The FFTs of this file replace those of Gonum for TinyGo and the purego
+// build tag: radix-2 for powers of two and Bluestein's algorithm for any
+// other length. They keep no plans between calls, so memory is only
+// allocated for the transform at hand.
+
+// nativeForward is Forward without Gonum
+func nativeForward(samples []float64) []complex128 {
+	n := len(samples)
+	coeffs := make([]complex128, n/2+1)
+	if n == 0 {
+		return coeffs
+	}
+	if n%2 != 0 {
+		x := make([]complex128, n)
+		for i, v := range samples {
+			x[i] = complex(v, 0)
+		}
+		transform(x)
+		copy(coeffs, x)
+		return coeffs
+	}
+	// The even and odd samples as real and imaginary parts of a signal of
+	// half the length need a transform of only n/2 points
+	h := n / 2
+	z := make([]complex128, h)
+	for i := range z {
+		z[i] = complex(samples[2*i], samples[2*i+1])
+	}
+	transform(z)
+	for k := range coeffs {
+		a, b := z[k%h], cmplx.Conj(z[(h-k)%h])
+		even, odd := (a+b)/2, (a-b)/complex(0, 2)
+		coeffs[k] = even + twiddle(k, n)*odd
+	}
+	return coeffs
+}
+
+// nativeInverse is Inverse without Gonum
+func nativeInverse(coeffs []complex128, n int) []float64 {
+	seq := make([]float64, n)
+	if n == 0 {
+		return seq
+	}
+	if n%2 != 0 {
+		x := make([]complex128, n)
+		for k := range n/2 + 1 {
+			x[k] = coeffs[k]
+			if k > 0 {
+				x[n-k] = cmplx.Conj(coeffs[k])
+			}
+		}
+		inverseTransform(x)
+		for i, v := range x {
+			seq[i] = real(v) / float64(n)
+		}
+		return seq
+	}
+	// Undoes the split of Forward into the transforms of the even and odd
+	// samples
+	h := n / 2
+	z := make([]complex128, h)
+	for k := range z {
+		a, b := coeffs[k], cmplx.Conj(coeffs[h-k])
+		even, odd := (a+b)/2, (a-b)/2*cmplx.Conj(twiddle(k, n))
+		z[k] = even + complex(0, 1)*odd
+	}
+	inverseTransform(z)
+	for i, v := range z {
+		seq[2*i], seq[2*i+1] = real(v)/float64(h), imag(v)/float64(h)
+	}
+	return seq
+}
+
+// nativeForwardComplex is ForwardComplex without Gonum
+func nativeForwardComplex(samples []complex128) []complex128 {
+	coeffs := append([]complex128(nil), samples...)
+	transform(coeffs)
+	return coeffs
+}
+
+// nativeInverseComplex is InverseComplex without Gonum
+func nativeInverseComplex(coeffs []complex128) []complex128 {
+	seq := append([]complex128(nil), coeffs...)
+	inverseTransform(seq)
+	for i := range seq {
+		seq[i] /= complex(float64(len(seq)), 0)
+	}
+	return seq
+}
+
+// transform computes the unnormalized FFT of x in place
+func transform(x []complex128) {
+	n := len(x)
+	switch {
+	case n <= 1:
+	case n&(n-1) == 0:
+		radix2(x)
+	default:
+		bluestein(x)
+	}
+}
+
+// inverseTransform computes the unnormalized inverse FFT of x in place, as
+// the conjugate of the FFT of the conjugate
+func inverseTransform(x []complex128) {
+	for i, v := range x {
+		x[i] = cmplx.Conj(v)
+	}
+	transform(x)
+	for i, v := range x {
+		x[i] = cmplx.Conj(v)
+	}
+}
+
+// radix2 is the iterative decimation in time FFT of a power of two length
+func radix2(x []complex128) {
+	n := len(x)
+	for i, j := 0, 0; i < n; i++ {
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j |= bit
+	}
+	w := make([]complex128, n/2)
+	for k := range w {
+		w[k] = twiddle(k, n)
+	}
+	for size := 2; size <= n; size <<= 1 {
+		half, step := size/2, n/size
+		for start := 0; start < n; start += size {
+			for j := range half {
+				a, b := start+j, start+j+half
+				t := w[j*step] * x[b]
+				x[a], x[b] = x[a]+t, x[a]-t
+			}
+		}
+	}
+}
+
+// bluestein computes the FFT of any length as a convolution with a chirp,
+// which radix2 evaluates at a power of two length
+func bluestein(x []complex128) {
+	n := len(x)
+	m := NextPowerOfTwo(2*n - 1)
+	// chirp[k] = e^(-πi·k²/N), with k² reduced modulo 2N for exact angles
+	chirp := make([]complex128, n)
+	for k := range chirp {
+		chirp[k] = cmplx.Rect(1, -math.Pi*float64(k*k%(2*n))/float64(n))
+	}
+	a, b := make([]complex128, m), make([]complex128, m)
+	for k, v := range x {
+		a[k] = v * chirp[k]
+		b[k] = cmplx.Conj(chirp[k])
+		if k > 0 {
+			b[m-k] = b[k]
+		}
+	}
+	radix2(a)
+	radix2(b)
+	for i := range a {
+		a[i] *= b[i]
+	}
+	inverseTransform(a)
+	for k := range x {
+		x[k] = a[k] * chirp[k] / complex(float64(m), 0)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build purego
+
+package dft
+
+import "testing"
+
+// TestPuregoBackend checks that the purego tag selects the pure Go FFTs, so
+// go test -tags purego runs the Verify tests of the default backend on them
+func TestPuregoBackend(t *testing.T) {
+	x := []float64{1, -2, 3, 0.5, 7, -1, 0.25}
+	got, want := Forward(x), nativeForward(x)
+	for k := range want {
+		if got[k] != want[k] {
+			t.Fatalf("coefficient %d is %v instead of %v of the pure Go FFT", k, got[k], want[k])
+		}
+	}
+}
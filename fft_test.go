@@ -0,0 +1,105 @@
+package dft
+
+import (
+	"fmt"
+	"testing"
+)
+
+// backends are the FFTs of the build, Gonum unless built with TinyGo or the
+// purego tag, and the pure Go FFTs, which are tested in every build
+var backends = []struct {
+	name           string
+	forward        func([]float64) []complex128
+	inverse        func([]complex128, int) []float64
+	forwardComplex func([]complex128) []complex128
+}{
+	{"default", Forward, Inverse, ForwardComplex},
+	{"native", nativeForward, nativeInverse, nativeForwardComplex},
+}
+
+func TestVerifyForward(t *testing.T) {
+	for _, b := range backends {
+		if worst, err := VerifyForward(b.forward, DefaultVerifyOptions); err != nil {
+			t.Errorf("%s: %v", b.name, err)
+		} else {
+			t.Logf("%s: max. error %.3g", b.name, worst)
+		}
+	}
+}
+
+func TestVerifyInverse(t *testing.T) {
+	for _, b := range backends {
+		if worst, err := VerifyInverse(b.inverse, DefaultVerifyOptions); err != nil {
+			t.Errorf("%s: %v", b.name, err)
+		} else {
+			t.Logf("%s: max. error %.3g", b.name, worst)
+		}
+	}
+}
+
+func TestVerifyForwardComplex(t *testing.T) {
+	for _, b := range backends {
+		if worst, err := VerifyForwardComplex(b.forwardComplex, DefaultVerifyOptions); err != nil {
+			t.Errorf("%s: %v", b.name, err)
+		} else {
+			t.Logf("%s: max. error %.3g", b.name, worst)
+		}
+	}
+}
+
+func TestInverseComplex(t *testing.T) {
+	// There is no VerifyInverseComplex, the round trip must reproduce x
+	for _, b := range []struct {
+		name    string
+		forward func([]complex128) []complex128
+		inverse func([]complex128) []complex128
+	}{
+		{"default", ForwardComplex, InverseComplex},
+		{"native", nativeForwardComplex, nativeInverseComplex},
+	} {
+		for _, n := range DefaultVerifyOptions.Sizes {
+			x := make([]complex128, n)
+			for i := range x {
+				x[i] = complex(float64(i%7)-3, float64(i%5)-2)
+			}
+			e, err := compareCoefficients(b.inverse(b.forward(x)), x)
+			if err == nil && e > DefaultVerifyOptions.MaxErr {
+				err = fmt.Errorf("relative error %.3g", e)
+			}
+			if err != nil {
+				t.Errorf("%s, size %d: %v", b.name, n, err)
+			}
+		}
+	}
+}
+
+// TestVerifyNormalization checks that the harness catches a missing or
+// doubled 1/N normalization
+func TestVerifyNormalization(t *testing.T) {
+	scaled := func(gain float64) func([]complex128, int) []float64 {
+		return func(coeffs []complex128, n int) []float64 {
+			seq := Inverse(coeffs, n)
+			for i := range seq {
+				seq[i] *= gain
+			}
+			return seq
+		}
+	}
+	opts := DefaultVerifyOptions
+	opts.Sizes = []int{2, 3, 16, 17}
+	for _, gain := range []float64{0.5, 2} {
+		if _, err := VerifyInverse(scaled(gain), opts); err == nil {
+			t.Errorf("an inverse scaled by %g passes", gain)
+		}
+	}
+	doubled := func(x []float64) []complex128 {
+		coeffs := Forward(x)
+		for k := range coeffs {
+			coeffs[k] *= 2
+		}
+		return coeffs
+	}
+	if _, err := VerifyForward(doubled, opts); err == nil {
+		t.Error("a forward transform scaled by 2 passes")
+	}
+}
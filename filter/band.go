@@ -0,0 +1,77 @@
+package filter
+
+import (
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// BandMode selects whether FilterBand keeps or removes the band
+type BandMode int
+
+const (
+	RemoveBand BandMode = iota // attenuate the band, keep everything else
+	KeepBand                   // keep the band, attenuate everything else
+)
+
+// BandOptions configures FilterBand
+type BandOptions struct {
+	Mode BandMode
+
+	// Gain applied to the attenuated bins, 0 removes them completely
+	Gain float64
+
+	// TransitionHz is the width of raised-cosine transitions centered on the
+	// band edges. A brick-wall filter (0) causes ringing in the time domain.
+	TransitionHz float64
+}
+
+// FilterBand filters samples in the frequency domain: the whole signal is
+// transformed, the bins between lowHz and highHz (or outside of it, depending
+// on the mode) are attenuated and the signal is transformed back.
+func FilterBand(samples []float64, sampleRate int, lowHz, highHz float64, opts BandOptions) []float64 {
+	if len(samples) == 0 {
+		return []float64{}
+	}
+	coeffs := dft.Forward(samples)
+	FilterSpectrumBand(coeffs, sampleRate, len(samples), lowHz, highHz, opts)
+	return dft.Inverse(coeffs, len(samples))
+}
+
+// FilterSpectrumBand applies the band filter of FilterBand in place to the
+// coefficients of the non-negative frequencies of an fftSize point FFT
+func FilterSpectrumBand(coeffs []complex128, sampleRate, fftSize int, lowHz, highHz float64, opts BandOptions) {
+	freqRes := float64(sampleRate) / float64(fftSize)
+	for k := range coeffs {
+		inBand := bandMembership(float64(k)*freqRes, lowHz, highHz, opts.TransitionHz)
+		keep := 1 - inBand
+		if opts.Mode == KeepBand {
+			keep = inBand
+		}
+		g := opts.Gain + (1-opts.Gain)*keep
+		coeffs[k] *= complex(g, 0)
+	}
+}
+
+// bandMembership returns 1 inside the band, 0 outside and a raised-cosine
+// transition of width transition centered on each edge
+func bandMembership(f, lowHz, highHz, transition float64) float64 {
+	return edge(f-lowHz, transition) * edge(highHz-f, transition)
+}
+
+// edge is a smooth step from 0 (d < -transition/2) to 1 (d > transition/2)
+func edge(d, transition float64) float64 {
+	if transition <= 0 {
+		if d >= 0 {
+			return 1
+		}
+		return 0
+	}
+	if d <= -transition/2 {
+		return 0
+	}
+	if d >= transition/2 {
+		return 1
+	}
+	return 0.5 * (1 + math.Sin(math.Pi*d/transition))
+}
@@ -0,0 +1,45 @@
+package filter
+
+import "fmt"
+
+// DefaultPreEmphasis is the customary pre-emphasis coefficient for speech
+// feature extraction
+const DefaultPreEmphasis = 0.97
+
+// PreEmphasisFilter returns the first order high-pass
+// y[n] = x[n] - alpha·x[n-1], which flattens the spectral tilt of speech by
+// raising the level at high frequencies relative to DC by
+// 20·log10((1+alpha)/(1-alpha)) dB, 36 dB for 0.97
+func PreEmphasisFilter(alpha float64) FIR {
+	return FIR{1, -alpha}
+}
+
+// PreEmphasis returns x filtered with PreEmphasisFilter. alpha must be in
+// [0..1), 0 returns a copy of x.
+func PreEmphasis(x []float64, alpha float64) ([]float64, error) {
+	if alpha < 0 || alpha >= 1 {
+		return nil, fmt.Errorf("invalid pre-emphasis coefficient %g, must be in [0..1)", alpha)
+	}
+	y := make([]float64, len(x))
+	for n, v := range x {
+		y[n] = v
+		if n > 0 {
+			y[n] -= alpha * x[n-1]
+		}
+	}
+	return y, nil
+}
+
+// DeEmphasis inverts PreEmphasis with y[n] = x[n] + alpha·y[n-1]
+func DeEmphasis(x []float64, alpha float64) ([]float64, error) {
+	if alpha < 0 || alpha >= 1 {
+		return nil, fmt.Errorf("invalid pre-emphasis coefficient %g, must be in [0..1)", alpha)
+	}
+	y := make([]float64, len(x))
+	prev := 0.0
+	for n, v := range x {
+		y[n] = v + alpha*prev
+		prev = y[n]
+	}
+	return y, nil
+}
@@ -0,0 +1,102 @@
+package filter
+
+import (
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Filter is a causal linear filter
+type Filter interface {
+	Filter(x []float64) []float64 // returns the filtered signal, starting at rest
+	Order() int
+}
+
+var (
+	_ Filter = FIR{}
+	_ Filter = dft.SOS{}
+)
+
+// FIR is a FIR filter given by its taps, e.g. designed with DesignFIR or Remez
+type FIR []float64
+
+// Filter returns the filtered signal with the same length as x
+func (f FIR) Filter(x []float64) []float64 {
+	if len(x) == 0 || len(f) == 0 {
+		return make([]float64, len(x))
+	}
+	return dft.ConvolveOA(x, f)[:len(x)]
+}
+
+// Order returns the order of the filter
+func (f FIR) Order() int {
+	return len(f) - 1
+}
+
+// filtFiltDecay is the level to which the impulse response of an IIR filter
+// must decay within the padding of FiltFilt
+const filtFiltDecay = 1e-3
+
+// FiltFilt applies f forward and backward to x, which squares its magnitude
+// response and cancels its phase response, so the result has zero phase
+// distortion. To reduce transients at the edges, x is extended by an odd
+// reflection at both ends before filtering and each pass starts in the steady
+// state of its first sample. The reflection covers 3*(order+1) samples and for
+// an SOS at least the length in which its impulse response decays to 1e-3,
+// e.g. that of a narrow notch, but not more than len(x)-1 samples.
+func FiltFilt(f Filter, x []float64) []float64 {
+	n := len(x)
+	if n == 0 {
+		return []float64{}
+	}
+	pad := 3 * (f.Order() + 1)
+	if sos, ok := f.(dft.SOS); ok {
+		if decay := sos.DecayLength(filtFiltDecay); decay > pad {
+			pad = decay
+		}
+	}
+	if pad > n-1 {
+		pad = n - 1
+	}
+
+	// Odd extension: 2*x[0] - x[pad..1], x, 2*x[n-1] - x[n-2..n-1-pad]
+	ext := make([]float64, 0, n+2*pad)
+	for i := pad; i >= 1; i-- {
+		ext = append(ext, 2*x[0]-x[i])
+	}
+	ext = append(ext, x...)
+	for i := n - 2; i >= n-1-pad; i-- {
+		ext = append(ext, 2*x[n-1]-x[i])
+	}
+
+	y := filterFrom(f, ext)
+	reverse(y)
+	y = filterFrom(f, y)
+	reverse(y)
+	return y[pad : pad+n]
+}
+
+// filterFrom applies f to x starting in the steady state of the constant
+// input x[0], see dft.SOS.FilterFrom. Other filters than FIR and SOS start at
+// rest.
+func filterFrom(f Filter, x []float64) []float64 {
+	switch f := f.(type) {
+	case dft.SOS:
+		return f.FilterFrom(x, x[0])
+	case FIR:
+		if len(f) < 2 {
+			break
+		}
+		// The FIR has settled after len(f)-1 samples of x[0]
+		settle := make([]float64, len(f)-1, len(f)-1+len(x))
+		for i := range settle {
+			settle[i] = x[0]
+		}
+		return f.Filter(append(settle, x...))[len(settle):]
+	}
+	return f.Filter(x)
+}
+
+func reverse(x []float64) {
+	for i, j := 0, len(x)-1; i < j; i, j = i+1, j-1 {
+		x[i], x[j] = x[j], x[i]
+	}
+}
@@ -0,0 +1,25 @@
+package filter
+
+import (
+	"math"
+	"testing"
+)
+
+// TestFiltFiltSteadyState checks that a narrow notch passes a signal with an
+// offset without step transients at the edges
+func TestFiltFiltSteadyState(t *testing.T) {
+	notch, err := Notch(50, 30, 8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := make([]float64, 4000)
+	for i := range x {
+		x[i] = 1 + 1e-3*math.Sin(2*math.Pi*1000*float64(i)/8000)
+	}
+	y := FiltFilt(notch, x)
+	for i := range y {
+		if math.Abs(y[i]-x[i]) > 1e-4 {
+			t.Fatalf("sample %d is %g instead of %g", i, y[i], x[i])
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Package filter implements the design and application of FIR and IIR filters.
+package filter
+
+import (
+	"fmt"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// BandType is the kind of frequency band a filter passes
+type BandType int
+
+const (
+	LowPass BandType = iota
+	HighPass
+	BandPass
+	BandStop
+)
+
+func (b BandType) String() string {
+	switch b {
+	case HighPass:
+		return "high-pass"
+	case BandPass:
+		return "band-pass"
+	case BandStop:
+		return "band-stop"
+	}
+	return "low-pass"
+}
+
+// FIRSpec describes a FIR filter for DesignFIR
+type FIRSpec struct {
+	Band         BandType
+	SampleRate   int
+	CutoffHz     float64    // cutoff of low- and high-pass filters, lower edge of band filters
+	CutoffHighHz float64    // upper edge of band-pass and band-stop filters
+	TransitionHz float64    // width of the transition band(s), determines the number of taps
+	Window       dft.Window // window applied to the ideal impulse response
+}
+
+// DesignFIR designs a linear phase FIR filter with the windowed-sinc method and
+// returns its taps, ready for dft.ConvolveOA or dft.NewConvolver.
+//
+// The number of taps is derived from the transition width and the main lobe
+// width of the window and is always odd, so the filter has an integer group
+// delay of (len(taps)-1)/2 samples. The window determines the stopband
+// attenuation: about 44 dB for Hanning, 53 dB for Hamming, 74 dB for Blackman
+// and freely adjustable for Kaiser.
+func DesignFIR(spec FIRSpec) ([]float64, error) {
+	nyquist := float64(spec.SampleRate) / 2
+	if spec.SampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate %d", spec.SampleRate)
+	}
+	if spec.TransitionHz <= 0 {
+		return nil, fmt.Errorf("invalid transition width %.2f Hz", spec.TransitionHz)
+	}
+	if spec.CutoffHz <= 0 || spec.CutoffHz >= nyquist {
+		return nil, fmt.Errorf("cutoff %.2f Hz is out of range (0..%.2f)", spec.CutoffHz, nyquist)
+	}
+	if (spec.Band == BandPass || spec.Band == BandStop) &&
+		(spec.CutoffHighHz <= spec.CutoffHz || spec.CutoffHighHz >= nyquist) {
+		return nil, fmt.Errorf("upper cutoff %.2f Hz is out of range (%.2f..%.2f)", spec.CutoffHighHz, spec.CutoffHz, nyquist)
+	}
+
+	taps := int(math.Ceil(spec.Window.TransitionWidth() * float64(spec.SampleRate) / spec.TransitionHz))
+	if taps%2 == 0 {
+		taps++
+	}
+
+	fc1 := spec.CutoffHz / float64(spec.SampleRate)
+	fc2 := spec.CutoffHighHz / float64(spec.SampleRate)
+	var h []float64
+	switch spec.Band {
+	case LowPass:
+		h = lowPassSinc(taps, fc1)
+	case HighPass:
+		h = invert(lowPassSinc(taps, fc1))
+	case BandPass:
+		h = subtract(lowPassSinc(taps, fc2), lowPassSinc(taps, fc1))
+	case BandStop:
+		h = invert(subtract(lowPassSinc(taps, fc2), lowPassSinc(taps, fc1)))
+	default:
+		return nil, fmt.Errorf("unknown band type %d", spec.Band)
+	}
+	spec.Window.Apply(h)
+
+	// Normalize to unity gain in the passband
+	var ref float64
+	switch spec.Band {
+	case LowPass, BandStop:
+		ref = 0
+	case HighPass:
+		ref = 0.5
+	case BandPass:
+		ref = (fc1 + fc2) / 2
+	}
+	gain := firGain(h, ref)
+	for i := range h {
+		h[i] /= gain
+	}
+	return h, nil
+}
+
+// lowPassSinc is the ideal low-pass impulse response with the normalized cutoff fc
+func lowPassSinc(taps int, fc float64) []float64 {
+	h := make([]float64, taps)
+	m := float64(taps-1) / 2
+	for i := range h {
+		x := float64(i) - m
+		if x == 0 {
+			h[i] = 2 * fc
+		} else {
+			h[i] = math.Sin(2*math.Pi*fc*x) / (math.Pi * x)
+		}
+	}
+	return h
+}
+
+// invert turns a low-pass into a high-pass (or band-pass into band-stop) by
+// spectral inversion
+func invert(h []float64) []float64 {
+	for i := range h {
+		h[i] = -h[i]
+	}
+	h[len(h)/2] += 1
+	return h
+}
+
+func subtract(a, b []float64) []float64 {
+	for i := range a {
+		a[i] -= b[i]
+	}
+	return a
+}
+
+// firGain returns the magnitude response of h at the normalized frequency f
+func firGain(h []float64, f float64) float64 {
+	re, im := 0.0, 0.0
+	for i, v := range h {
+		re += v * math.Cos(2*math.Pi*f*float64(i))
+		im -= v * math.Sin(2*math.Pi*f*float64(i))
+	}
+	return math.Hypot(re, im)
+}
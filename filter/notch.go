@@ -0,0 +1,144 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Hum detection parameters
+const (
+	humHarmonics   = 3    // harmonics of the mains frequency checked by DetectHum
+	humSearchHz    = 1.5  // tolerance of the mains frequency
+	humMinAboveDB  = 10.0 // minimum height of the fundamental above the noise floor
+	humFloorWindow = 50.0 // width of the noise floor filter in Hz
+)
+
+// Notch returns a second order IIR notch filter at freq Hz. The quality factor q
+// is freq divided by the -3 dB bandwidth, higher values give narrower notches.
+func Notch(freq, q float64, sampleRate int) (dft.SOS, error) {
+	if freq <= 0 || freq >= float64(sampleRate)/2 {
+		return nil, fmt.Errorf("notch frequency %.2f Hz is out of range", freq)
+	}
+	if q <= 0 {
+		return nil, fmt.Errorf("invalid quality factor %.2f", q)
+	}
+
+	// Audio EQ cookbook (R. Bristow-Johnson)
+	w0 := 2 * math.Pi * freq / float64(sampleRate)
+	alpha := math.Sin(w0) / (2 * q)
+	a0 := 1 + alpha
+	return dft.SOS{{
+		B0: 1 / a0,
+		B1: -2 * math.Cos(w0) / a0,
+		B2: 1 / a0,
+		A1: -2 * math.Cos(w0) / a0,
+		A2: (1 - alpha) / a0,
+	}}, nil
+}
+
+// DetectHum checks samples for mains hum at 50 Hz and 60 Hz including their
+// harmonics. It returns the mains frequency with the stronger hum and how far
+// its fundamental rises above the noise floor in dB, or 0 if no hum was found.
+func DetectHum(samples []float64, sampleRate int) (mainsHz float64, levelDB float64) {
+	s := dft.ComputeSpectrum(samples, sampleRate)
+	floor := s.NoiseFloor(humFloorWindow, 0.5)
+
+	bestScore := 0.0
+	for _, mains := range []float64{50, 60} {
+		score, fundamental := 0.0, 0.0
+		for h := 1; h <= humHarmonics; h++ {
+			above := humAboveFloor(s, floor, mains*float64(h))
+			if h == 1 {
+				fundamental = above
+			}
+			score += math.Max(0, above)
+		}
+		if fundamental >= humMinAboveDB && score > bestScore {
+			bestScore, mainsHz, levelDB = score, mains, fundamental
+		}
+	}
+	return mainsHz, levelDB
+}
+
+// humAboveFloor returns the height of the strongest bin near freq above the noise floor in dB
+func humAboveFloor(s dft.Spectrum, floor []float64, freq float64) float64 {
+	lo := int(math.Floor((freq - humSearchHz) / s.FreqRes()))
+	hi := int(math.Ceil((freq + humSearchHz) / s.FreqRes()))
+	best := math.Inf(-1)
+	for k := lo; k <= hi; k++ {
+		if k < 1 || k >= len(s.Magnitude) {
+			continue
+		}
+		db := 20 * math.Log10((s.Magnitude[k]+1e-12)/(floor[k]+1e-12))
+		best = math.Max(best, db)
+	}
+	return best
+}
+
+// RemoveHum detects mains hum with DetectHum and removes it with zero-phase
+// notch filters at the mains frequency and its harmonics below the Nyquist
+// frequency. If no hum is found samples are returned unchanged and mainsHz is 0.
+func RemoveHum(samples []float64, sampleRate, harmonics int, q float64) (out []float64, mainsHz float64, err error) {
+	mainsHz, _ = DetectHum(samples, sampleRate)
+	if mainsHz == 0 {
+		return samples, 0, nil
+	}
+
+	notches := dft.SOS{}
+	for h := 1; h <= harmonics; h++ {
+		freq := mainsHz * float64(h)
+		if freq >= float64(sampleRate)/2 {
+			break
+		}
+		notch, err := Notch(freq, q, sampleRate)
+		if err != nil {
+			return nil, 0, err
+		}
+		notches = append(notches, notch...)
+	}
+	// The reflection of FiltFilt shifts the phase of the hum, the narrow
+	// notches would ring from there into the signal. A periodic extension by
+	// whole mains periods continues the hum instead, so only the transients
+	// of the other content remain, which the notches barely respond to.
+	n := len(samples)
+	pad := notches.DecayLength(filtFiltDecay)
+	if pad < 0 {
+		return nil, 0, fmt.Errorf("unstable notch filter, quality factor %.2f is too high", q)
+	}
+	period := humPeriod(n, sampleRate, mainsHz)
+	ext := make([]float64, 0, n+2*pad)
+	for i := -pad; i < 0; i++ {
+		ext = append(ext, samples[(i%period+period)%period])
+	}
+	ext = append(ext, samples...)
+	for i := 0; i < pad; i++ {
+		ext = append(ext, samples[n-period+i%period])
+	}
+	return FiltFilt(notches, ext)[pad : pad+n], mainsHz, nil
+}
+
+// humPeriod returns the length of the periodic extension of RemoveHum for
+// n samples: the longest multiple of the smallest whole number of samples
+// that spans whole periods of mainsHz, e.g. 882 samples for 50 Hz at 44.1 kHz.
+// If n is shorter, it is the nearest whole number of samples of single periods.
+func humPeriod(n, sampleRate int, mainsHz float64) int {
+	mains := int(mainsHz)
+	exact := sampleRate / gcd(sampleRate, mains)
+	if n >= exact {
+		return n / exact * exact
+	}
+	period := int(math.Round(float64(sampleRate) / mainsHz))
+	if n < period {
+		return n
+	}
+	return n / period * period
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,48 @@
+package filter
+
+import (
+	"math"
+	"testing"
+)
+
+// TestRemoveHumEdges checks that the hum is removed at the edges of the
+// signal as well as in the middle
+func TestRemoveHumEdges(t *testing.T) {
+	const sampleRate = 8000
+	tone := make([]float64, 4*sampleRate)
+	x := make([]float64, len(tone))
+	for i := range x {
+		ts := float64(i) / sampleRate
+		tone[i] = 0.1 * math.Sin(2*math.Pi*730*ts)
+		x[i] = tone[i] + 0.5*math.Sin(2*math.Pi*50*ts+0.7) + 0.1*math.Sin(2*math.Pi*150*ts+0.3)
+	}
+	out, mainsHz, err := RemoveHum(x, sampleRate, 10, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mainsHz != 50 {
+		t.Fatalf("detected %g Hz mains hum, want 50 Hz", mainsHz)
+	}
+
+	// Residual hum relative to the input in 100 ms segments
+	levelDB := func(start int) float64 {
+		var res, in float64
+		for i := start; i < start+sampleRate/10; i++ {
+			res += (out[i] - tone[i]) * (out[i] - tone[i])
+			in += x[i] * x[i]
+		}
+		return 10 * math.Log10(res/in)
+	}
+	for _, c := range []struct {
+		name  string
+		start int
+	}{
+		{"start", 0},
+		{"middle", len(x) / 2},
+		{"end", len(x) - sampleRate/10},
+	} {
+		if db := levelDB(c.start); db > -50 {
+			t.Errorf("residual hum at the %s is %.1f dB", c.name, db)
+		}
+	}
+}
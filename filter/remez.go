@@ -0,0 +1,243 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Band is a frequency band of an equiripple filter specification
+type Band struct {
+	LowHz, HighHz float64
+	Gain          float64 // desired amplitude in the band, e.g. 1 for pass and 0 for stop bands
+	Weight        float64 // relative weight of the error, the ripple is inversely proportional to it
+}
+
+// Remez iteration limits
+const (
+	remezGridDensity   = 16
+	remezMaxIterations = 40
+)
+
+// Remez designs a linear phase FIR filter with numTaps taps (odd) whose
+// amplitude response approximates the bands with an equiripple error, using the
+// Parks–McClellan algorithm. The bands must be sorted and separated by gaps,
+// which are the transition bands.
+//
+// To reach a passband ripple δp and a stopband ripple δs, weight the bands
+// with 1/δp and 1/δs. EstimateRemezTaps estimates the number of taps.
+func Remez(numTaps int, bands []Band, sampleRate int) ([]float64, error) {
+	if numTaps < 3 || numTaps%2 == 0 {
+		return nil, fmt.Errorf("number of taps must be odd and >= 3, got %d", numTaps)
+	}
+	if len(bands) == 0 {
+		return nil, fmt.Errorf("no bands specified")
+	}
+	nyquist := float64(sampleRate) / 2
+	for i, b := range bands {
+		if b.LowHz < 0 || b.HighHz > nyquist || b.HighHz <= b.LowHz || b.Weight <= 0 {
+			return nil, fmt.Errorf("invalid band %d: %.2f..%.2f Hz, weight %.2f", i, b.LowHz, b.HighHz, b.Weight)
+		}
+		// Bands that touch share a grid frequency with two desired values,
+		// which makes the interpolation singular
+		if i > 0 && b.LowHz <= bands[i-1].HighHz {
+			return nil, fmt.Errorf("band %d must start above the end of the previous band, a transition band is needed between them", i)
+		}
+	}
+
+	L := (numTaps - 1) / 2
+	grid, desired, weight := remezGrid(bands, sampleRate, L)
+	r := L + 2
+	if len(grid) < r {
+		return nil, fmt.Errorf("frequency grid too small")
+	}
+
+	// Initial extremal frequencies, evenly spread over the grid
+	ext := make([]int, r)
+	for i := range ext {
+		ext[i] = i * (len(grid) - 1) / (r - 1)
+	}
+
+	var interp remezInterpolator
+	for iter := 0; iter < remezMaxIterations; iter++ {
+		interp = newRemezInterpolator(grid, desired, weight, ext)
+
+		// Error on the dense grid
+		errs := make([]float64, len(grid))
+		maxErr := 0.0
+		for i, w := range grid {
+			errs[i] = weight[i] * (desired[i] - interp.eval(math.Cos(w)))
+			maxErr = math.Max(maxErr, math.Abs(errs[i]))
+		}
+
+		next := remezExtrema(errs, r)
+		if len(next) < r {
+			break
+		}
+		ext = next
+		if maxErr-math.Abs(interp.delta) <= 1e-6*maxErr {
+			break
+		}
+	}
+
+	// Frequency sampling of the amplitude response gives the impulse response
+	n := float64(numTaps)
+	amp := make([]float64, L+1)
+	for k := range amp {
+		amp[k] = interp.eval(math.Cos(2 * math.Pi * float64(k) / n))
+	}
+	h := make([]float64, numTaps)
+	for i := range h {
+		sum := amp[0]
+		for k := 1; k <= L; k++ {
+			sum += 2 * amp[k] * math.Cos(2*math.Pi*float64(k)*float64(i-L)/n)
+		}
+		h[i] = sum / n
+	}
+	return h, nil
+}
+
+// EstimateRemezTaps estimates the number of taps (odd) that Remez needs for a
+// filter with the given passband ripple and stopband attenuation in dB and
+// transition width, using Kaiser's formula.
+func EstimateRemezTaps(passRippleDB, stopAttenuationDB, transitionHz float64, sampleRate int) int {
+	dp := (math.Pow(10, passRippleDB/20) - 1) / (math.Pow(10, passRippleDB/20) + 1)
+	ds := math.Pow(10, -stopAttenuationDB/20)
+	taps := int(math.Ceil((-20*math.Log10(math.Sqrt(dp*ds))-13)/(14.6*transitionHz/float64(sampleRate)))) + 1
+	if taps < 3 {
+		taps = 3
+	}
+	if taps%2 == 0 {
+		taps++
+	}
+	return taps
+}
+
+// remezGrid returns the dense grid of angular frequencies covering the bands
+// with the desired amplitude and weight at each point
+func remezGrid(bands []Band, sampleRate, L int) (grid, desired, weight []float64) {
+	total := 0.0
+	for _, b := range bands {
+		total += b.HighHz - b.LowHz
+	}
+	points := remezGridDensity * (L + 1)
+	for _, b := range bands {
+		n := int(math.Ceil(float64(points)*(b.HighHz-b.LowHz)/total)) + 1
+		for i := 0; i < n; i++ {
+			f := b.LowHz + (b.HighHz-b.LowHz)*float64(i)/float64(n-1)
+			grid = append(grid, 2*math.Pi*f/float64(sampleRate))
+			desired = append(desired, b.Gain)
+			weight = append(weight, b.Weight)
+		}
+	}
+	return grid, desired, weight
+}
+
+// remezInterpolator is the barycentric Lagrange interpolation of the amplitude
+// response through the current extremal frequencies
+type remezInterpolator struct {
+	delta float64
+	x     []float64 // cos(ω) of the interpolation points
+	c     []float64 // amplitude at the interpolation points
+	d     []float64 // barycentric weights
+}
+
+func newRemezInterpolator(grid, desired, weight []float64, ext []int) remezInterpolator {
+	r := len(ext)
+	x := make([]float64, r)
+	for i, e := range ext {
+		x[i] = math.Cos(grid[e])
+	}
+
+	b := barycentricWeights(x)
+	num, den := 0.0, 0.0
+	sign := 1.0
+	for k, e := range ext {
+		num += b[k] * desired[e]
+		den += sign * b[k] / weight[e]
+		sign = -sign
+	}
+	delta := num / den
+
+	// Interpolate through all but the last extremal frequency
+	c := make([]float64, r-1)
+	sign = 1.0
+	for k := 0; k < r-1; k++ {
+		e := ext[k]
+		c[k] = desired[e] - sign*delta/weight[e]
+		sign = -sign
+	}
+	return remezInterpolator{
+		delta: delta,
+		x:     x[:r-1],
+		c:     c,
+		d:     barycentricWeights(x[:r-1]),
+	}
+}
+
+func (p remezInterpolator) eval(x float64) float64 {
+	num, den := 0.0, 0.0
+	for k, xk := range p.x {
+		diff := x - xk
+		if math.Abs(diff) < 1e-14 {
+			return p.c[k]
+		}
+		t := p.d[k] / diff
+		num += t * p.c[k]
+		den += t
+	}
+	return num / den
+}
+
+// barycentricWeights returns 1/Π(x_k - x_i) for each k, scaled to avoid overflow
+func barycentricWeights(x []float64) []float64 {
+	w := make([]float64, len(x))
+	for k := range x {
+		prod := 1.0
+		for i := range x {
+			if i != k {
+				prod *= 2 * (x[k] - x[i])
+			}
+		}
+		w[k] = 1 / prod
+	}
+	return w
+}
+
+// remezExtrema returns r alternating extrema of the error, or fewer if the
+// error does not alternate often enough
+func remezExtrema(errs []float64, r int) []int {
+	candidates := []int{}
+	for i := range errs {
+		e := errs[i]
+		left := i == 0 || math.Abs(e) >= math.Abs(errs[i-1]) || (e > 0) != (errs[i-1] > 0)
+		right := i == len(errs)-1 || math.Abs(e) >= math.Abs(errs[i+1]) || (e > 0) != (errs[i+1] > 0)
+		if left && right {
+			candidates = append(candidates, i)
+		}
+	}
+
+	// Keep the larger of neighbouring extrema with the same sign
+	alternating := []int{}
+	for _, i := range candidates {
+		n := len(alternating)
+		if n > 0 && (errs[alternating[n-1]] > 0) == (errs[i] > 0) {
+			if math.Abs(errs[i]) > math.Abs(errs[alternating[n-1]]) {
+				alternating[n-1] = i
+			}
+			continue
+		}
+		alternating = append(alternating, i)
+	}
+
+	// Drop the smaller end until r extrema are left, which keeps the alternation
+	for len(alternating) > r {
+		if math.Abs(errs[alternating[0]]) < math.Abs(errs[alternating[len(alternating)-1]]) {
+			alternating = alternating[1:]
+		} else {
+			alternating = alternating[:len(alternating)-1]
+		}
+	}
+	sort.Ints(alternating)
+	return alternating
+}
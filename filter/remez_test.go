@@ -0,0 +1,34 @@
+package filter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRemez(t *testing.T) {
+	bands := []Band{
+		{LowHz: 0, HighHz: 1000, Gain: 1, Weight: 1},
+		{LowHz: 1500, HighHz: 4000, Gain: 0, Weight: 10},
+	}
+	h, err := Remez(51, bands, 8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range h {
+		if math.IsNaN(v) || math.Abs(v-h[len(h)-1-i]) > 1e-12 {
+			t.Fatalf("tap %d is %g, tap %d is %g", i, v, len(h)-1-i, h[len(h)-1-i])
+		}
+	}
+	if gain := firGain(h, 500.0/8000); math.Abs(gain-1) > 0.05 {
+		t.Errorf("passband gain %.3f", gain)
+	}
+	if gain := firGain(h, 2500.0/8000); gain > 0.01 {
+		t.Errorf("stopband gain %.4f", gain)
+	}
+
+	// Touching bands leave no transition band
+	bands[1].LowHz = 1000
+	if _, err := Remez(51, bands, 8000); err == nil {
+		t.Error("no error for bands without a transition band")
+	}
+}
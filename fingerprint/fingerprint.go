@@ -0,0 +1,226 @@
+// Package fingerprint identifies recordings by landmarks in their
+// spectrogram, as popularized by Shazam. The strongest local maxima of the
+// spectrogram form a constellation map; pairs of nearby peaks are hashed from
+// their frequencies and their distance in time. The hashes survive noise,
+// lossy coding and level changes, and a match is a set of hashes that agree
+// on the time offset between query and reference.
+package fingerprint
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/resample"
+)
+
+// Options configures the constellation map and the hashing of its peaks.
+// Fingerprints are only comparable if they were computed with the same
+// options.
+type Options struct {
+	SampleRate int // rate the audio is resampled to before the analysis
+	FrameSize  int // STFT frame size in samples at SampleRate, at most 2048
+	HopSize    int // STFT hop size in samples at SampleRate
+
+	TimeRadius int     // a peak is the maximum within ±TimeRadius frames...
+	FreqRadius int     // ...and ±FreqRadius bins
+	RangeDB    float64 // peaks more than RangeDB below the loudest bin are ignored
+	Density    int     // largest number of peaks per second, the strongest are kept
+
+	FanOut   int // number of later peaks each anchor peak is paired with
+	MaxDelta int // largest distance of a pair in frames, at most 4095
+	MaxBins  int // largest frequency distance of a pair in bins
+}
+
+// DefaultOptions analyzes audio at 11025 Hz with 93 ms frames and 23 ms
+// hops, which keeps the spectral peaks of music below 5.5 kHz
+var DefaultOptions = Options{
+	SampleRate: 11025,
+	FrameSize:  1024,
+	HopSize:    256,
+	TimeRadius: 10,
+	FreqRadius: 10,
+	RangeDB:    60,
+	Density:    30,
+	FanOut:     10,
+	MaxDelta:   64,
+	MaxBins:    128,
+}
+
+// Seconds returns the time in seconds of a frame offset
+func (o Options) Seconds(frame int) float64 {
+	return float64(frame*o.HopSize) / float64(o.SampleRate)
+}
+
+func (o Options) validate() error {
+	if o.SampleRate <= 0 || o.FrameSize < 2 || o.FrameSize > 2048 || o.HopSize <= 0 {
+		return fmt.Errorf("invalid sample rate %d, frame size %d or hop size %d", o.SampleRate, o.FrameSize, o.HopSize)
+	}
+	if o.MaxDelta < 1 || o.MaxDelta > 4095 || o.FanOut < 1 {
+		return fmt.Errorf("invalid fan-out %d or pair distance %d", o.FanOut, o.MaxDelta)
+	}
+	return nil
+}
+
+// Peak is a point of the constellation map
+type Peak struct {
+	Frame int     // STFT frame
+	Bin   int     // frequency bin
+	Level float64 // log magnitude
+}
+
+// Hash identifies a pair of peaks: 10 bits for the frequency bin of each peak
+// and 12 bits for their distance in frames
+type Hash uint32
+
+func newHash(anchor, target Peak) Hash {
+	return Hash(anchor.Bin)<<22 | Hash(target.Bin)<<12 | Hash(target.Frame-anchor.Frame)
+}
+
+// Fingerprint is a hash with the frame of its anchor peak
+type Fingerprint struct {
+	Hash   Hash
+	Offset int // frame of the anchor peak, see Options.Seconds
+}
+
+// Compute returns the fingerprints of a recording ordered by offset
+func Compute(samples []float64, sampleRate int, opts Options) ([]Fingerprint, error) {
+	peaks, err := Constellation(samples, sampleRate, opts)
+	if err != nil {
+		return nil, err
+	}
+	return Hashes(peaks, opts), nil
+}
+
+// Constellation returns the spectral peaks of a recording ordered by frame
+// and bin
+func Constellation(samples []float64, sampleRate int, opts Options) ([]Peak, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	if sampleRate != opts.SampleRate {
+		var err error
+		if samples, err = resample.Resample(samples, sampleRate, opts.SampleRate); err != nil {
+			return nil, err
+		}
+	}
+	frames := dft.STFT(samples, opts.SampleRate, opts.FrameSize, opts.HopSize)
+	if len(frames) == 0 {
+		return nil, nil
+	}
+
+	bins := len(frames[0].Spectrum)
+	levels := make([]float64, len(frames)*bins)
+	loudest := math.Inf(-1)
+	for t, f := range frames {
+		for k, c := range f.Spectrum {
+			v := math.Log(real(c)*real(c) + imag(c)*imag(c) + 1e-20)
+			levels[t*bins+k] = v
+			loudest = math.Max(loudest, v)
+		}
+	}
+	// The levels are natural logarithms of the power
+	threshold := loudest - opts.RangeDB*math.Ln10/10
+
+	// The maximum over the neighbourhood is separable into bins and frames
+	maxima := make([]float64, len(levels))
+	for t := range frames {
+		slidingMax(levels[t*bins:], maxima[t*bins:], bins, 1, opts.FreqRadius)
+	}
+	neighbourhood := make([]float64, len(levels))
+	for k := range bins {
+		slidingMax(maxima[k:], neighbourhood[k:], len(frames), bins, opts.TimeRadius)
+	}
+
+	var peaks []Peak
+	for t := range frames {
+		// DC and Nyquist carry no useful landmarks
+		for k := 1; k < bins-1; k++ {
+			v := levels[t*bins+k]
+			if v >= threshold && v == neighbourhood[t*bins+k] {
+				peaks = append(peaks, Peak{Frame: t, Bin: k, Level: v})
+			}
+		}
+	}
+	return thin(peaks, opts), nil
+}
+
+// slidingMax writes the maximum of in over ±radius elements to out for n
+// elements spaced by stride, in linear time with a monotonic queue
+func slidingMax(in, out []float64, n, stride, radius int) {
+	queue := make([]int, 0, 2*radius+1)
+	next := 0
+	for i := range n {
+		for ; next < n && next <= i+radius; next++ {
+			for len(queue) > 0 && in[queue[len(queue)-1]*stride] <= in[next*stride] {
+				queue = queue[:len(queue)-1]
+			}
+			queue = append(queue, next)
+		}
+		for queue[0] < i-radius {
+			queue = queue[1:]
+		}
+		out[i*stride] = in[queue[0]*stride]
+	}
+}
+
+// thin keeps the strongest opts.Density peaks of each second so that loud
+// and dense passages do not flood the index
+func thin(peaks []Peak, opts Options) []Peak {
+	if opts.Density <= 0 {
+		return peaks
+	}
+	framesPerSecond := max(1, int(math.Round(float64(opts.SampleRate)/float64(opts.HopSize))))
+	var kept []Peak
+	for start := 0; start < len(peaks); {
+		end := start
+		second := peaks[start].Frame / framesPerSecond
+		for end < len(peaks) && peaks[end].Frame/framesPerSecond == second {
+			end++
+		}
+		chunk := peaks[start:end]
+		if len(chunk) > opts.Density {
+			sort.Slice(chunk, func(i, j int) bool { return chunk[i].Level > chunk[j].Level })
+			chunk = chunk[:opts.Density]
+			sort.Slice(chunk, func(i, j int) bool {
+				if chunk[i].Frame != chunk[j].Frame {
+					return chunk[i].Frame < chunk[j].Frame
+				}
+				return chunk[i].Bin < chunk[j].Bin
+			})
+		}
+		kept = append(kept, chunk...)
+		start = end
+	}
+	return kept
+}
+
+// Hashes pairs each peak with up to opts.FanOut later peaks in its target
+// zone, the peaks at most opts.MaxDelta frames later and opts.MaxBins bins
+// apart, and returns the hashes of the pairs
+func Hashes(peaks []Peak, opts Options) []Fingerprint {
+	var fps []Fingerprint
+	for i, anchor := range peaks {
+		paired := 0
+		for _, target := range peaks[i+1:] {
+			dt := target.Frame - anchor.Frame
+			if dt > opts.MaxDelta || paired == opts.FanOut {
+				break
+			}
+			if dt == 0 || abs(target.Bin-anchor.Bin) > opts.MaxBins {
+				continue
+			}
+			fps = append(fps, Fingerprint{Hash: newHash(anchor, target), Offset: anchor.Frame})
+			paired++
+		}
+	}
+	return fps
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
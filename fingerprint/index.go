@@ -0,0 +1,139 @@
+package fingerprint
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// posting is an occurrence of a hash in an indexed recording
+type posting struct {
+	id     int32
+	offset int32
+}
+
+// Index is an in-memory index of fingerprinted recordings. It is safe for
+// concurrent use, e.g. to fingerprint a library with several workers.
+type Index struct {
+	opts Options
+
+	mu       sync.RWMutex
+	names    []string
+	lengths  []int // number of fingerprints per recording
+	postings map[Hash][]posting
+}
+
+// Match is a recording of the index that matches a query
+type Match struct {
+	ID    int    // ID returned by Index.Add
+	Name  string // name passed to Index.Add
+	Score int    // number of hashes that agree on the offset
+	// Offset is the position of the start of the query within the recording
+	// in seconds, negative if the query starts before it
+	Offset float64
+	// Coverage is Score relative to the number of fingerprints of the query,
+	// or of the recording if it is shorter
+	Coverage float64
+}
+
+// NewIndex returns an empty index for fingerprints computed with opts
+func NewIndex(opts Options) *Index {
+	return &Index{opts: opts, postings: map[Hash][]posting{}}
+}
+
+// Options returns the options the fingerprints of the index are computed with
+func (ix *Index) Options() Options { return ix.opts }
+
+// Len returns the number of indexed recordings
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.names)
+}
+
+// Name returns the name of the recording with the given ID
+func (ix *Index) Name(id int) string {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.names[id]
+}
+
+// Add indexes the fingerprints of a recording and returns its ID
+func (ix *Index) Add(name string, fps []Fingerprint) int {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	id := len(ix.names)
+	ix.names = append(ix.names, name)
+	ix.lengths = append(ix.lengths, len(fps))
+	for _, fp := range fps {
+		ix.postings[fp.Hash] = append(ix.postings[fp.Hash], posting{int32(id), int32(fp.Offset)})
+	}
+	return id
+}
+
+// AddSamples fingerprints a recording with the options of the index and adds
+// it
+func (ix *Index) AddSamples(name string, samples []float64, sampleRate int) (int, error) {
+	fps, err := Compute(samples, sampleRate, ix.opts)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return ix.Add(name, fps), nil
+}
+
+// Lookup returns the recordings that share at least minScore time aligned
+// hashes with the query, best match first. For every recording the offsets
+// of the shared hashes are histogrammed; a true match piles up in a single
+// offset while chance collisions spread out.
+func (ix *Index) Lookup(query []Fingerprint, minScore int) []Match {
+	type key struct {
+		id    int32
+		delta int32
+	}
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	counts := map[key]int{}
+	for _, fp := range query {
+		for _, p := range ix.postings[fp.Hash] {
+			counts[key{p.id, p.offset - int32(fp.Offset)}]++
+		}
+	}
+
+	best := map[int32]key{}
+	for k, n := range counts {
+		if b, ok := best[k.id]; !ok || n > counts[b] || n == counts[b] && k.delta < b.delta {
+			best[k.id] = k
+		}
+	}
+	var matches []Match
+	for id, k := range best {
+		score := counts[k]
+		if score < max(minScore, 1) {
+			continue
+		}
+		matches = append(matches, Match{
+			ID:       int(id),
+			Name:     ix.names[id],
+			Score:    score,
+			Offset:   ix.opts.Seconds(int(k.delta)),
+			Coverage: float64(score) / float64(max(1, min(len(query), ix.lengths[id]))),
+		})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// LookupSamples fingerprints a query recording with the options of the index
+// and looks it up
+func (ix *Index) LookupSamples(samples []float64, sampleRate, minScore int) ([]Match, error) {
+	fps, err := Compute(samples, sampleRate, ix.opts)
+	if err != nil {
+		return nil, err
+	}
+	return ix.Lookup(fps, minScore), nil
+}
@@ -0,0 +1,161 @@
+// Package fixed computes FFTs in fixed-point arithmetic on int16 (Q15) or
+// int32 (Q31) samples, for targets like microcontrollers where floating point
+// is slow or missing. Block floating point scaling halves all values before a
+// stage whenever a butterfly could overflow, and counts the halvings in a
+// common exponent, so the full integer range is used without overflow.
+package fixed
+
+import (
+	"fmt"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Sample is the integer type of the fixed-point values: int16 in Q15 or
+// int32 in Q31 format, i.e. full scale is ±1
+type Sample interface {
+	int16 | int32
+}
+
+// fracBits returns the number of fractional bits of T
+func fracBits[T Sample]() uint {
+	var zero T
+	if _, ok := any(zero).(int16); ok {
+		return 15
+	}
+	return 31
+}
+
+// Forward computes the FFT of the complex signal re + i·im in place. The
+// length must be a power of two. The coefficients are
+// (re[k] + i·im[k])·2^exp in the units of the input, without the 1/N
+// normalization of an inverse transform.
+func Forward[T Sample](re, im []T) (exp int, err error) {
+	n := len(re)
+	if len(im) != n {
+		return 0, fmt.Errorf("%d real and %d imaginary values", n, len(im))
+	}
+	if n == 0 || n&(n-1) != 0 {
+		return 0, fmt.Errorf("length %d is not a power of two", n)
+	}
+	q := fracBits[T]()
+	fullScale := int64(1)<<q - 1
+	// A butterfly grows a component by at most 1+√2
+	limit := fullScale * 2 / 5
+	round := int64(1) << (q - 1)
+
+	// Twiddles e^(-2πik/n) for k < n/2
+	cos, sin := make([]int64, n/2), make([]int64, n/2)
+	for k := range cos {
+		angle := 2 * math.Pi * float64(k) / float64(n)
+		cos[k] = int64(math.Round(math.Cos(angle) * float64(fullScale)))
+		sin[k] = int64(math.Round(math.Sin(angle) * float64(fullScale)))
+	}
+
+	// Bit reversed order
+	for i, j := 0, 0; i < n; i++ {
+		if i < j {
+			re[i], re[j] = re[j], re[i]
+			im[i], im[j] = im[j], im[i]
+		}
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j |= bit
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		for maxAbs(re, im) > limit {
+			for i := range re {
+				re[i], im[i] = T((int64(re[i])+1)>>1), T((int64(im[i])+1)>>1)
+			}
+			exp++
+		}
+		half, step := size/2, n/size
+		for start := 0; start < n; start += size {
+			for j := range half {
+				wr, wi := cos[j*step], -sin[j*step]
+				a, b := start+j, start+j+half
+				br, bi := int64(re[b]), int64(im[b])
+				tr := (br*wr - bi*wi + round) >> q
+				ti := (br*wi + bi*wr + round) >> q
+				ar, ai := int64(re[a]), int64(im[a])
+				re[a], im[a] = T(ar+tr), T(ai+ti)
+				re[b], im[b] = T(ar-tr), T(ai-ti)
+			}
+		}
+	}
+	return exp, nil
+}
+
+func maxAbs[T Sample](re, im []T) int64 {
+	m := int64(0)
+	for i := range re {
+		m = max(m, abs(int64(re[i])), abs(int64(im[i])))
+	}
+	return m
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Quantize converts samples in [-1, 1] to fixed-point values, clipping the
+// samples outside of that range
+func Quantize[T Sample](samples []float64) []T {
+	fullScale := float64(int64(1)<<fracBits[T]() - 1)
+	q := make([]T, len(samples))
+	for i, v := range samples {
+		q[i] = T(math.Round(math.Max(-1, math.Min(v, 1)) * fullScale))
+	}
+	return q
+}
+
+// ToComplex converts the coefficients of Forward to the scale of dft.Forward
+// of the samples in [-1, 1]
+func ToComplex[T Sample](re, im []T, exp int) []complex128 {
+	scale := math.Ldexp(1, exp) / float64(int64(1)<<fracBits[T]()-1)
+	coeffs := make([]complex128, len(re))
+	for k := range coeffs {
+		coeffs[k] = complex(float64(re[k])*scale, float64(im[k])*scale)
+	}
+	return coeffs
+}
+
+// Transform computes the FFT of a real valued signal in fixed-point. Unlike
+// dft.Forward it always transforms a power of two length: the samples are
+// zero-padded to n = NextPowerOfTwo(len(samples)) and the n/2+1 coefficients
+// of the non-negative frequencies of the padded signal are returned, which
+// equal dft.Forward of that padded signal. The samples are scaled to full
+// scale before quantization and the coefficients back, so they may have any
+// level. Only on power of two sizes, where both contracts agree, can
+// Transform[int16] be checked with dft.VerifyForward.
+func Transform[T Sample](samples []float64) []complex128 {
+	n := dft.NextPowerOfTwo(len(samples))
+	peak := 0.0
+	for _, v := range samples {
+		peak = math.Max(peak, math.Abs(v))
+	}
+	if peak == 0 {
+		return make([]complex128, n/2+1)
+	}
+	scaled := make([]float64, n)
+	for i, v := range samples {
+		scaled[i] = v / peak
+	}
+	re := Quantize[T](scaled)
+	im := make([]T, n)
+	// The length is a power of two and re and im have the same length, so
+	// Forward cannot fail
+	exp, _ := Forward(re, im)
+	coeffs := ToComplex(re[:n/2+1], im[:n/2+1], exp)
+	for k := range coeffs {
+		coeffs[k] *= complex(peak, 0)
+	}
+	return coeffs
+}
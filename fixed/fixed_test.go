@@ -0,0 +1,65 @@
+package fixed
+
+import (
+	"math/cmplx"
+	"testing"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// verifyOptions are dft.DefaultVerifyOptions restricted to the power of two
+// sizes that Forward supports
+func verifyOptions(maxErr float64) dft.VerifyOptions {
+	opts := dft.DefaultVerifyOptions
+	opts.Sizes = nil
+	for _, n := range dft.DefaultVerifyOptions.Sizes {
+		if n&(n-1) == 0 {
+			opts.Sizes = append(opts.Sizes, n)
+		}
+	}
+	opts.MaxErr = maxErr
+	return opts
+}
+
+func TestTransform(t *testing.T) {
+	// The quantization error of Q15 and Q31 bounds the accuracy
+	for _, c := range []struct {
+		name      string
+		transform func([]float64) []complex128
+		maxErr    float64
+	}{
+		{"int16", Transform[int16], 2e-2},
+		{"int32", Transform[int32], 1e-6},
+	} {
+		if worst, err := dft.VerifyForward(c.transform, verifyOptions(c.maxErr)); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		} else {
+			t.Logf("%s: max. error %.3g", c.name, worst)
+		}
+	}
+}
+
+func TestTransformPadding(t *testing.T) {
+	x := []float64{0.5, -0.25, 1, 0.75, -1}
+	got := Transform[int32](x)
+	padded := make([]float64, 8)
+	copy(padded, x)
+	want := dft.Forward(padded)
+	if len(got) != len(want) {
+		t.Fatalf("%d coefficients instead of %d", len(got), len(want))
+	}
+	for k := range want {
+		if cmplx.Abs(got[k]-want[k]) > 1e-6 {
+			t.Errorf("coefficient %d is %v instead of %v", k, got[k], want[k])
+		}
+	}
+}
+
+func TestForwardErrors(t *testing.T) {
+	if _, err := Forward(make([]int16, 6), make([]int16, 6)); err == nil {
+		t.Error("no error for a length of 6")
+	}
+	if _, err := Forward(make([]int16, 8), make([]int16, 4)); err == nil {
+		t.Error("no error for different lengths")
+	}
+}
@@ -0,0 +1,126 @@
+package dft
+
+import (
+	"math"
+	"sort"
+)
+
+// Defaults of the local noise floor estimate
+const (
+	defaultFloorWindowBins = 64
+	defaultFloorPercentile = 0.5
+)
+
+// minimumStatisticsBias compensates that the minimum of the smoothed noise power
+// is below its mean, it is a typical value for smoothing factors around 0.9
+const minimumStatisticsBias = 1.5
+
+// NoiseFloor estimates the noise floor of a magnitude spectrum with a
+// sliding percentile filter of windowBins bins. A percentile of 0.5 is a median
+// filter, which ignores narrow peaks as long as they cover less than half of the
+// window. To keep it cheap for long spectra the percentile is evaluated on a
+// grid of an eighth of the window and linearly interpolated in between.
+//
+// percentile is in the range [0..1], 0 being the minimum and 1 the maximum of
+// the window. Values outside of it are clamped, NaN selects the median.
+func NoiseFloor(mag []float64, windowBins int, percentile float64) []float64 {
+	n := len(mag)
+	floor := make([]float64, n)
+	if n == 0 {
+		return floor
+	}
+	if windowBins < 3 {
+		windowBins = 3
+	}
+	switch {
+	case math.IsNaN(percentile):
+		percentile = defaultFloorPercentile
+	case percentile < 0:
+		percentile = 0
+	case percentile > 1:
+		percentile = 1
+	}
+	step := windowBins / 8
+	if step < 1 {
+		step = 1
+	}
+
+	buf := make([]float64, 0, windowBins+1)
+	eval := func(center int) float64 {
+		start, end := center-windowBins/2, center+windowBins/2
+		if start < 0 {
+			start = 0
+		}
+		if end >= n {
+			end = n - 1
+		}
+		buf = append(buf[:0], mag[start:end+1]...)
+		sort.Float64s(buf)
+		return buf[int(percentile*float64(len(buf)-1))]
+	}
+
+	prev, prevValue := 0, eval(0)
+	floor[0] = prevValue
+	for next := step; prev < n-1; next += step {
+		if next > n-1 {
+			next = n - 1
+		}
+		nextValue := eval(next)
+		for i := prev + 1; i <= next; i++ {
+			t := float64(i-prev) / float64(next-prev)
+			floor[i] = prevValue + t*(nextValue-prevValue)
+		}
+		prev, prevValue = next, nextValue
+	}
+	return floor
+}
+
+// aboveFloorDB returns how far mag is above floor in dB
+func aboveFloorDB(mag, floor float64) float64 {
+	return 20 * math.Log10((mag+minMagnitude)/(floor+minMagnitude))
+}
+
+// NoiseFloor estimates the noise floor of s with a sliding percentile filter
+// over windowHz, see NoiseFloor
+func (s Spectrum) NoiseFloor(windowHz, percentile float64) []float64 {
+	return NoiseFloor(s.Magnitude, int(windowHz/s.FreqRes()), percentile)
+}
+
+// MinimumStatisticsFloor estimates the per-bin noise floor of a signal from its
+// STFT frames with minimum statistics (Martin, 2001): the power of each bin is
+// recursively smoothed over time with the smoothing factor in the range (0..1)
+// and the minimum over all frames, corrected for its bias, is the noise floor.
+// Unlike NoiseFloor it also works for bins that are permanently occupied by a
+// tone, as long as the tone pauses at some point. The result is in the same
+// magnitude units as NewSpectrum.
+func MinimumStatisticsFloor(frames []Frame, sampleRate, frameSize int, smoothing float64) []float64 {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	bins := len(frames[0].Spectrum)
+	smoothed := make([]float64, bins)
+	minimum := make([]float64, bins)
+	for i := range minimum {
+		minimum[i] = math.Inf(1)
+	}
+
+	for f, frame := range frames {
+		mag := NewSpectrum(frame.Spectrum, sampleRate, frameSize, frameSize).Magnitude
+		for k := 0; k < bins && k < len(mag); k++ {
+			power := mag[k] * mag[k]
+			if f == 0 {
+				smoothed[k] = power
+			} else {
+				smoothed[k] = smoothing*smoothed[k] + (1-smoothing)*power
+			}
+			minimum[k] = math.Min(minimum[k], smoothed[k])
+		}
+	}
+
+	floor := make([]float64, bins)
+	for k := range floor {
+		floor[k] = math.Sqrt(minimum[k] * minimumStatisticsBias)
+	}
+	return floor
+}
@@ -0,0 +1,32 @@
+package dft
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNoiseFloorPercentileRange(t *testing.T) {
+	mag := make([]float64, 100)
+	for i := range mag {
+		mag[i] = float64(i%10) + 1
+	}
+	for _, c := range []struct {
+		percentile float64
+		want       float64 // floor in the middle of the spectrum
+	}{
+		{-1, 1},
+		{0, 1},
+		{1, 10},
+		{2, 10},
+		{math.Inf(1), 10},
+		{math.NaN(), NoiseFloor(mag, 20, defaultFloorPercentile)[50]},
+	} {
+		floor := NoiseFloor(mag, 20, c.percentile)
+		if len(floor) != len(mag) {
+			t.Fatalf("percentile %g: %d values for %d bins", c.percentile, len(floor), len(mag))
+		}
+		if floor[50] != c.want {
+			t.Errorf("percentile %g: floor %g, want %g", c.percentile, floor[50], c.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package dft
+
+import (
+	"math"
+	"sort"
+)
+
+// HarmonicSeries is a group of peaks forming a fundamental and its overtones
+type HarmonicSeries struct {
+	Fundamental float64 // frequency of the fundamental in Hz
+	Partials    []Peak  // peaks of the series in ascending frequency, the first is the fundamental
+	Harmonics   []int   // harmonic number of each partial, 1 is the fundamental
+	Energy      float64 // sum of the squared magnitudes of all partials
+}
+
+// GroupHarmonics groups peaks into harmonic series. A peak belongs to the series
+// of a fundamental f0 if its frequency is within toleranceCents of h*f0 for a
+// harmonic number h in 2..maxHarmonic.
+//
+// Series are built greedily: the fundamental whose series fits best is chosen
+// first and its partials are removed from the candidates. The fit is the
+// energy of the series times the share of harmonics up to the highest one
+// found that are present, so a weak peak an octave below a tone, whose series
+// would only hold every other harmonic, does not take over the tone's
+// partials. Every peak ends up in exactly one series, the series are returned
+// with the highest energy first.
+func GroupHarmonics(peaks []Peak, toleranceCents float64, maxHarmonic int) []HarmonicSeries {
+	remaining := append([]Peak(nil), peaks...)
+	sort.SliceStable(remaining, func(i, j int) bool {
+		return remaining[i].FreqHz < remaining[j].FreqHz
+	})
+
+	groups := []HarmonicSeries{}
+	for len(remaining) > 0 {
+		var best HarmonicSeries
+		var bestMembers []int
+		bestFit := 0.0
+		for i := range remaining {
+			series, members := harmonicSeries(remaining, i, toleranceCents, maxHarmonic)
+			fit := harmonicFit(series)
+			if bestMembers == nil || fit > bestFit {
+				best, bestMembers, bestFit = series, members, fit
+			}
+		}
+		groups = append(groups, best)
+
+		isMember := make(map[int]bool, len(bestMembers))
+		for _, m := range bestMembers {
+			isMember[m] = true
+		}
+		kept := remaining[:0]
+		for i, p := range remaining {
+			if !isMember[i] {
+				kept = append(kept, p)
+			}
+		}
+		remaining = kept
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Energy > groups[j].Energy
+	})
+	return groups
+}
+
+// harmonicSeries builds the series with the fundamental peaks[fund] from the
+// peaks above it. peaks must be sorted by frequency. The indices of the member
+// peaks are returned along with the series.
+func harmonicSeries(peaks []Peak, fund int, toleranceCents float64, maxHarmonic int) (HarmonicSeries, []int) {
+	f0 := peaks[fund].FreqHz
+	series := HarmonicSeries{
+		Fundamental: f0,
+		Partials:    []Peak{peaks[fund]},
+		Harmonics:   []int{1},
+		Energy:      peaks[fund].Magnitude * peaks[fund].Magnitude,
+	}
+	members := []int{fund}
+	if f0 <= 0 {
+		return series, members
+	}
+
+	// The strongest matching peak per harmonic number, found by binary search
+	// in the sorted peaks
+	matches := map[int]int{}
+	spread := math.Exp2(toleranceCents / 1200)
+	for h := 2; h <= maxHarmonic; h++ {
+		target := float64(h) * f0
+		lo, hi := target/spread, target*spread
+		for i := sort.Search(len(peaks), func(i int) bool { return peaks[i].FreqHz >= lo }); i < len(peaks) && peaks[i].FreqHz <= hi; i++ {
+			if i <= fund || int(math.Round(peaks[i].FreqHz/f0)) != h {
+				continue
+			}
+			if prev, ok := matches[h]; !ok || peaks[i].Magnitude > peaks[prev].Magnitude {
+				matches[h] = i
+			}
+		}
+	}
+
+	for h := 2; h <= maxHarmonic; h++ {
+		i, ok := matches[h]
+		if !ok {
+			continue
+		}
+		series.Partials = append(series.Partials, peaks[i])
+		series.Harmonics = append(series.Harmonics, h)
+		series.Energy += peaks[i].Magnitude * peaks[i].Magnitude
+		members = append(members, i)
+	}
+	return series, members
+}
+
+// harmonicFit scores a series by its energy times the share of the harmonics
+// 1..n that are present, n being the highest harmonic of the series
+func harmonicFit(series HarmonicSeries) float64 {
+	highest := series.Harmonics[len(series.Harmonics)-1]
+	return series.Energy * float64(len(series.Harmonics)) / float64(highest)
+}
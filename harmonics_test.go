@@ -0,0 +1,38 @@
+package dft
+
+import "testing"
+
+// TestGroupHarmonicsSubharmonic checks that a weak peak an octave below a
+// harmonic tone does not become the fundamental of the tone's partials
+func TestGroupHarmonicsSubharmonic(t *testing.T) {
+	peaks := []Peak{{FreqHz: 100, Magnitude: 0.05}}
+	for h := 1; h <= 5; h++ {
+		peaks = append(peaks, Peak{FreqHz: 200 * float64(h), Magnitude: 1 / float64(h)})
+	}
+	peaks = append(peaks, Peak{FreqHz: 330, Magnitude: 0.3}, Peak{FreqHz: 660, Magnitude: 0.1})
+
+	groups := GroupHarmonics(peaks, 20, 10)
+	if len(groups) != 3 {
+		t.Fatalf("got %d series, want 3: %+v", len(groups), groups)
+	}
+	for i, want := range []struct {
+		fundamental float64
+		harmonics   []int
+	}{
+		{200, []int{1, 2, 3, 4, 5}},
+		{330, []int{1, 2}},
+		{100, []int{1}},
+	} {
+		g := groups[i]
+		if g.Fundamental != want.fundamental || len(g.Harmonics) != len(want.harmonics) {
+			t.Errorf("series %d: fundamental %g Hz with harmonics %v, want %g Hz with %v", i, g.Fundamental, g.Harmonics, want.fundamental, want.harmonics)
+			continue
+		}
+		for j, h := range want.harmonics {
+			if g.Harmonics[j] != h {
+				t.Errorf("series %d: harmonics %v, want %v", i, g.Harmonics, want.harmonics)
+				break
+			}
+		}
+	}
+}
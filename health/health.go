@@ -0,0 +1,158 @@
+// Package health checks recordings for capture problems that distort any
+// later analysis: clipping, a DC offset and dropouts, so bad captures are
+// flagged before their spectra are trusted.
+package health
+
+import (
+	"fmt"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Options configures Check
+type Options struct {
+	// A run of at least MinClipRun equal samples at or above ClipLevelDB
+	// (dBFS) is a clipped region. Flat tops below full scale are found as
+	// well, e.g. of a clipped signal that was attenuated afterwards.
+	ClipLevelDB float64
+	MinClipRun  int
+
+	DCLimitDB float64 // highest tolerated DC offset in dBFS
+
+	// A run of equal samples below the clip level that lasts at least
+	// MinDropout seconds, e.g. digital silence from a buffer underrun, is a
+	// dropout. Runs at the start or the end of a channel are not dropouts.
+	MinDropout float64
+}
+
+// DefaultOptions flag flat tops of 4 samples above -1 dBFS, an offset above
+// -40 dBFS (1% of full scale) and dropouts of 5 ms
+var DefaultOptions = Options{
+	ClipLevelDB: -1,
+	MinClipRun:  4,
+	DCLimitDB:   -40,
+	MinDropout:  0.005,
+}
+
+// Region is a part of a channel, in seconds
+type Region struct {
+	Start float64 `json:"start_s"`
+	End   float64 `json:"end_s"`
+}
+
+// Duration returns the length of the region in seconds
+func (r Region) Duration() float64 { return r.End - r.Start }
+
+// Channel is the health of a channel
+type Channel struct {
+	Clips          []Region `json:"clips"`           // clipped regions
+	ClippedSamples int      `json:"clipped_samples"` // samples in the clipped regions
+	DCOffset       float64  `json:"dc_offset"`       // mean of the samples
+	DCOffsetDB     float64  `json:"dc_offset_dbfs"`  // level of the mean
+	HasDCOffset    bool     `json:"has_dc_offset"`   // the offset exceeds the limit
+	Dropouts       []Region `json:"dropouts"`
+}
+
+// OK reports whether no problem was found in the channel
+func (c Channel) OK() bool {
+	return len(c.Clips) == 0 && !c.HasDCOffset && len(c.Dropouts) == 0
+}
+
+// Report is the health of all channels of a recording
+type Report struct {
+	SampleRate int       `json:"sample_rate"`
+	Channels   []Channel `json:"channels"`
+}
+
+// OK reports whether no problem was found in any channel
+func (r Report) OK() bool {
+	for _, c := range r.Channels {
+		if !c.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// Shift moves all regions by offset seconds, e.g. to refer to the start of a
+// file instead of the start of the checked range
+func (r Report) Shift(offset float64) {
+	for _, c := range r.Channels {
+		for _, regions := range [][]Region{c.Clips, c.Dropouts} {
+			for i := range regions {
+				regions[i].Start += offset
+				regions[i].End += offset
+			}
+		}
+	}
+}
+
+// Problems describes the problems of each channel in a line, e.g. for
+// warnings, or returns nil if the recording is healthy
+func (r Report) Problems() []string {
+	var problems []string
+	for i, c := range r.Channels {
+		if len(c.Clips) > 0 {
+			problems = append(problems, fmt.Sprintf("channel %d: clipped regions: %d with %d samples, the first at %.3f s",
+				i, len(c.Clips), c.ClippedSamples, c.Clips[0].Start))
+		}
+		if c.HasDCOffset {
+			problems = append(problems, fmt.Sprintf("channel %d: DC offset of %.4g (%.1f dBFS)", i, c.DCOffset, c.DCOffsetDB))
+		}
+		if len(c.Dropouts) > 0 {
+			problems = append(problems, fmt.Sprintf("channel %d: dropouts: %d, the first at %.3f s for %.1f ms",
+				i, len(c.Dropouts), c.Dropouts[0].Start, c.Dropouts[0].Duration()*1000))
+		}
+	}
+	return problems
+}
+
+// Check examines every channel of a recording for clipping, DC offset and
+// dropouts
+func Check(channels [][]float64, sampleRate int, opts Options) (Report, error) {
+	if sampleRate <= 0 {
+		return Report{}, fmt.Errorf("invalid sample rate %d", sampleRate)
+	}
+	if opts.MinClipRun < 2 {
+		return Report{}, fmt.Errorf("invalid minimum clip run of %d samples", opts.MinClipRun)
+	}
+	clipLevel := math.Pow(10, opts.ClipLevelDB/20)
+	minDropout := max(int(math.Ceil(opts.MinDropout*float64(sampleRate))), 2)
+	seconds := func(i int) float64 { return float64(i) / float64(sampleRate) }
+
+	r := Report{SampleRate: sampleRate, Channels: make([]Channel, len(channels))}
+	for ch, x := range channels {
+		c := &r.Channels[ch]
+		sum := 0.0
+		for _, v := range x {
+			sum += v
+		}
+		if len(x) > 0 {
+			c.DCOffset = sum / float64(len(x))
+		}
+		c.DCOffsetDB = dft.DefaultDBFloor
+		if c.DCOffset != 0 {
+			c.DCOffsetDB = math.Max(20*math.Log10(math.Abs(c.DCOffset)), dft.DefaultDBFloor)
+		}
+		c.HasDCOffset = c.DCOffsetDB > opts.DCLimitDB
+
+		// Both problems are runs of equal samples, told apart by their level
+		for start := 0; start < len(x); {
+			end := start + 1
+			for end < len(x) && x[end] == x[start] {
+				end++
+			}
+			n := end - start
+			switch {
+			case math.Abs(x[start]) >= clipLevel && n >= opts.MinClipRun:
+				c.Clips = append(c.Clips, Region{seconds(start), seconds(end)})
+				c.ClippedSamples += n
+			case math.Abs(x[start]) < clipLevel && n >= minDropout && start > 0 && end < len(x):
+				c.Dropouts = append(c.Dropouts, Region{seconds(start), seconds(end)})
+			}
+			start = end
+		}
+	}
+	return r, nil
+}
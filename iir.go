@@ -0,0 +1,126 @@
+package dft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Biquad is a second order IIR filter section with normalized coefficients
+//
+//	H(z) = (B0 + B1 z^-1 + B2 z^-2) / (1 + A1 z^-1 + A2 z^-2)
+type Biquad struct {
+	B0, B1, B2 float64
+	A1, A2     float64
+}
+
+// SOS is an IIR filter as a cascade of second order sections
+type SOS []Biquad
+
+// Filter applies the filter to x and returns the filtered signal. The filter
+// starts at rest for every call.
+func (f SOS) Filter(x []float64) []float64 {
+	y := append([]float64(nil), x...)
+	for _, s := range f {
+		s.filter(y, 0, 0)
+	}
+	return y
+}
+
+// FilterFrom is like Filter, but the filter starts in the steady state of a
+// constant input of initial instead of at rest, like with lfilter_zi in
+// SciPy. A signal that starts at initial then causes no step transient.
+func (f SOS) FilterFrom(x []float64, initial float64) []float64 {
+	y := append([]float64(nil), x...)
+	in := initial
+	for _, s := range f {
+		// Steady state of the transposed direct form II for the constant input
+		// in and output out = gain*in
+		den := 1 + s.A1 + s.A2
+		if den == 0 {
+			// Pole at z = 1, there is no steady state
+			s.filter(y, 0, 0)
+			in = 0
+			continue
+		}
+		out := in * (s.B0 + s.B1 + s.B2) / den
+		s.filter(y, out-s.B0*in, s.B2*in-s.A2*out)
+		in = out
+	}
+	return y
+}
+
+// filter applies the section in place to y with the initial state z1, z2
+func (s Biquad) filter(y []float64, z1, z2 float64) {
+	// Transposed direct form II
+	for i, in := range y {
+		out := s.B0*in + z1
+		z1 = s.B1*in - s.A1*out + z2
+		z2 = s.B2*in - s.A2*out
+		y[i] = out
+	}
+}
+
+// DecayLength returns the number of samples after which the impulse response
+// of the filter has decayed to tolerance of its initial level, estimated from
+// the largest pole radius. It is -1 for unstable filters.
+func (f SOS) DecayLength(tolerance float64) int {
+	radius := 0.0
+	for _, s := range f {
+		// Complex poles have the radius sqrt(A2), real ones are the roots of
+		// z^2 + A1 z + A2
+		if d := s.A1*s.A1 - 4*s.A2; d < 0 {
+			radius = math.Max(radius, math.Sqrt(s.A2))
+		} else {
+			radius = math.Max(radius, (math.Abs(s.A1)+math.Sqrt(d))/2)
+		}
+	}
+	switch {
+	case radius >= 1:
+		return -1
+	case radius == 0:
+		return 2 * len(f)
+	}
+	return int(math.Ceil(math.Log(tolerance) / math.Log(radius)))
+}
+
+// Response returns the complex frequency response of the filter at freq Hz
+func (f SOS) Response(freq float64, sampleRate int) complex128 {
+	z := cmplx.Exp(complex(0, -2*math.Pi*freq/float64(sampleRate))) // z^-1
+	h := complex(1, 0)
+	for _, s := range f {
+		num := complex(s.B0, 0) + complex(s.B1, 0)*z + complex(s.B2, 0)*z*z
+		den := 1 + complex(s.A1, 0)*z + complex(s.A2, 0)*z*z
+		h *= num / den
+	}
+	return h
+}
+
+// Scale multiplies the gain of the filter by g
+func (f SOS) Scale(g float64) {
+	if len(f) == 0 {
+		return
+	}
+	f[0].B0 *= g
+	f[0].B1 *= g
+	f[0].B2 *= g
+}
+
+// bilinearPole maps an analog real pole or zero at -2*pi*freq rad/s to the z-plane
+func bilinearPole(freq float64, sampleRate int) float64 {
+	k := 2 * float64(sampleRate)
+	s := -2 * math.Pi * freq
+	return (k + s) / (k - s)
+}
+
+// realBiquad returns the biquad with the real zeros z1, z2 and real poles p1, p2
+func realBiquad(z1, z2, p1, p2 float64) Biquad {
+	return Biquad{
+		B0: 1, B1: -(z1 + z2), B2: z1 * z2,
+		A1: -(p1 + p2), A2: p1 * p2,
+	}
+}
+
+// Order returns the order of the filter
+func (f SOS) Order() int {
+	return 2 * len(f)
+}
@@ -0,0 +1,71 @@
+package dft
+
+import "math/cmplx"
+
+// IQSpectrum is the spectrum of complex baseband (I/Q) samples. Unlike Spectrum
+// it covers negative and positive frequencies, its bins are ordered from
+// CenterHz-SampleRate/2 upwards.
+type IQSpectrum struct {
+	Coefficients []complex128 // FFT coefficients, reordered like Magnitude
+	Magnitude    []float64    // amplitude of every bin, from the lowest frequency upwards
+	CenterHz     float64      // frequency the receiver was tuned to
+	SampleRate   int
+	FFTSize      int
+	SignalLength int
+}
+
+// ComputeIQSpectrum applies a Hanning window to iq, zero-pads it to the next
+// power of two and computes its amplitude spectrum. The magnitudes are scaled
+// so a complex tone of amplitude A shows up with a magnitude of A.
+func ComputeIQSpectrum(iq []complex128, sampleRate int, centerHz float64) IQSpectrum {
+	fftSize := NextPowerOfTwo(len(iq))
+	padded := make([]complex128, fftSize)
+	n := len(iq)
+	for i, w := range (Window{Type: Hanning}).Coefficients(n) {
+		padded[i] = iq[i] * complex(w, 0)
+	}
+	coeffs := ForwardComplex(padded)
+
+	// Move the negative frequencies to the front
+	shifted := FFTShift(coeffs)
+	mag := make([]float64, fftSize)
+	for i, c := range shifted {
+		mag[i] = cmplx.Abs(c) / float64(n) / hanningGain
+	}
+
+	return IQSpectrum{
+		Coefficients: shifted,
+		Magnitude:    mag,
+		CenterHz:     centerHz,
+		SampleRate:   sampleRate,
+		FFTSize:      fftSize,
+		SignalLength: n,
+	}
+}
+
+// FreqRes returns the frequency resolution in Hz per bin
+func (s IQSpectrum) FreqRes() float64 {
+	return float64(s.SampleRate) / float64(s.FFTSize)
+}
+
+// Frequency returns the absolute frequency in Hz of bin i
+func (s IQSpectrum) Frequency(i int) float64 {
+	return s.CenterHz + float64(i-s.FFTSize/2)*s.FreqRes()
+}
+
+// FindPeaks finds the peaks of the I/Q spectrum like FindPeaks for real
+// signals. The frequencies of the peaks are absolute, i.e. they include
+// CenterHz and are below it for negative baseband frequencies.
+func (s IQSpectrum) FindPeaks(opts PeakOptions) []Peak {
+	peaks := FindPeaks(Spectrum{
+		Coefficients: s.Coefficients,
+		Magnitude:    s.Magnitude,
+		SampleRate:   s.SampleRate,
+		FFTSize:      s.FFTSize,
+		SignalLength: s.SignalLength,
+	}, opts)
+	for i := range peaks {
+		peaks[i].FreqHz += s.Frequency(0)
+	}
+	return peaks
+}
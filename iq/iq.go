@@ -0,0 +1,104 @@
+// Package iq reads interleaved I/Q (complex baseband) sample files as recorded
+// by software defined radios, e.g. with rtl_sdr, hackrf_transfer or GNU Radio.
+package iq
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// Format is the sample format of an I/Q file
+type Format int
+
+const (
+	CU8  Format = iota // unsigned 8 bit with an offset of 127.5, e.g. rtl_sdr
+	CS8                // signed 8 bit, e.g. hackrf_transfer
+	CS16               // signed 16 bit little-endian
+	CF32               // 32 bit float little-endian, e.g. GNU Radio
+)
+
+var formatNames = map[Format]string{
+	CU8:  "cu8",
+	CS8:  "cs8",
+	CS16: "cs16",
+	CF32: "cf32",
+}
+
+func (f Format) String() string {
+	return formatNames[f]
+}
+
+// bytes returns the size of one I or Q value
+func (f Format) bytes() int {
+	switch f {
+	case CS16:
+		return 2
+	case CF32:
+		return 4
+	}
+	return 1
+}
+
+// ParseFormat parses a format name like cu8, cs8, cs16 or cf32. The SDR tool
+// names fc32 and complex16 are accepted as well.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "cu8", "u8":
+		return CU8, nil
+	case "cs8", "s8":
+		return CS8, nil
+	case "cs16", "s16", "complex16":
+		return CS16, nil
+	case "cf32", "fc32", "f32", "complex":
+		return CF32, nil
+	}
+	return 0, fmt.Errorf("unknown I/Q format %q", name)
+}
+
+// FormatFromExtension guesses the format from the file extension of path,
+// e.g. capture.cu8
+func FormatFromExtension(path string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// Load reads all I/Q pairs from r and returns them scaled to [-1, 1]
+func Load(r io.Reader, f Format) ([]complex128, error) {
+	if _, ok := formatNames[f]; !ok {
+		return nil, fmt.Errorf("unknown I/Q format %d", f)
+	}
+	size := 2 * f.bytes()
+	br := bufio.NewReader(r)
+	buf := make([]byte, 4096*size)
+
+	var samples []complex128
+	for {
+		n, err := io.ReadFull(br, buf)
+		for i := 0; i+size <= n; i += size {
+			samples = append(samples, complex(f.value(buf[i:]), f.value(buf[i+size/2:])))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return samples, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// value decodes a single I or Q value
+func (f Format) value(b []byte) float64 {
+	switch f {
+	case CU8:
+		return (float64(b[0]) - 127.5) / 127.5
+	case CS8:
+		return float64(int8(b[0])) / 128
+	case CS16:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / 32768
+	}
+	return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+}
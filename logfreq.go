@@ -0,0 +1,69 @@
+package dft
+
+import "math"
+
+// LogSpectrum is a spectrum on a logarithmic frequency axis
+type LogSpectrum struct {
+	Frequencies []float64 // center frequency of each band in Hz
+	Magnitude   []float64 // magnitude of each band
+}
+
+// LogRebin rebins the linear magnitude spectrum of s onto a logarithmic frequency
+// axis from minHz to maxHz with pointsPerOctave bands per octave.
+//
+// Bands that contain one or more bins get the RMS of their magnitudes, the
+// average level of the band: broadband noise keeps its level, but a tone is
+// averaged with the other bins of its band and drops by about
+// 10*log10(bins/lobe) dB, lobe being the width of the window's main lobe in
+// bins. Bands narrower than a bin, which happens at low frequencies, are
+// linearly interpolated between the neighbouring bins.
+func LogRebin(s Spectrum, minHz, maxHz float64, pointsPerOctave int) LogSpectrum {
+	result := LogSpectrum{Frequencies: []float64{}, Magnitude: []float64{}}
+	nyquist := float64(s.SampleRate) / 2
+	if maxHz > nyquist {
+		maxHz = nyquist
+	}
+	if minHz <= 0 || maxHz <= minHz || pointsPerOctave <= 0 || len(s.Magnitude) == 0 {
+		return result
+	}
+
+	freqRes := s.FreqRes()
+	halfBand := math.Pow(2, 0.5/float64(pointsPerOctave))
+	n := int(math.Floor(math.Log2(maxHz/minHz)*float64(pointsPerOctave))) + 1
+	for i := 0; i < n; i++ {
+		center := minHz * math.Pow(2, float64(i)/float64(pointsPerOctave))
+		lo := int(math.Ceil(center / halfBand / freqRes))
+		hi := int(math.Floor(center * halfBand / freqRes))
+		if hi >= len(s.Magnitude) {
+			hi = len(s.Magnitude) - 1
+		}
+
+		var mag float64
+		if hi >= lo {
+			sum := 0.0
+			for k := lo; k <= hi; k++ {
+				sum += s.Magnitude[k] * s.Magnitude[k]
+			}
+			mag = math.Sqrt(sum / float64(hi-lo+1))
+		} else {
+			mag = interpolateBin(s.Magnitude, center/freqRes)
+		}
+
+		result.Frequencies = append(result.Frequencies, center)
+		result.Magnitude = append(result.Magnitude, mag)
+	}
+	return result
+}
+
+// interpolateBin linearly interpolates values at the fractional index pos
+func interpolateBin(values []float64, pos float64) float64 {
+	i := int(math.Floor(pos))
+	if i < 0 {
+		return values[0]
+	}
+	if i >= len(values)-1 {
+		return values[len(values)-1]
+	}
+	t := pos - float64(i)
+	return values[i]*(1-t) + values[i+1]*t
+}
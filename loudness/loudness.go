@@ -0,0 +1,246 @@
+// Package loudness measures the loudness of programmes per ITU-R BS.1770 and
+// EBU R128: momentary, short-term and integrated loudness in LUFS, the
+// loudness range (EBU Tech 3342) and the true peak level.
+package loudness
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+const (
+	absoluteGate  = -70.0 // LUFS
+	relativeGate  = -10.0 // LU below the ungated loudness, for the integrated loudness
+	rangeGate     = -20.0 // LU below the ungated loudness, for the loudness range
+	momentarySize = 4     // 400 ms in 100 ms steps
+	shortTermSize = 30    // 3 s in 100 ms steps
+)
+
+// EBU R128 delivery targets
+const (
+	TargetLUFS      = -23.0 // integrated loudness of a programme
+	MaxTruePeakDBTP = -1.0  // highest permitted true peak level
+	TargetTolerance = 0.5   // LU around TargetLUFS for file based delivery
+)
+
+// Result is the loudness of a programme. Levels of silence, or of a
+// programme that is quieter than the absolute gate throughout, are
+// dft.DefaultDBFloor.
+type Result struct {
+	Integrated   float64       `json:"integrated_lufs"`     // gated loudness of the whole programme
+	Range        float64       `json:"range_lu"`            // loudness range (LRA), the spread of the short-term loudness
+	MomentaryMax float64       `json:"momentary_max_lufs"`  // highest momentary loudness
+	ShortTermMax float64       `json:"short_term_max_lufs"` // highest short-term loudness
+	TruePeak     float64       `json:"true_peak_dbtp"`      // highest level between the samples, from 4x oversampling
+	SamplePeak   float64       `json:"sample_peak_dbfs"`    // highest sample value
+	Peaks        []ChannelPeak `json:"channel_peaks"`       // peak levels of each channel
+	Momentary    []float64     `json:"-"`                   // loudness of the 400 ms windows, every 100 ms
+	ShortTerm    []float64     `json:"-"`                   // loudness of the 3 s windows, every 100 ms
+}
+
+// Compliant reports whether the programme meets the EBU R128 targets for
+// file delivery: an integrated loudness of -23 ±0.5 LUFS and a true peak of
+// at most -1 dBTP
+func (r Result) Compliant() bool {
+	return math.Abs(r.Integrated-TargetLUFS) <= TargetTolerance && r.TruePeak <= MaxTruePeakDBTP
+}
+
+// ChannelWeights returns the BS.1770 weights of n channels: 1 for the front
+// channels, and for 6 channels the 5.1 layout L, R, C, LFE, Ls, Rs with the
+// LFE excluded and the surround channels weighted by 1.41 (+1.5 dB)
+func ChannelWeights(n int) []float64 {
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1
+	}
+	if n == 6 {
+		weights[3] = 0
+		weights[4], weights[5] = 1.41, 1.41
+	}
+	return weights
+}
+
+// KWeighting returns the K-weighting filter of BS.1770 at sampleRate: a high
+// shelf of +4 dB above 1.5 kHz modelling the head, followed by a high-pass
+// at 38 Hz (RLB weighting). The coefficients are derived from the analog
+// prototype, at 48 kHz they equal those given in the standard.
+func KWeighting(sampleRate int) dft.SOS {
+	fs := float64(sampleRate)
+
+	k := math.Tan(math.Pi * 1681.974450955533 / fs)
+	q := 0.7071752369554196
+	vh := math.Pow(10, 3.999843853973347/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := dft.Biquad{
+		B0: (vh + vb*k/q + k*k) / a0,
+		B1: 2 * (k*k - vh) / a0,
+		B2: (vh - vb*k/q + k*k) / a0,
+		A1: 2 * (k*k - 1) / a0,
+		A2: (1 - k/q + k*k) / a0,
+	}
+
+	k = math.Tan(math.Pi * 38.13547087602444 / fs)
+	q = 0.5003270373238773
+	a0 = 1 + k/q + k*k
+	highPass := dft.Biquad{
+		B0: 1, B1: -2, B2: 1,
+		A1: 2 * (k*k - 1) / a0,
+		A2: (1 - k/q + k*k) / a0,
+	}
+	return dft.SOS{shelf, highPass}
+}
+
+// Measure computes the loudness of a programme from its channels, weighted
+// by weights (see ChannelWeights, nil for the default of the channel count).
+// The programme should be at least 3 s long for the short-term loudness and
+// the loudness range.
+func Measure(channels [][]float64, sampleRate int, weights []float64) (Result, error) {
+	if len(channels) == 0 || len(channels[0]) == 0 {
+		return Result{}, fmt.Errorf("no samples")
+	}
+	if sampleRate <= 0 {
+		return Result{}, fmt.Errorf("invalid sample rate %d", sampleRate)
+	}
+	if weights == nil {
+		weights = ChannelWeights(len(channels))
+	}
+	if len(weights) != len(channels) {
+		return Result{}, fmt.Errorf("%d weights for %d channels", len(weights), len(channels))
+	}
+
+	// The weighted power of each 100 ms step, windows are sums of steps
+	n := len(channels[0])
+	steps := n * 10 / sampleRate
+	power := make([]float64, steps)
+	kw := KWeighting(sampleRate)
+	for c, x := range channels {
+		if len(x) != n {
+			return Result{}, fmt.Errorf("channel %d has %d samples, channel 0 has %d", c, len(x), n)
+		}
+		if weights[c] == 0 {
+			continue
+		}
+		y := kw.Filter(x)
+		for s := range power {
+			sum := 0.0
+			for _, v := range y[s*sampleRate/10 : (s+1)*sampleRate/10] {
+				sum += v * v
+			}
+			power[s] += weights[c] * sum
+		}
+	}
+
+	r := Result{
+		Momentary:  windows(power, momentarySize, sampleRate),
+		ShortTerm:  windows(power, shortTermSize, sampleRate),
+		TruePeak:   dft.DefaultDBFloor,
+		SamplePeak: dft.DefaultDBFloor,
+		Peaks:      Peaks(channels),
+	}
+	for _, p := range r.Peaks {
+		r.TruePeak = math.Max(r.TruePeak, p.TruePeak)
+		r.SamplePeak = math.Max(r.SamplePeak, p.SamplePeak)
+	}
+	r.MomentaryMax = maxLoudness(r.Momentary)
+	r.ShortTermMax = maxLoudness(r.ShortTerm)
+	r.Integrated = gatedLoudness(r.Momentary, relativeGate)
+	r.Range = loudnessRange(r.ShortTerm)
+	return r, nil
+}
+
+// windows returns the loudness of the windows of size steps, advancing by a
+// step
+func windows(power []float64, size, sampleRate int) []float64 {
+	if len(power) < size {
+		return nil
+	}
+	// The steps may differ by a sample if the rate is not a multiple of 10
+	samples := float64(size*sampleRate) / 10
+	loudness := make([]float64, len(power)-size+1)
+	sum := 0.0
+	for i, p := range power {
+		sum += p
+		if i >= size {
+			sum -= power[i-size]
+		}
+		if i >= size-1 {
+			loudness[i-size+1] = toLUFS(sum / samples)
+		}
+	}
+	return loudness
+}
+
+// toLUFS converts the weighted mean square to loudness
+func toLUFS(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return dft.DefaultDBFloor
+	}
+	return math.Max(-0.691+10*math.Log10(meanSquare), dft.DefaultDBFloor)
+}
+
+func fromLUFS(l float64) float64 {
+	return math.Pow(10, (l+0.691)/10)
+}
+
+func maxLoudness(values []float64) float64 {
+	m := dft.DefaultDBFloor
+	for _, v := range values {
+		m = math.Max(m, v)
+	}
+	return m
+}
+
+// gated returns the values above the absolute gate and above the relative
+// gate below their power mean
+func gated(values []float64, relative float64) []float64 {
+	var above []float64
+	sum := 0.0
+	for _, v := range values {
+		if v > absoluteGate {
+			above = append(above, v)
+			sum += fromLUFS(v)
+		}
+	}
+	if len(above) == 0 {
+		return nil
+	}
+	threshold := toLUFS(sum/float64(len(above))) + relative
+	kept := above[:0]
+	for _, v := range above {
+		if v > threshold {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// gatedLoudness returns the power mean of the gated values
+func gatedLoudness(values []float64, relative float64) float64 {
+	kept := gated(values, relative)
+	if len(kept) == 0 {
+		return dft.DefaultDBFloor
+	}
+	sum := 0.0
+	for _, v := range kept {
+		sum += fromLUFS(v)
+	}
+	return toLUFS(sum / float64(len(kept)))
+}
+
+// loudnessRange returns the difference of the 95th and the 10th percentile
+// of the gated short-term loudness (EBU Tech 3342)
+func loudnessRange(shortTerm []float64) float64 {
+	kept := gated(shortTerm, rangeGate)
+	if len(kept) == 0 {
+		return 0
+	}
+	sort.Float64s(kept)
+	percentile := func(p float64) float64 {
+		return kept[int(math.Round(p*float64(len(kept)-1)))]
+	}
+	return percentile(0.95) - percentile(0.10)
+}
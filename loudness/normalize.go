@@ -0,0 +1,141 @@
+package loudness
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// NormalizationMode is the level measure that Normalize brings to a target
+type NormalizationMode int
+
+const (
+	NoNormalization   NormalizationMode = iota
+	PeakNormalization                   // highest absolute sample value in dBFS
+	RMSNormalization                    // RMS level in dBFS, a full scale sine is -3 dBFS
+	LUFSNormalization                   // integrated loudness in LUFS
+)
+
+var normalizationNames = map[NormalizationMode]string{
+	NoNormalization:   "none",
+	PeakNormalization: "peak",
+	RMSNormalization:  "rms",
+	LUFSNormalization: "lufs",
+}
+
+// defaultTargets are the targets of the modes without an explicit one
+var defaultTargets = map[NormalizationMode]float64{
+	PeakNormalization: -1,
+	RMSNormalization:  -20,
+	LUFSNormalization: TargetLUFS,
+}
+
+// Normalization brings recordings to a common level, so that recordings at
+// different levels have comparable magnitude spectra
+type Normalization struct {
+	Mode     NormalizationMode
+	TargetDB float64 // in dBFS, or LUFS for LUFSNormalization
+}
+
+// String returns the mode and the target, e.g. "lufs:-23", or "none"
+func (n Normalization) String() string {
+	if n.Mode == NoNormalization {
+		return normalizationNames[n.Mode]
+	}
+	return fmt.Sprintf("%s:%g", normalizationNames[n.Mode], n.TargetDB)
+}
+
+// ParseNormalization parses a normalization as returned by
+// Normalization.String, e.g. "peak:-3" or "lufs". Without a target peak
+// normalizes to -1 dBFS, rms to -20 dBFS and lufs to -23 LUFS. An empty
+// string is "none".
+func ParseNormalization(s string) (Normalization, error) {
+	name, target, hasTarget := strings.Cut(strings.ToLower(strings.TrimSpace(s)), ":")
+	if name == "" {
+		name = "none"
+	}
+	for mode, n := range normalizationNames {
+		if n != name {
+			continue
+		}
+		norm := Normalization{Mode: mode, TargetDB: defaultTargets[mode]}
+		if hasTarget {
+			if mode == NoNormalization {
+				return Normalization{}, fmt.Errorf("the normalization none has no target")
+			}
+			v, err := strconv.ParseFloat(target, 64)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+				return Normalization{}, fmt.Errorf("invalid target %q of the %s normalization", target, name)
+			}
+			norm.TargetDB = v
+		}
+		return norm, nil
+	}
+	return Normalization{}, fmt.Errorf("unknown normalization %q", s)
+}
+
+// Level returns the level of the channels in the measure of the mode, in
+// dBFS or LUFS. The peak and RMS levels are those of the loudest channel.
+func (n Normalization) Level(channels [][]float64, sampleRate int) (float64, error) {
+	if len(channels) == 0 || len(channels[0]) == 0 {
+		return 0, fmt.Errorf("no samples")
+	}
+	level := 0.0
+	switch n.Mode {
+	case PeakNormalization:
+		for _, x := range channels {
+			for _, v := range x {
+				level = math.Max(level, math.Abs(v))
+			}
+		}
+		level = 20 * math.Log10(level)
+	case RMSNormalization:
+		for _, x := range channels {
+			sum := 0.0
+			for _, v := range x {
+				sum += v * v
+			}
+			level = math.Max(level, sum/float64(len(x)))
+		}
+		level = 10 * math.Log10(level)
+	case LUFSNormalization:
+		r, err := Measure(channels, sampleRate, nil)
+		if err != nil {
+			return 0, err
+		}
+		if r.Integrated <= absoluteGate {
+			return 0, fmt.Errorf("the loudness cannot be measured, the recording is too short or below %g LUFS", absoluteGate)
+		}
+		return r.Integrated, nil
+	default:
+		return 0, fmt.Errorf("no level for the normalization %s", n)
+	}
+	if level <= dft.DefaultDBFloor {
+		return 0, fmt.Errorf("the recording is silent")
+	}
+	return level, nil
+}
+
+// Normalize scales the channels in place by a common gain that brings their
+// level to the target and returns the gain in dB. NoNormalization leaves the
+// channels unchanged and returns 0.
+func Normalize(channels [][]float64, sampleRate int, n Normalization) (gainDB float64, err error) {
+	if n.Mode == NoNormalization {
+		return 0, nil
+	}
+	level, err := n.Level(channels, sampleRate)
+	if err != nil {
+		return 0, fmt.Errorf("failed to normalize: %w", err)
+	}
+	gainDB = n.TargetDB - level
+	gain := math.Pow(10, gainDB/20)
+	for _, x := range channels {
+		for i := range x {
+			x[i] *= gain
+		}
+	}
+	return gainDB, nil
+}
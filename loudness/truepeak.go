@@ -0,0 +1,152 @@
+package loudness
+
+import (
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+const (
+	oversampling = 4  // interpolated values per sample
+	phaseTaps    = 12 // taps of each phase of the interpolation filter
+)
+
+// interpolator holds the polyphase filter of the true peak meter: a 48 tap
+// Kaiser windowed sinc interpolating 4 values per sample, as suggested by
+// BS.1770 Annex 2. Phase p computes the value p/4 of a sample after the
+// oldest of the newest phaseTaps/2 input samples.
+var interpolator = func() [oversampling][phaseTaps]float64 {
+	var phases [oversampling][phaseTaps]float64
+	n := oversampling * phaseTaps
+	window := dft.Window{Type: dft.Kaiser, Beta: 5}.Coefficients(n)
+	center := float64(n-1) / 2
+	for i := range n {
+		x := (float64(i) - center) / oversampling
+		h := 1.0
+		if x != 0 {
+			h = math.Sin(math.Pi*x) / (math.Pi * x)
+		}
+		phases[i%oversampling][i/oversampling] = h * window[i]
+	}
+	// Every phase passes DC with unity gain
+	for p := range phases {
+		sum := 0.0
+		for _, h := range phases[p] {
+			sum += h
+		}
+		for k := range phases[p] {
+			phases[p][k] /= sum
+		}
+	}
+	return phases
+}()
+
+// ChannelPeak are the peak levels of a channel
+type ChannelPeak struct {
+	SamplePeak float64 `json:"sample_peak_dbfs"` // highest absolute sample value in dBFS
+	TruePeak   float64 `json:"true_peak_dbtp"`   // highest level of the reconstructed signal in dBTP
+	// InterSampleOvers counts the interpolated values above full scale
+	// between samples within full scale, the clipping a sample peak meter
+	// misses and a D/A converter or lossy encoder produces
+	InterSampleOvers int `json:"inter_sample_overs"`
+}
+
+// TruePeakMeter measures the sample and true peak levels of a multichannel
+// stream block by block. The true peak is the maximum of the signal
+// oversampled 4 times, which reveals the peaks between the samples that
+// exceed the sample peak by up to 3 dB at high frequencies.
+type TruePeakMeter struct {
+	history [][]float64 // last phaseTaps samples of each channel, oldest first
+	samples []float64   // highest absolute sample values
+	peaks   []float64   // highest absolute interpolated values
+	overs   []int
+	filled  []int // samples in the history, up to phaseTaps
+}
+
+// NewTruePeakMeter returns a meter for the given number of channels
+func NewTruePeakMeter(channels int) *TruePeakMeter {
+	m := &TruePeakMeter{
+		history: make([][]float64, channels),
+		samples: make([]float64, channels),
+		peaks:   make([]float64, channels),
+		overs:   make([]int, channels),
+		filled:  make([]int, channels),
+	}
+	for c := range m.history {
+		m.history[c] = make([]float64, phaseTaps)
+	}
+	return m
+}
+
+// Write meters the next block of samples of each channel. The blocks of all
+// channels must have the same length. The values between the first and the
+// last phaseTaps/2 samples of the stream are not interpolated.
+func (m *TruePeakMeter) Write(block [][]float64) {
+	for c, x := range block {
+		if c >= len(m.history) {
+			break
+		}
+		h := m.history[c]
+		for _, v := range x {
+			copy(h, h[1:])
+			h[phaseTaps-1] = v
+			m.samples[c] = math.Max(m.samples[c], math.Abs(v))
+			// Interpolating across the start of the stream would measure
+			// the ringing of a step from silence
+			if m.filled[c] < phaseTaps {
+				m.filled[c]++
+				continue
+			}
+			// The interpolated values lie between the two middle samples
+			inside := math.Abs(h[phaseTaps/2-1]) <= 1 && math.Abs(h[phaseTaps/2]) <= 1
+			for p := range interpolator {
+				y := 0.0
+				for k, coeff := range interpolator[p] {
+					y += coeff * h[phaseTaps-1-k]
+				}
+				y = math.Abs(y)
+				m.peaks[c] = math.Max(m.peaks[c], y)
+				if y > 1 && inside && p > 0 {
+					m.overs[c]++
+				}
+			}
+		}
+	}
+}
+
+// Peaks returns the peak levels of each channel so far. The true peak is at
+// least the sample peak.
+func (m *TruePeakMeter) Peaks() []ChannelPeak {
+	peaks := make([]ChannelPeak, len(m.history))
+	for c := range peaks {
+		peaks[c] = ChannelPeak{
+			SamplePeak:       toDB(m.samples[c]),
+			TruePeak:         toDB(math.Max(m.samples[c], m.peaks[c])),
+			InterSampleOvers: m.overs[c],
+		}
+	}
+	return peaks
+}
+
+func toDB(amplitude float64) float64 {
+	if amplitude == 0 {
+		return dft.DefaultDBFloor
+	}
+	return math.Max(20*math.Log10(amplitude), dft.DefaultDBFloor)
+}
+
+// Peaks returns the sample and true peak levels of each channel
+func Peaks(channels [][]float64) []ChannelPeak {
+	m := NewTruePeakMeter(len(channels))
+	m.Write(channels)
+	return m.Peaks()
+}
+
+// TruePeak returns the highest true peak level of the channels in dBTP
+func TruePeak(channels [][]float64) float64 {
+	peak := dft.DefaultDBFloor
+	for _, p := range Peaks(channels) {
+		peak = math.Max(peak, p.TruePeak)
+	}
+	return peak
+}
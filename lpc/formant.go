@@ -0,0 +1,161 @@
+package lpc
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/filter"
+	"github.com/epikur-io/go-discrete-fourier-transform/resample"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Formant is a resonance of the vocal tract
+type Formant struct {
+	FreqHz      float64 `json:"freq_hz"`
+	BandwidthHz float64 `json:"bandwidth_hz"`
+}
+
+// FormantOptions configures Formants and Track
+type FormantOptions struct {
+	// MaxHz is the upper limit of the formants. The signal is resampled to
+	// twice MaxHz so the model spends its poles on the formant range: 5000 Hz
+	// suits male, 5500 Hz female voices.
+	MaxHz float64
+	// Order of the LPC model, 0 for 2 + 2·MaxHz/1000, a pole pair per kHz
+	// plus two for the glottal source and radiation
+	Order        int
+	PreEmphasis  float64 // coefficient of the first order pre-emphasis, 0 to disable
+	MinHz        float64 // lower limit of the formants
+	MaxBandwidth float64 // poles with a larger bandwidth in Hz are not formants
+	Count        int     // largest number of formants returned per frame
+}
+
+// DefaultFormantOptions finds up to four formants below 5500 Hz
+var DefaultFormantOptions = FormantOptions{
+	MaxHz:        5500,
+	PreEmphasis:  0.97,
+	MinHz:        90,
+	MaxBandwidth: 400,
+	Count:        4,
+}
+
+func (o FormantOptions) order() int {
+	if o.Order > 0 {
+		return o.Order
+	}
+	return 2 + int(math.Round(2*o.MaxHz/1000))
+}
+
+// Formants returns the formants of a model computed at sampleRate, the
+// resonances of its poles ordered by frequency. A pole at z = r·e^jθ is a
+// resonance at θ·sampleRate/2π Hz with a bandwidth of -ln(r)·sampleRate/π Hz.
+func (m Model) Formants(sampleRate int, opts FormantOptions) ([]Formant, error) {
+	roots, err := Roots(m.Coeffs)
+	if err != nil {
+		return nil, err
+	}
+	var formants []Formant
+	for _, z := range roots {
+		if imag(z) <= 0 {
+			continue // each pair is counted once
+		}
+		f := Formant{
+			FreqHz:      cmplx.Phase(z) * float64(sampleRate) / (2 * math.Pi),
+			BandwidthHz: -math.Log(cmplx.Abs(z)) * float64(sampleRate) / math.Pi,
+		}
+		if f.FreqHz >= opts.MinHz && f.FreqHz <= opts.MaxHz && f.BandwidthHz <= opts.MaxBandwidth {
+			formants = append(formants, f)
+		}
+	}
+	sort.Slice(formants, func(i, j int) bool { return formants[i].FreqHz < formants[j].FreqHz })
+	if opts.Count > 0 && len(formants) > opts.Count {
+		formants = formants[:opts.Count]
+	}
+	return formants, nil
+}
+
+// Roots returns the roots of the polynomial c[0]·z^p + c[1]·z^(p-1) + ... +
+// c[p], e.g. the poles of a model for its coefficients, as the eigenvalues of
+// the companion matrix
+func Roots(c []float64) ([]complex128, error) {
+	for len(c) > 0 && c[0] == 0 {
+		c = c[1:]
+	}
+	p := len(c) - 1
+	if p < 1 {
+		return nil, nil
+	}
+	companion := mat.NewDense(p, p, nil)
+	for j := range p {
+		companion.Set(0, j, -c[j+1]/c[0])
+	}
+	for i := 1; i < p; i++ {
+		companion.Set(i, i-1, 1)
+	}
+	var eig mat.Eigen
+	if !eig.Factorize(companion, mat.EigenNone) {
+		return nil, fmt.Errorf("the roots of the polynomial did not converge")
+	}
+	return eig.Values(nil), nil
+}
+
+// FormantFrame are the formants of a single frame
+type FormantFrame struct {
+	Time     float64   `json:"time_s"` // center of the frame in seconds
+	Formants []Formant `json:"formants"`
+}
+
+// Track returns the formants of each frame of samples. Frames are laid out
+// as in dft.STFT at the original sample rate, so the track lines up with
+// spectrograms and pitch contours; frameSize is typically 25 ms and hopSize
+// 10 ms. Silent frames have no formants.
+func Track(samples []float64, sampleRate, frameSize, hopSize int, opts FormantOptions) ([]FormantFrame, error) {
+	if opts.MaxHz <= 0 || opts.MaxHz > float64(sampleRate)/2 {
+		return nil, fmt.Errorf("the formant limit %g Hz is not below the Nyquist frequency of %d Hz", opts.MaxHz, sampleRate)
+	}
+	if frameSize <= 0 || hopSize <= 0 {
+		return nil, fmt.Errorf("invalid frame size %d or hop size %d", frameSize, hopSize)
+	}
+	rate := int(math.Round(2 * opts.MaxHz))
+	x := samples
+	if rate != sampleRate {
+		var err error
+		if x, err = resample.Resample(samples, sampleRate, rate); err != nil {
+			return nil, err
+		}
+	}
+	if opts.PreEmphasis != 0 {
+		var err error
+		if x, err = filter.PreEmphasis(x, opts.PreEmphasis); err != nil {
+			return nil, err
+		}
+	}
+
+	scale := float64(rate) / float64(sampleRate)
+	size := max(2, int(math.Round(float64(frameSize)*scale)))
+	order := min(opts.order(), size-1)
+	n := len(dft.Frames(samples, frameSize, hopSize))
+	frames := make([]FormantFrame, n)
+	for i := range frames {
+		frames[i].Time = dft.FrameTime(i, sampleRate, frameSize, hopSize)
+		start := int(math.Round(float64(i*hopSize) * scale))
+		end := min(len(x), start+size)
+		if end-start <= order {
+			continue
+		}
+		m, err := Analyze(x[start:end], order)
+		if err != nil {
+			return nil, err
+		}
+		if m.Error == 0 {
+			continue
+		}
+		if frames[i].Formants, err = m.Formants(rate, opts); err != nil {
+			return nil, err
+		}
+	}
+	return frames, nil
+}
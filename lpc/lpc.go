@@ -0,0 +1,103 @@
+// Package lpc implements linear predictive coding: the all-pole model of a
+// signal frame that predicts each sample from the previous ones, and the
+// formant frequencies of speech derived from it.
+package lpc
+
+import (
+	"fmt"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Model is the all-pole model 1/A(z) of a frame, with
+// A(z) = 1 + a[1]·z^-1 + ... + a[p]·z^-p
+type Model struct {
+	Coeffs     []float64 // a[0..p] with a[0] = 1
+	Reflection []float64 // reflection (PARCOR) coefficients k[1..p]
+	Error      float64   // power of the prediction error
+}
+
+// Order returns the number of poles of the model
+func (m Model) Order() int { return len(m.Coeffs) - 1 }
+
+// Analyze computes the LPC model of a frame with the autocorrelation method:
+// the frame is Hamming windowed, its autocorrelation is taken and the normal
+// equations are solved with Levinson–Durbin. The model is always stable.
+func Analyze(frame []float64, order int) (Model, error) {
+	if order < 1 || order >= len(frame) {
+		return Model{}, fmt.Errorf("invalid order %d for a frame of %d samples", order, len(frame))
+	}
+	x := make([]float64, len(frame))
+	copy(x, frame)
+	dft.Window{Type: dft.Hamming}.Apply(x)
+	return Levinson(dft.AutoCorr(x)[:order+1], order)
+}
+
+// Levinson solves the normal equations of linear prediction for the
+// autocorrelation r[0..order] with the Levinson–Durbin recursion in O(p²).
+// A silent frame (r[0] = 0) results in the trivial model A(z) = 1.
+func Levinson(r []float64, order int) (Model, error) {
+	if order < 1 || len(r) < order+1 {
+		return Model{}, fmt.Errorf("need %d autocorrelation lags for order %d, got %d", order+1, order, len(r))
+	}
+	m := Model{
+		Coeffs:     make([]float64, order+1),
+		Reflection: make([]float64, order),
+		Error:      r[0],
+	}
+	m.Coeffs[0] = 1
+	if r[0] <= 0 {
+		return m, nil
+	}
+	prev := make([]float64, order+1)
+	for i := 1; i <= order; i++ {
+		acc := r[i]
+		for j := 1; j < i; j++ {
+			acc += m.Coeffs[j] * r[i-j]
+		}
+		k := -acc / m.Error
+		copy(prev, m.Coeffs)
+		for j := 1; j < i; j++ {
+			m.Coeffs[j] = prev[j] + k*prev[i-j]
+		}
+		m.Coeffs[i] = k
+		m.Reflection[i-1] = k
+		m.Error *= 1 - k*k
+		// A numerically singular autocorrelation, e.g. of a pure tone,
+		// is predicted perfectly by the lower order
+		if m.Error <= r[0]*1e-12 {
+			m.Error = math.Max(m.Error, 0)
+			break
+		}
+	}
+	return m, nil
+}
+
+// Residual filters samples with A(z), the prediction error of the model
+func (m Model) Residual(samples []float64) []float64 {
+	e := make([]float64, len(samples))
+	for n := range samples {
+		for j, a := range m.Coeffs {
+			if n-j < 0 {
+				break
+			}
+			e[n] += a * samples[n-j]
+		}
+	}
+	return e
+}
+
+// Envelope returns the power spectrum Error/|A(e^jw)|² of the model at the
+// fftSize/2+1 frequencies of an fftSize point FFT, the smooth spectral
+// envelope of the frame
+func (m Model) Envelope(fftSize int) []float64 {
+	a := make([]float64, max(fftSize, len(m.Coeffs)))
+	copy(a, m.Coeffs)
+	spectrum := dft.Forward(a)[:fftSize/2+1]
+	env := make([]float64, len(spectrum))
+	for k, c := range spectrum {
+		env[k] = m.Error / (real(c)*real(c) + imag(c)*imag(c))
+	}
+	return env
+}
@@ -0,0 +1,186 @@
+package measure
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// CompareOptions configures Compare
+type CompareOptions struct {
+	FFTSize        int     // frame size of the averaged spectra, sets the frequency resolution
+	MaxDelay       float64 // largest delay between the signals searched for in seconds
+	BandsPerOctave int     // width of the reported bands, e.g. 3 for third octaves
+	MinHz, MaxHz   float64 // frequency range of the bands, MaxHz 0 extends to the Nyquist frequency
+}
+
+// DefaultCompareOptions reports third octave bands from 20 Hz with 8192 point
+// spectra and delays of up to a second
+var DefaultCompareOptions = CompareOptions{FFTSize: 8192, MaxDelay: 1, BandsPerOctave: 3, MinHz: 20}
+
+// BandDeviation is the level difference of the test signal in a band
+type BandDeviation struct {
+	CenterHz    float64 `json:"center_hz"`
+	LowHz       float64 `json:"low_hz"`
+	HighHz      float64 `json:"high_hz"`
+	LevelDB     float64 `json:"level_db"`     // level difference of test and reference in the band
+	DeviationDB float64 `json:"deviation_db"` // LevelDB relative to Comparison.GainDB
+	Coherence   float64 `json:"coherence"`    // mean coherence in the band, near 1 if test is a linear function of the reference
+}
+
+// Comparison is the result of Compare
+type Comparison struct {
+	SampleRate     int
+	Delay          float64 // seconds by which the test signal lags the reference
+	GainDB         float64 // median level difference of the bands, the gain of a chain
+	Freqs          []float64
+	TransferDB     []float64 // magnitude of the transfer function from reference to test per bin
+	TransferPhase  []float64 // phase of the transfer function in radians, after removing the delay
+	Coherence      []float64 // magnitude squared coherence per bin in [0..1]
+	Bands          []BandDeviation
+	MaxDeviationDB float64 // largest absolute DeviationDB
+}
+
+// Compare aligns test to ref by cross-correlation and compares their spectra,
+// e.g. the input and output of an audio chain. The transfer function is the
+// H1 estimate, the averaged cross spectrum divided by the averaged spectrum of
+// the reference. It is only meaningful where the coherence is high, i.e. where
+// test is a linear function of ref; the band levels compare the averaged
+// spectra and also suit unrelated recordings such as two takes.
+func Compare(ref, test []float64, sampleRate int, opts CompareOptions) (Comparison, error) {
+	if sampleRate <= 0 || opts.FFTSize < 2 {
+		return Comparison{}, fmt.Errorf("invalid sample rate %d or FFT size %d", sampleRate, opts.FFTSize)
+	}
+	delay := dft.EstimateDelay(ref, test, int(opts.MaxDelay*float64(sampleRate)))
+	ref, test = align(ref, test, int(math.Round(delay)))
+	if len(ref) < opts.FFTSize {
+		return Comparison{}, fmt.Errorf("the aligned signals overlap by %d samples, less than the FFT size %d", len(ref), opts.FFTSize)
+	}
+
+	pxx, pyy, pxy := crossSpectra(ref, test, opts.FFTSize)
+	c := Comparison{
+		SampleRate:    sampleRate,
+		Delay:         delay / float64(sampleRate),
+		Freqs:         make([]float64, len(pxx)),
+		TransferDB:    make([]float64, len(pxx)),
+		TransferPhase: make([]float64, len(pxx)),
+		Coherence:     make([]float64, len(pxx)),
+	}
+	res := float64(sampleRate) / float64(opts.FFTSize)
+	var totalX, totalY float64
+	for k := range pxx {
+		c.Freqs[k] = float64(k) * res
+		totalX += pxx[k]
+		totalY += pyy[k]
+		if pxx[k] == 0 || pyy[k] == 0 {
+			c.TransferDB[k] = math.Inf(-1)
+			continue
+		}
+		h := pxy[k] / complex(pxx[k], 0)
+		c.TransferDB[k] = 20 * math.Log10(cmplx.Abs(h))
+		// The integer part of the delay was removed by the alignment
+		c.TransferPhase[k] = cmplx.Phase(h * cmplx.Rect(1, 2*math.Pi*c.Freqs[k]*(delay-math.Round(delay))/float64(sampleRate)))
+		c.Coherence[k] = cmplx.Abs(pxy[k]) * cmplx.Abs(pxy[k]) / (pxx[k] * pyy[k])
+	}
+	if totalX == 0 || totalY == 0 {
+		return Comparison{}, fmt.Errorf("a signal is silent")
+	}
+	c.GainDB = 10 * math.Log10(totalY/totalX)
+
+	maxHz := opts.MaxHz
+	if maxHz <= 0 || maxHz > float64(sampleRate)/2 {
+		maxHz = float64(sampleRate) / 2
+	}
+	for _, b := range fractionalOctaves(opts.BandsPerOctave, opts.MinHz, maxHz) {
+		var sx, sy, coh float64
+		n := 0
+		for k := int(math.Ceil(b.LowHz / res)); k < len(pxx) && float64(k)*res < b.HighHz; k++ {
+			sx += pxx[k]
+			sy += pyy[k]
+			coh += c.Coherence[k]
+			n++
+		}
+		if n == 0 || sx == 0 || sy == 0 {
+			continue
+		}
+		b.LevelDB = 10 * math.Log10(sy/sx)
+		b.Coherence = coh / float64(n)
+		c.Bands = append(c.Bands, b)
+	}
+
+	// The median ignores bands outside the passband of the chain
+	if len(c.Bands) > 0 {
+		levels := make([]float64, len(c.Bands))
+		for i, b := range c.Bands {
+			levels[i] = b.LevelDB
+		}
+		sort.Float64s(levels)
+		c.GainDB = levels[len(levels)/2]
+		if len(levels)%2 == 0 {
+			c.GainDB = (levels[len(levels)/2-1] + levels[len(levels)/2]) / 2
+		}
+	}
+	for i := range c.Bands {
+		c.Bands[i].DeviationDB = c.Bands[i].LevelDB - c.GainDB
+		c.MaxDeviationDB = math.Max(c.MaxDeviationDB, math.Abs(c.Bands[i].DeviationDB))
+	}
+	return c, nil
+}
+
+// align drops the samples of ref and test that have no counterpart when test
+// lags ref by delay samples and returns the overlapping parts
+func align(ref, test []float64, delay int) ([]float64, []float64) {
+	if delay > 0 {
+		test = test[min(delay, len(test)):]
+	} else {
+		ref = ref[min(-delay, len(ref)):]
+	}
+	n := min(len(ref), len(test))
+	return ref[:n], test[:n]
+}
+
+// crossSpectra returns the Welch averaged power spectra of x and y and their
+// cross spectrum conj(X)·Y over Hann windowed frames with 50% overlap
+func crossSpectra(x, y []float64, fftSize int) (pxx, pyy []float64, pxy []complex128) {
+	bins := fftSize/2 + 1
+	pxx = make([]float64, bins)
+	pyy = make([]float64, bins)
+	pxy = make([]complex128, bins)
+	window := dft.Window{Type: dft.Hanning}.Coefficients(fftSize)
+	bx := make([]float64, fftSize)
+	by := make([]float64, fftSize)
+	for start := 0; start+fftSize <= len(x); start += fftSize / 2 {
+		for i, w := range window {
+			bx[i] = x[start+i] * w
+			by[i] = y[start+i] * w
+		}
+		cx := dft.Forward(bx)
+		cy := dft.Forward(by)
+		for k := range pxx {
+			pxx[k] += real(cx[k])*real(cx[k]) + imag(cx[k])*imag(cx[k])
+			pyy[k] += real(cy[k])*real(cy[k]) + imag(cy[k])*imag(cy[k])
+			pxy[k] += cmplx.Conj(cx[k]) * cy[k]
+		}
+	}
+	return pxx, pyy, pxy
+}
+
+// fractionalOctaves returns the bands of 1/n octave centered on 1 kHz (base
+// 2) whose centers lie between minHz and maxHz
+func fractionalOctaves(n int, minHz, maxHz float64) []BandDeviation {
+	n = max(n, 1)
+	half := math.Pow(2, 1/(2*float64(n)))
+	var bands []BandDeviation
+	first := int(math.Ceil(float64(n) * math.Log2(math.Max(minHz, 1)/1000)))
+	for k := first; ; k++ {
+		fc := 1000 * math.Pow(2, float64(k)/float64(n))
+		if fc > maxHz {
+			break
+		}
+		bands = append(bands, BandDeviation{CenterHz: fc, LowHz: fc / half, HighHz: fc * half})
+	}
+	return bands
+}
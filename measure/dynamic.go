@@ -0,0 +1,76 @@
+package measure
+
+import (
+	"fmt"
+	"math"
+)
+
+// ToneMetrics is the result of AnalyzeTone, all ratios are in dB relative to the fundamental
+type ToneMetrics struct {
+	Fundamental float64 // measured fundamental frequency in Hz
+	SNR         float64 // signal to noise ratio, harmonics excluded
+	SINAD       float64 // signal to noise and distortion ratio
+	SFDR        float64 // spurious-free dynamic range, distance to the strongest spur in dBc
+	SpurFreq    float64 // frequency of the strongest spur in Hz
+	ENOB        float64 // effective number of bits derived from SINAD
+}
+
+// AnalyzeTone computes ADC/DAC style dynamic range metrics of a single tone capture.
+//
+// The fundamental is located like in THD. The given number of harmonics is
+// treated as distortion and excluded from the SNR. ENOB is computed as
+// (SINAD - 1.76) / 6.02 and therefore only represents the converter if the
+// tone is close to full scale. Unlike THD the spectrum is Blackman-Harris
+// windowed, whose sidelobes below -92 dB keep the leakage of a tone between
+// two bins from masking spurs and noise.
+func AnalyzeTone(samples []float64, sampleRate int, fundamentalHz float64, harmonics int) (ToneMetrics, error) {
+	if len(samples) < blackmanHarrisWindow.minSamples() {
+		return ToneMetrics{}, fmt.Errorf("not enough samples")
+	}
+
+	p := newPowerSpectrum(samples, sampleRate, blackmanHarrisWindow)
+	fund, err := p.fundamental(fundamentalHz)
+	if err != nil {
+		return ToneMetrics{}, err
+	}
+
+	fundPower := p.tonePower(fund)
+	harmPower := 0.0
+	for _, bin := range p.harmonicBins(fund, harmonics) {
+		harmPower += p.tonePower(bin)
+	}
+	noiseDist := p.totalPower() - fundPower
+	noise := noiseDist - harmPower
+	if noiseDist <= 0 || noise <= 0 {
+		return ToneMetrics{}, fmt.Errorf("no noise floor, the capture is not measurable")
+	}
+
+	// Strongest spur outside of the fundamental lobe. tonePower sums the
+	// lobe of the spur, so the spur must be a whole lobe width away from the
+	// fundamental lobe, or its sum would include the skirt of the fundamental.
+	spur := -1
+	for i := p.lobe + 1; i < len(p.power); i++ {
+		if i >= fund-2*p.lobe && i <= fund+2*p.lobe {
+			continue
+		}
+		if spur < 0 || p.power[i] > p.power[spur] {
+			spur = i
+		}
+	}
+	sfdr := math.Inf(1)
+	spurFreq := 0.0
+	if spur >= 0 && p.tonePower(spur) > 0 {
+		sfdr = ratioDB(fundPower / p.tonePower(spur))
+		spurFreq = float64(spur) * p.freqRes
+	}
+
+	sinad := ratioDB(fundPower / noiseDist)
+	return ToneMetrics{
+		Fundamental: float64(fund) * p.freqRes,
+		SNR:         ratioDB(fundPower / noise),
+		SINAD:       sinad,
+		SFDR:        sfdr,
+		SpurFreq:    spurFreq,
+		ENOB:        (sinad - 1.76) / 6.02,
+	}, nil
+}
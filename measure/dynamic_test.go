@@ -0,0 +1,30 @@
+package measure
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestAnalyzeTone(t *testing.T) {
+	const sampleRate, n = 48000, 48000
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, freq := range []float64{1000, 1000.5} {
+		// Noise 70 dB and a spur 80 dB below the tone power of 0.5
+		x := tone(n, sampleRate, freq)
+		for i := range x {
+			ts := float64(i) / sampleRate
+			x[i] += math.Sqrt(0.5*1e-7)*rng.NormFloat64() + 1e-4*math.Sin(2*math.Pi*7321*ts)
+		}
+		m, err := AnalyzeTone(x, sampleRate, freq, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(m.SNR-70) > 1 {
+			t.Errorf("%g Hz: SNR %.2f dB, want 70 dB", freq, m.SNR)
+		}
+		if math.Abs(m.SFDR-80) > 1 || math.Abs(m.SpurFreq-7321) > 1 {
+			t.Errorf("%g Hz: SFDR %.2f dB at %.0f Hz, want 80 dB at 7321 Hz", freq, m.SFDR, m.SpurFreq)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package measure
+
+import (
+	"fmt"
+	"math"
+)
+
+// IMDStandard selects the two-tone intermodulation distortion method
+type IMDStandard int
+
+const (
+	// SMPTE uses a low and a high frequency tone with 4:1 amplitude ratio
+	// (typically 60 Hz and 7 kHz). The sidebands f2±n·f1 around the high
+	// tone are measured relative to the high tone.
+	SMPTE IMDStandard = iota
+	// CCIF uses two closely spaced tones of equal amplitude (typically 19 kHz
+	// and 20 kHz). The difference tone f2-f1 and the 3rd order products 2f1-f2
+	// and 2f2-f1 are measured relative to the sum of both tones.
+	CCIF
+)
+
+func (s IMDStandard) String() string {
+	if s == CCIF {
+		return "CCIF"
+	}
+	return "SMPTE"
+}
+
+// smpteSidebands is the number of sideband pairs f2±n·f1 measured for SMPTE
+const smpteSidebands = 3
+
+// imdMinToneHz is the lowest frequency searched for stimulus tones
+const imdMinToneHz = 20
+
+// IMDProduct is a single intermodulation product
+type IMDProduct struct {
+	Frequency float64 // Hz
+	Level     float64 // dB relative to the reference level of the standard
+}
+
+// Intermodulation is the result of IMD
+type Intermodulation struct {
+	Standard IMDStandard
+	LowTone  float64 // detected low stimulus tone in Hz
+	HighTone float64 // detected high stimulus tone in Hz
+	Products []IMDProduct
+	IMD      float64 // total intermodulation distortion as amplitude ratio
+}
+
+// IMDPercent returns the IMD in percent
+func (m Intermodulation) IMDPercent() float64 { return m.IMD * 100 }
+
+// IMDdB returns the IMD in dB
+func (m Intermodulation) IMDdB() float64 { return 20 * math.Log10(m.IMD) }
+
+// IMD measures the two-tone intermodulation distortion of a capture. The two
+// stimulus tones are detected as the two strongest tones of the spectrum above
+// 20 Hz. Products within two lobe widths of a stimulus tone are skipped, as
+// the skirt of the tone would add to them. If the frequency resolution is too
+// coarse for any product to lie outside, an error is returned.
+func IMD(samples []float64, sampleRate int, standard IMDStandard) (Intermodulation, error) {
+	if len(samples) < blackmanHarrisWindow.minSamples() {
+		return Intermodulation{}, fmt.Errorf("not enough samples")
+	}
+
+	// Without DC the search can start at the low tone of SMPTE, which is only
+	// a few bins above DC at a coarse resolution
+	mean := 0.0
+	for _, v := range samples {
+		mean += v
+	}
+	mean /= float64(len(samples))
+	centered := make([]float64, len(samples))
+	for i, v := range samples {
+		centered[i] = v - mean
+	}
+	p := newPowerSpectrum(centered, sampleRate, blackmanHarrisWindow)
+	start := max(1, int(math.Ceil(imdMinToneHz/p.freqRes)))
+
+	first := -1
+	for i := start; i < len(p.power); i++ {
+		if first < 0 || p.power[i] > p.power[first] {
+			first = i
+		}
+	}
+	if first < 0 || p.power[first] == 0 {
+		return Intermodulation{}, fmt.Errorf("no stimulus tones found")
+	}
+	second := -1
+	for i := start; i < len(p.power); i++ {
+		if i >= first-2*p.lobe && i <= first+2*p.lobe {
+			continue
+		}
+		if second < 0 || p.power[i] > p.power[second] {
+			second = i
+		}
+	}
+	if second < 0 || p.power[second] == 0 {
+		return Intermodulation{}, fmt.Errorf("second stimulus tone not found")
+	}
+
+	low, high := first, second
+	if low > high {
+		low, high = high, low
+	}
+
+	var reference float64
+	var bins []int
+	switch standard {
+	case SMPTE:
+		reference = math.Sqrt(p.tonePower(high))
+		for n := 1; n <= smpteSidebands; n++ {
+			bins = append(bins, high-n*low, high+n*low)
+		}
+	case CCIF:
+		reference = math.Sqrt(p.tonePower(low)) + math.Sqrt(p.tonePower(high))
+		bins = append(bins, high-low, 2*low-high, 2*high-low)
+	default:
+		return Intermodulation{}, fmt.Errorf("unknown IMD standard %d", standard)
+	}
+
+	products := []IMDProduct{}
+	sum := 0.0
+	for _, bin := range bins {
+		bin = p.peakBin(bin, 1)
+		if bin <= p.lobe || bin+p.lobe >= len(p.power) {
+			continue
+		}
+		if abs(bin-low) <= 2*p.lobe || abs(bin-high) <= 2*p.lobe {
+			continue
+		}
+		amp := math.Sqrt(p.tonePower(bin))
+		sum += amp * amp
+		products = append(products, IMDProduct{
+			Frequency: float64(bin) * p.freqRes,
+			Level:     20 * math.Log10(amp/reference),
+		})
+	}
+
+	if len(products) == 0 {
+		return Intermodulation{}, fmt.Errorf("a resolution of %.2f Hz does not separate the products from the tones, more samples are needed", p.freqRes)
+	}
+
+	return Intermodulation{
+		Standard: standard,
+		LowTone:  float64(low) * p.freqRes,
+		HighTone: float64(high) * p.freqRes,
+		Products: products,
+		IMD:      math.Sqrt(sum) / reference,
+	}, nil
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
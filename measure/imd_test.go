@@ -0,0 +1,53 @@
+package measure
+
+import (
+	"math"
+	"testing"
+)
+
+// smpte returns 60 Hz and 7 kHz tones with 4:1 amplitudes, the 7 kHz tone
+// amplitude modulated by depth, which gives sidebands of depth/2 at 7 kHz ±
+// 60 Hz, and a DC offset
+func smpte(n, sampleRate int, depth, offset float64) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		t := float64(i) / float64(sampleRate)
+		low := math.Sin(2 * math.Pi * 60 * t)
+		x[i] = offset + 0.8*low + 0.2*(1+depth*low)*math.Sin(2*math.Pi*7000*t)
+	}
+	return x
+}
+
+func TestIMD(t *testing.T) {
+	m, err := IMD(smpte(48000, 48000, 0.01, 0), 48000, SMPTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.LowTone != 60 || m.HighTone != 7000 {
+		t.Errorf("tones at %g Hz and %g Hz, want 60 Hz and 7000 Hz", m.LowTone, m.HighTone)
+	}
+	if want := math.Sqrt2 * 0.005; math.Abs(m.IMD-want) > 0.01*want {
+		t.Errorf("IMD %.4g, want %.4g", m.IMD, want)
+	}
+}
+
+func TestIMDCoarseResolution(t *testing.T) {
+	// At 10 Hz per bin the first sidebands lie within the skirt of the 7 kHz
+	// tone and must not count as intermodulation
+	m, err := IMD(smpte(4800, 48000, 0, 0.1), 48000, SMPTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.LowTone != 60 || m.HighTone != 7000 {
+		t.Errorf("tones at %g Hz and %g Hz, want 60 Hz and 7000 Hz", m.LowTone, m.HighTone)
+	}
+	if m.IMDdB() > -80 {
+		t.Errorf("IMD of %.1f dB without intermodulation", m.IMDdB())
+	}
+
+	// At 20 Hz per bin the 60 Hz tone is 3 bins above DC, no sideband is
+	// resolved
+	if _, err := IMD(smpte(2400, 48000, 0, 0.1), 48000, SMPTE); err == nil {
+		t.Error("no error for unresolved sidebands")
+	}
+}
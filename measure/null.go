@@ -0,0 +1,172 @@
+package measure
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// delayTaps is the length of the windowed sinc filter that shifts the test
+// signal by a fraction of a sample
+const delayTaps = 255
+
+// NullBand is the level of the residual of a null test in a band
+type NullBand struct {
+	CenterHz float64 `json:"center_hz"`
+	LowHz    float64 `json:"low_hz"`
+	HighHz   float64 `json:"high_hz"`
+	DepthDB  float64 `json:"depth_db"` // residual relative to the reference in the band
+}
+
+// NullResult is the result of NullTest
+type NullResult struct {
+	SampleRate int
+	Delay      float64   // seconds by which the test signal lagged the reference
+	GainDB     float64   // gain applied to the test signal to match the level of the reference
+	Inverted   bool      // the polarity of the test signal was inverted
+	Residual   []float64 // the aligned reference minus the aligned and level matched test signal
+	DepthDB    float64   // energy of the residual relative to the reference, the depth of the null
+	Freqs      []float64
+	ResidualDB []float64 // averaged power spectrum of the residual relative to the reference per bin
+	Bands      []NullBand
+}
+
+// NullTest subtracts test from ref after aligning it to a fraction of a
+// sample and matching its level and polarity, and analyzes the residual. Two
+// takes through a transparent codec, cable or converter null deeply; whatever
+// remains is what they changed. The delay is estimated by cross-correlation
+// and the gain by least squares. Clock drift between the takes is not
+// corrected, so both should come from the same clock.
+func NullTest(ref, test []float64, sampleRate int, opts CompareOptions) (NullResult, error) {
+	if sampleRate <= 0 || opts.FFTSize < 2 {
+		return NullResult{}, fmt.Errorf("invalid sample rate %d or FFT size %d", sampleRate, opts.FFTSize)
+	}
+	whole := math.Round(dft.EstimateDelay(ref, test, int(opts.MaxDelay*float64(sampleRate))))
+	ref, test = align(ref, test, int(whole))
+
+	// The delay filter needs half its length of context on both sides
+	edge := delayTaps / 2
+	if len(ref) < opts.FFTSize+2*edge {
+		return NullResult{}, fmt.Errorf("the aligned signals overlap by %d samples, less than the FFT size %d", len(ref), opts.FFTSize)
+	}
+	frac := fractionOfDelay(ref, test, opts.FFTSize)
+	delay := whole + frac
+	test = fractionalDelay(test, frac)
+	ref, test = ref[edge:len(ref)-edge], test[edge:len(test)-edge]
+
+	var rt, tt, rr float64
+	for i := range ref {
+		rt += ref[i] * test[i]
+		tt += test[i] * test[i]
+		rr += ref[i] * ref[i]
+	}
+	if tt == 0 || rr == 0 {
+		return NullResult{}, fmt.Errorf("a signal is silent")
+	}
+	gain := rt / tt
+	r := NullResult{
+		SampleRate: sampleRate,
+		Delay:      delay / float64(sampleRate),
+		GainDB:     20 * math.Log10(math.Abs(gain)),
+		Inverted:   gain < 0,
+		Residual:   make([]float64, len(ref)),
+	}
+	var energy float64
+	for i := range ref {
+		r.Residual[i] = ref[i] - gain*test[i]
+		energy += r.Residual[i] * r.Residual[i]
+	}
+	r.DepthDB = powerRatioDB(energy, rr)
+
+	pref, pres, _ := crossSpectra(ref, r.Residual, opts.FFTSize)
+	res := float64(sampleRate) / float64(opts.FFTSize)
+	r.Freqs = make([]float64, len(pref))
+	r.ResidualDB = make([]float64, len(pref))
+	for k := range pref {
+		r.Freqs[k] = float64(k) * res
+		r.ResidualDB[k] = powerRatioDB(pres[k], pref[k])
+	}
+	maxHz := opts.MaxHz
+	if maxHz <= 0 || maxHz > float64(sampleRate)/2 {
+		maxHz = float64(sampleRate) / 2
+	}
+	for _, b := range fractionalOctaves(opts.BandsPerOctave, opts.MinHz, maxHz) {
+		var sref, sres float64
+		for k := int(math.Ceil(b.LowHz / res)); k < len(pref) && float64(k)*res < b.HighHz; k++ {
+			sref += pref[k]
+			sres += pres[k]
+		}
+		if sref == 0 {
+			continue
+		}
+		r.Bands = append(r.Bands, NullBand{CenterHz: b.CenterHz, LowHz: b.LowHz, HighHz: b.HighHz, DepthDB: powerRatioDB(sres, sref)})
+	}
+	return r, nil
+}
+
+// powerRatioDB returns the power ratio of residual to reference in dB, a
+// perfect null is clamped to dft.DefaultDBFloor
+func powerRatioDB(residual, reference float64) float64 {
+	if reference == 0 {
+		return 0 // nothing to cancel
+	}
+	if residual <= 0 {
+		return dft.DefaultDBFloor
+	}
+	return math.Max(10*math.Log10(residual/reference), dft.DefaultDBFloor)
+}
+
+// fractionOfDelay returns the delay of test relative to ref in samples for
+// signals aligned to within a sample. It fits a line through the phase of
+// their cross spectrum, weighted by its magnitude, which is far more precise
+// than interpolating the peak of the cross-correlation.
+func fractionOfDelay(ref, test []float64, fftSize int) float64 {
+	_, _, pxy := crossSpectra(ref, test, fftSize)
+	// An inverted polarity shifts the phase by pi
+	var dot float64
+	for i := range ref {
+		dot += ref[i] * test[i]
+	}
+	sign := complex(1, 0)
+	if dot < 0 {
+		sign = -1
+	}
+	// test[n] = ref[n-d] has the phase -w·d at the angular frequency w
+	var num, den float64
+	for k, c := range pxy {
+		w := 2 * math.Pi * float64(k) / float64(fftSize)
+		weight := cmplx.Abs(c)
+		num -= weight * w * cmplx.Phase(sign*c)
+		den += weight * w * w
+	}
+	if den == 0 {
+		return 0
+	}
+	return math.Max(-1, math.Min(1, num/den))
+}
+
+// fractionalDelay advances x by d samples (|d| <= 1), y[n] = x[n+d], with
+// a Kaiser windowed sinc filter. The first and last delayTaps/2 samples of
+// the result are inaccurate.
+func fractionalDelay(x []float64, d float64) []float64 {
+	if d == 0 {
+		return x
+	}
+	window := dft.Window{Type: dft.Kaiser, Beta: 8.6}.Coefficients(delayTaps)
+	h := make([]float64, delayTaps)
+	center := delayTaps / 2
+	// Tap i weighs x[n+center-i] in y[n+center], the sinc is sampled at
+	// center-i-d, or i-center+d as it is even
+	for i := range h {
+		t := float64(i-center) + d
+		if t == 0 {
+			h[i] = window[i]
+			continue
+		}
+		h[i] = window[i] * math.Sin(math.Pi*t) / (math.Pi * t)
+	}
+	y := dft.ConvolveOA(x, h)
+	return y[center : center+len(x)]
+}
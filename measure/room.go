@@ -0,0 +1,199 @@
+package measure
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/filter"
+)
+
+// RoomAcoustics are the ISO 3382 parameters of an impulse response in one
+// frequency band. Decay times are 0 if the decay does not cover their range
+// above the noise floor.
+type RoomAcoustics struct {
+	CenterHz float64 // center of the octave band, 0 for the broadband response
+	EDT      float64 // early decay time in seconds, from the 0 to -10 dB decay
+	T20      float64 // reverberation time in seconds, from the -5 to -25 dB decay
+	T30      float64 // reverberation time in seconds, from the -5 to -35 dB decay
+	RT60     float64 // T30, or T20 if the dynamic range is too small for T30
+	C50      float64 // clarity in dB, energy ratio of the first 50 ms to the rest
+	C80      float64 // clarity in dB, energy ratio of the first 80 ms to the rest
+	NoiseDB  float64 // noise floor relative to the peak of the response in dB
+}
+
+// RoomOptions configures AnalyzeRoom
+type RoomOptions struct {
+	// Bands are the center frequencies of the analyzed octave bands. Bands
+	// whose upper edge exceeds the Nyquist frequency are skipped.
+	Bands []float64
+}
+
+// DefaultRoomOptions analyzes the octave bands from 63 Hz to 8 kHz
+var DefaultRoomOptions = RoomOptions{Bands: []float64{63, 125, 250, 500, 1000, 2000, 4000, 8000}}
+
+// AnalyzeRoom computes the reverberation times and clarity indices of an
+// impulse response, e.g. from DeconvolveSweep, broadband and per octave band.
+// The first result is the broadband response. The response should include
+// some noise after the decay, which is estimated from its last tenth and
+// removed before the Schroeder integration.
+func AnalyzeRoom(ir []float64, sampleRate int, opts RoomOptions) ([]RoomAcoustics, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate %d", sampleRate)
+	}
+	if len(ir) < sampleRate/10 {
+		return nil, fmt.Errorf("the impulse response of %d samples is shorter than 100 ms", len(ir))
+	}
+	results := []RoomAcoustics{roomAcoustics(ir, sampleRate)}
+
+	// Pad to avoid that the decay of the zero-phase band filters wraps around
+	padded := make([]float64, 2*len(ir))
+	copy(padded, ir)
+	for _, fc := range opts.Bands {
+		low, high := fc/math.Sqrt2, fc*math.Sqrt2
+		if high >= float64(sampleRate)/2 {
+			continue
+		}
+		band := filter.FilterBand(padded, sampleRate, low, high, filter.BandOptions{
+			Mode:         filter.KeepBand,
+			TransitionHz: (high - low) / 2,
+		})
+		r := roomAcoustics(band[:len(ir)], sampleRate)
+		r.CenterHz = fc
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// roomAcoustics computes the parameters of a single band
+func roomAcoustics(ir []float64, sampleRate int) RoomAcoustics {
+	energy := make([]float64, len(ir))
+	peak := 0.0
+	for i, v := range ir {
+		energy[i] = v * v
+		peak = math.Max(peak, energy[i])
+	}
+	var r RoomAcoustics
+	if peak == 0 {
+		return r
+	}
+
+	// The noise floor is the mean energy of the last tenth
+	tail := energy[len(energy)*9/10:]
+	noise := 0.0
+	for _, e := range tail {
+		noise += e
+	}
+	noise /= float64(len(tail))
+	r.NoiseDB = 10 * math.Log10(math.Max(noise, 1e-300)/peak)
+
+	onset := onset(energy, peak)
+	curve := SchroederCurve(energy[onset:truncation(energy, onset, noise, sampleRate)], noise)
+	r.EDT = decayTime(curve, sampleRate, 0, -10)
+	r.T20 = decayTime(curve, sampleRate, -5, -25)
+	r.T30 = decayTime(curve, sampleRate, -5, -35)
+	r.RT60 = r.T30
+	if r.RT60 == 0 {
+		r.RT60 = r.T20
+	}
+	r.C50 = clarity(energy[onset:], noise, sampleRate, 0.050)
+	r.C80 = clarity(energy[onset:], noise, sampleRate, 0.080)
+	return r
+}
+
+// onset returns the index of the direct sound, where the energy first rises
+// to 20 dB below its peak (ISO 3382-1)
+func onset(energy []float64, peak float64) int {
+	for i, e := range energy {
+		if e >= peak/100 {
+			return i
+		}
+	}
+	return 0
+}
+
+// truncation returns the end of the usable decay: the first 10 ms window
+// after the onset whose mean energy falls to twice the noise floor
+func truncation(energy []float64, onset int, noise float64, sampleRate int) int {
+	window := max(1, sampleRate/100)
+	for start := onset + window; start+window <= len(energy); start += window {
+		sum := 0.0
+		for _, e := range energy[start : start+window] {
+			sum += e
+		}
+		if sum/float64(window) <= 2*noise {
+			return start
+		}
+	}
+	return len(energy)
+}
+
+// SchroederCurve returns the energy decay curve in dB relative to the total
+// energy by backward integration of energy, the squared impulse response,
+// after subtracting the noise energy per sample
+func SchroederCurve(energy []float64, noise float64) []float64 {
+	curve := make([]float64, len(energy))
+	sum := 0.0
+	for i := len(energy) - 1; i >= 0; i-- {
+		sum += math.Max(energy[i]-noise, 0)
+		curve[i] = sum
+	}
+	total := curve[0]
+	for i, c := range curve {
+		if total <= 0 || c <= 0 {
+			curve[i] = math.Inf(-1)
+			continue
+		}
+		curve[i] = 10 * math.Log10(c/total)
+	}
+	return curve
+}
+
+// decayTime fits a line to the decay curve between the levels from and to
+// (dB, from > to) and returns the time of a 60 dB decay at its slope, or 0 if
+// the curve does not fall to the lower level
+func decayTime(curve []float64, sampleRate int, from, to float64) float64 {
+	var sx, sy, sxx, sxy, n float64
+	reached := false
+	for i, level := range curve {
+		if level > from {
+			continue
+		}
+		if level < to {
+			reached = true
+			break
+		}
+		x := float64(i) / float64(sampleRate)
+		sx += x
+		sy += level
+		sxx += x * x
+		sxy += x * level
+		n++
+	}
+	if !reached || n < 2 {
+		return 0
+	}
+	slope := (n*sxy - sx*sy) / (n*sxx - sx*sx) // dB per second
+	if slope >= 0 {
+		return 0
+	}
+	return -60 / slope
+}
+
+// clarity returns the ratio of the energy up to limit seconds after the
+// onset to the energy after it in dB
+func clarity(energy []float64, noise float64, sampleRate int, limit float64) float64 {
+	split := min(len(energy), int(limit*float64(sampleRate)))
+	var early, late float64
+	for i, e := range energy {
+		e = math.Max(e-noise, 0)
+		if i < split {
+			early += e
+		} else {
+			late += e
+		}
+	}
+	if late == 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(early/late)
+}
@@ -0,0 +1,145 @@
+// Package measure implements audio measurements such as harmonic distortion,
+// noise and dynamic range metrics computed from a windowed power spectrum, and
+// impulse response measurements with sweeps and room acoustics parameters.
+package measure
+
+import (
+	"fmt"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// spectrumWindow is the window of a powerSpectrum. lobeHalfWidth is the
+// number of bins on each side of a tone that are counted towards its power,
+// the half width of the main lobe plus one bin for tones that fall between
+// two bins.
+type spectrumWindow struct {
+	apply         func([]float64)
+	lobeHalfWidth int
+}
+
+var (
+	// hanningWindow has a narrow main lobe of ±2 bins, which separates the
+	// harmonics of low fundamentals, and is used for THD
+	hanningWindow = spectrumWindow{dft.ApplyHanningWindow, 3}
+	// blackmanHarrisWindow has a main lobe of ±4 bins, but sidelobes below
+	// -92 dB, so the leakage of a tone does not hide spurs and noise in
+	// dynamic range and intermodulation measurements
+	blackmanHarrisWindow = spectrumWindow{dft.ApplyBlackmanHarrisWindow, 5}
+)
+
+// minSamples returns the number of samples needed for a spectrum with w
+func (w spectrumWindow) minSamples() int {
+	return 2*w.lobeHalfWidth + 2
+}
+
+// powerSpectrum is a windowed power spectrum of a signal
+type powerSpectrum struct {
+	power   []float64 // |X[k]|² of the non-negative frequencies
+	freqRes float64   // bin width in Hz
+	lobe    int       // lobeHalfWidth of the window
+}
+
+func newPowerSpectrum(samples []float64, sampleRate int, window spectrumWindow) powerSpectrum {
+	windowed := append([]float64(nil), samples...)
+	window.apply(windowed)
+
+	spectrum := dft.Forward(windowed)
+	power := make([]float64, len(spectrum))
+	for i, c := range spectrum {
+		power[i] = real(c)*real(c) + imag(c)*imag(c)
+	}
+	return powerSpectrum{
+		power:   power,
+		freqRes: float64(sampleRate) / float64(len(samples)),
+		lobe:    window.lobeHalfWidth,
+	}
+}
+
+// bin returns the bin index nearest to freq
+func (p powerSpectrum) bin(freq float64) int {
+	return int(math.Round(freq / p.freqRes))
+}
+
+// peakBin returns the bin with the highest power within ±radius bins of center
+func (p powerSpectrum) peakBin(center, radius int) int {
+	best := -1
+	for i := center - radius; i <= center+radius; i++ {
+		if i < 1 || i >= len(p.power) {
+			continue
+		}
+		if best < 0 || p.power[i] > p.power[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// strongestBin returns the bin with the highest power above the DC lobe
+func (p powerSpectrum) strongestBin() int {
+	best := -1
+	for i := p.lobe + 1; i < len(p.power); i++ {
+		if best < 0 || p.power[i] > p.power[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// fundamental returns the bin of the fundamental within ±2 bins of freq, or
+// the strongest bin if freq is 0
+func (p powerSpectrum) fundamental(freq float64) (int, error) {
+	fund := p.strongestBin()
+	if freq > 0 {
+		fund = p.peakBin(p.bin(freq), 2)
+	}
+	if fund < 0 {
+		return 0, fmt.Errorf("fundamental %.2f Hz is out of range", freq)
+	}
+	if p.tonePower(fund) == 0 {
+		return 0, fmt.Errorf("no signal at the fundamental")
+	}
+	return fund, nil
+}
+
+// harmonicBins returns the bins of the 2nd up to the given harmonic of fund
+// whose main lobe lies below the Nyquist frequency
+func (p powerSpectrum) harmonicBins(fund, harmonics int) []int {
+	bins := []int{}
+	for h := 2; h <= harmonics; h++ {
+		bin := p.peakBin(fund*h, 1)
+		if bin < 0 || bin+p.lobe >= len(p.power) {
+			break
+		}
+		bins = append(bins, bin)
+	}
+	return bins
+}
+
+// tonePower sums the power of the main lobe around bin. Summing the lobe instead
+// of taking the peak bin accounts for the equivalent noise bandwidth of the
+// window, so tone and noise powers are directly comparable.
+func (p powerSpectrum) tonePower(bin int) float64 {
+	sum := 0.0
+	for i := bin - p.lobe; i <= bin+p.lobe; i++ {
+		if i >= 1 && i < len(p.power) {
+			sum += p.power[i]
+		}
+	}
+	return sum
+}
+
+// totalPower sums the power of all bins above the DC lobe
+func (p powerSpectrum) totalPower() float64 {
+	sum := 0.0
+	for i := p.lobe + 1; i < len(p.power); i++ {
+		sum += p.power[i]
+	}
+	return sum
+}
+
+// ratioDB converts a power ratio to dB
+func ratioDB(ratio float64) float64 {
+	return 10 * math.Log10(ratio)
+}
@@ -0,0 +1,65 @@
+package measure
+
+import (
+	"fmt"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/siggen"
+)
+
+// SweepResponse is the deconvolution of a recorded exponential sweep. The
+// linear impulse response follows time zero, the impulse responses of the
+// harmonic distortion products precede it at the times of
+// Sweep.HarmonicDelay.
+type SweepResponse struct {
+	Sweep      siggen.Sweep
+	Linear     []float64 // impulse response, index 0 is the start of the sweep in the recording
+	full       []float64 // recording convolved with the inverse filter
+	zero       int       // index of time zero in full
+	SampleRate int
+}
+
+// DeconvolveSweep extracts the impulse response of the system that recorded
+// was played through, with Farina's method: the recording is convolved with
+// the inverse filter of the sweep. The recording must start at the same time
+// as the sweep, any latency of the playback and recording chain delays the
+// impulse response. It should continue after the end of the sweep for at
+// least the length of the impulse response, e.g. the reverberation time.
+func DeconvolveSweep(recorded []float64, sweep siggen.Sweep) (SweepResponse, error) {
+	inv, err := sweep.InverseFilter()
+	if err != nil {
+		return SweepResponse{}, err
+	}
+	if len(recorded) < len(inv) {
+		return SweepResponse{}, fmt.Errorf("the recording of %d samples is shorter than the sweep of %d samples", len(recorded), len(inv))
+	}
+	full := dft.ConvolveOA(recorded, inv)
+	zero := len(inv) - 1
+	return SweepResponse{
+		Sweep:      sweep,
+		Linear:     full[zero : zero+len(recorded)-len(inv)+1],
+		full:       full,
+		zero:       zero,
+		SampleRate: sweep.SampleRate,
+	}, nil
+}
+
+// Harmonic returns length samples of the impulse response of the n-th
+// harmonic distortion product, n >= 2. Its frequency axis refers to the
+// excitation frequency, i.e. its spectrum at f is the level of the n-th
+// harmonic at n·f produced by a tone at f. Fewer samples are returned where
+// the response of the next order begins.
+func (r SweepResponse) Harmonic(n, length int) ([]float64, error) {
+	if n < 2 {
+		return nil, fmt.Errorf("invalid harmonic order %d", n)
+	}
+	start := r.zero - int(math.Round(r.Sweep.HarmonicDelay(n)*float64(r.SampleRate)))
+	if start < 0 {
+		return nil, fmt.Errorf("the response of harmonic %d precedes the deconvolution", n)
+	}
+	// The response of order n-1 begins where this one ends
+	end := r.zero - int(math.Round(r.Sweep.HarmonicDelay(n-1)*float64(r.SampleRate)))
+	length = min(length, end-start)
+	return r.full[start : start+length], nil
+}
@@ -0,0 +1,70 @@
+package measure
+
+import (
+	"fmt"
+	"math"
+)
+
+// Distortion is the result of THD
+type Distortion struct {
+	Fundamental float64   // measured fundamental frequency in Hz
+	Harmonics   []float64 // level of each harmonic (2nd, 3rd, ...) relative to the fundamental in dB
+	THD         float64   // total harmonic distortion as amplitude ratio
+	THDN        float64   // total harmonic distortion plus noise as amplitude ratio
+}
+
+// THDPercent returns the THD in percent
+func (d Distortion) THDPercent() float64 { return d.THD * 100 }
+
+// THDNPercent returns the THD+N in percent
+func (d Distortion) THDNPercent() float64 { return d.THDN * 100 }
+
+// THDdB returns the THD in dB
+func (d Distortion) THDdB() float64 { return 20 * math.Log10(d.THD) }
+
+// THDNdB returns the THD+N in dB
+func (d Distortion) THDNdB() float64 { return 20 * math.Log10(d.THDN) }
+
+// THD measures the total harmonic distortion of a single tone capture.
+//
+// The fundamental is searched within ±2 bins of fundamentalHz, or taken as the
+// strongest bin if fundamentalHz is 0. The power of the 2nd up to the given
+// number of harmonics below the Nyquist frequency is summed for THD. For THD+N
+// everything except DC and the fundamental is summed. The spectrum is Hanning
+// windowed, whose narrow main lobe keeps the harmonics of low fundamentals
+// apart.
+func THD(samples []float64, sampleRate int, fundamentalHz float64, harmonics int) (Distortion, error) {
+	if len(samples) < hanningWindow.minSamples() {
+		return Distortion{}, fmt.Errorf("not enough samples")
+	}
+	if harmonics < 2 {
+		return Distortion{}, fmt.Errorf("invalid number of harmonics %d", harmonics)
+	}
+
+	p := newPowerSpectrum(samples, sampleRate, hanningWindow)
+	fund, err := p.fundamental(fundamentalHz)
+	if err != nil {
+		return Distortion{}, err
+	}
+
+	fundPower := p.tonePower(fund)
+	levels := []float64{}
+	harmPower := 0.0
+	for _, bin := range p.harmonicBins(fund, harmonics) {
+		power := p.tonePower(bin)
+		harmPower += power
+		levels = append(levels, ratioDB(power/fundPower))
+	}
+
+	noisePower := p.totalPower() - fundPower
+	if noisePower < 0 {
+		noisePower = 0
+	}
+
+	return Distortion{
+		Fundamental: float64(fund) * p.freqRes,
+		Harmonics:   levels,
+		THD:         math.Sqrt(harmPower / fundPower),
+		THDN:        math.Sqrt(noisePower / fundPower),
+	}, nil
+}
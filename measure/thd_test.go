@@ -0,0 +1,40 @@
+package measure
+
+import (
+	"math"
+	"testing"
+)
+
+// tone returns a sine of amplitude 1 at freq Hz with the given harmonics,
+// given as amplitudes of the 2nd, 3rd, ... harmonic
+func tone(n, sampleRate int, freq float64, harmonics ...float64) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		t := float64(i) / float64(sampleRate)
+		x[i] = math.Sin(2 * math.Pi * freq * t)
+		for h, amp := range harmonics {
+			x[i] += amp * math.Sin(2*math.Pi*freq*float64(h+2)*t)
+		}
+	}
+	return x
+}
+
+func TestTHD(t *testing.T) {
+	// The harmonics of a 30 Hz tone are 6 bins apart, their Blackman-Harris
+	// lobes would overlap
+	for _, freq := range []float64{1000, 1010.5, 30} {
+		x := tone(9600, 48000, freq, 1e-2, 1e-3)
+		d, err := THD(x, 48000, freq, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := math.Hypot(1e-2, 1e-3); math.Abs(d.THD-want) > 0.02*want {
+			t.Errorf("%g Hz: THD %.4g, want %.4g", freq, d.THD, want)
+		}
+		for i, want := range []float64{-40, -60} {
+			if math.Abs(d.Harmonics[i]-want) > 0.2 {
+				t.Errorf("%g Hz: harmonic %d at %.2f dB, want %g dB", freq, i+2, d.Harmonics[i], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Package metrics exports spectral measurements of a stream, e.g. a live
+// capture, as Prometheus gauges, so machine-condition monitoring can alert on
+// spectral changes like a rising band level or a shifted fundamental.
+//
+// Exporter is an http.Handler that serves the latest values in the Prometheus
+// text exposition format:
+//
+//	dft_band_level_db{band="low"}  level of a frequency band in dB
+//	dft_f0_hz                      fundamental frequency, 0 if no pitch was found
+//	dft_f0_confidence              confidence of the f0 estimate in the range [0..1]
+//	dft_peaks                      number of detected peaks
+//	dft_blocks_total               number of analyzed blocks
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/pitch"
+)
+
+// Band is a named frequency band
+type Band struct {
+	Name          string
+	LowHz, HighHz float64
+}
+
+// ParseBands parses a comma separated list of bands in the form
+// name:low-high, e.g. "low:20-250,mid:250-2000,high:2000-8000"
+func ParseBands(s string) ([]Band, error) {
+	var bands []Band
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, limits, ok := strings.Cut(item, ":")
+		low, high, ok2 := strings.Cut(limits, "-")
+		if !ok || !ok2 || name == "" {
+			return nil, fmt.Errorf("invalid band %q, expected name:low-high", item)
+		}
+		b := Band{Name: name}
+		var err error
+		if b.LowHz, err = strconv.ParseFloat(low, 64); err != nil {
+			return nil, fmt.Errorf("invalid band %q: %v", item, err)
+		}
+		if b.HighHz, err = strconv.ParseFloat(high, 64); err != nil {
+			return nil, fmt.Errorf("invalid band %q: %v", item, err)
+		}
+		if b.LowHz < 0 || b.HighHz <= b.LowHz {
+			return nil, fmt.Errorf("invalid band %q: empty frequency range", item)
+		}
+		bands = append(bands, b)
+	}
+	return bands, nil
+}
+
+// Options configures an Exporter
+type Options struct {
+	Namespace string // prefix of the metric names
+	Bands     []Band
+	Peaks     dft.PeakOptions // criteria of the counted peaks
+
+	// The f0 is estimated with the harmonic product spectrum in the range
+	// MinF0Hz..MaxF0Hz, estimates with a confidence below MinF0Confidence
+	// are reported as 0 Hz
+	MinF0Hz, MaxF0Hz float64
+	F0Harmonics      int
+	MinF0Confidence  float64
+}
+
+// DefaultOptions exports the levels of three broad bands, the f0 between 40 and
+// 2000 Hz and the number of peaks 20 dB above the noise floor
+var DefaultOptions = Options{
+	Namespace: "dft",
+	Bands: []Band{
+		{Name: "low", LowHz: 20, HighHz: 250},
+		{Name: "mid", LowHz: 250, HighHz: 2000},
+		{Name: "high", LowHz: 2000, HighHz: 20000},
+	},
+	Peaks:           dft.PeakOptions{MinAboveFloorDB: 20, MinDistanceHz: 3},
+	MinF0Hz:         40,
+	MaxF0Hz:         2000,
+	F0Harmonics:     4,
+	MinF0Confidence: 0.2,
+}
+
+// Exporter holds the measurements of the latest spectrum of a stream
+type Exporter struct {
+	opts Options
+
+	mu      sync.Mutex
+	levels  []float64 // dB per band
+	f0      pitch.Estimate
+	peaks   int
+	blocks  int
+	updated bool
+}
+
+// NewExporter returns an Exporter without measurements
+func NewExporter(opts Options) *Exporter {
+	return &Exporter{opts: opts, levels: make([]float64, len(opts.Bands))}
+}
+
+// Update measures a spectrum of a block of the stream, computed with a Hann
+// window like the spectra of dft.StreamAnalyzer and capture.Run
+func (e *Exporter) Update(s dft.Spectrum) {
+	levels := make([]float64, len(e.opts.Bands))
+	for i, b := range e.opts.Bands {
+		levels[i] = BandLevel(s, b.LowHz, b.HighHz)
+	}
+	f0 := pitch.HPSSpectrum(s.Magnitude, s.SampleRate, s.FFTSize, e.opts.MinF0Hz, e.opts.MaxF0Hz, e.opts.F0Harmonics)
+	if f0.Confidence < e.opts.MinF0Confidence {
+		f0.Frequency = 0
+	}
+	peaks := len(dft.FindPeaks(s, e.opts.Peaks))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.levels = levels
+	e.f0 = f0
+	e.peaks = peaks
+	e.blocks++
+	e.updated = true
+}
+
+// BandLevel returns the level of the band lowHz..highHz of a Hann windowed
+// spectrum in dB relative to a full scale sine, i.e. a sine with an amplitude
+// of 1 in the band has a level of about 0 dB. Bins are included if their
+// center frequency is in the band.
+func BandLevel(s dft.Spectrum, lowHz, highHz float64) float64 {
+	res := s.FreqRes()
+	first := max(int(math.Ceil(lowHz/res)), 0)
+	last := min(int(math.Floor(highHz/res)), len(s.Magnitude)-1)
+	energy := 0.0
+	for i := first; i <= last; i++ {
+		energy += s.Magnitude[i] * s.Magnitude[i]
+	}
+	// The window spreads the energy of a tone over ENBW bins
+	energy /= hannENBW
+	return dft.AmplitudeToDB(math.Sqrt(energy), dft.DBOptions{})
+}
+
+var hannENBW = dft.Window{Type: dft.Hanning}.ENBW()
+
+// ServeHTTP writes the metrics in the Prometheus text format, nothing but the
+// block counter is written before the first update
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	ns := e.opts.Namespace
+	if ns != "" {
+		ns += "_"
+	}
+	metric := func(name, typ, help string) {
+		fmt.Fprintf(w, "# HELP %s%s %s\n# TYPE %s%s %s\n", ns, name, help, ns, name, typ)
+	}
+
+	metric("blocks_total", "counter", "Number of analyzed blocks.")
+	fmt.Fprintf(w, "%sblocks_total %d\n", ns, e.blocks)
+	if !e.updated {
+		return
+	}
+	if len(e.opts.Bands) > 0 {
+		metric("band_level_db", "gauge", "Level of a frequency band in dB relative to a full scale sine.")
+		for i, b := range e.opts.Bands {
+			fmt.Fprintf(w, "%sband_level_db{band=%q,low_hz=\"%g\",high_hz=\"%g\"} %s\n",
+				ns, b.Name, b.LowHz, b.HighHz, formatValue(e.levels[i]))
+		}
+	}
+	metric("f0_hz", "gauge", "Fundamental frequency in Hz, 0 if no pitch was found.")
+	fmt.Fprintf(w, "%sf0_hz %s\n", ns, formatValue(e.f0.Frequency))
+	metric("f0_confidence", "gauge", "Confidence of the fundamental frequency estimate.")
+	fmt.Fprintf(w, "%sf0_confidence %s\n", ns, formatValue(e.f0.Confidence))
+	metric("peaks", "gauge", "Number of detected spectral peaks.")
+	fmt.Fprintf(w, "%speaks %d\n", ns, e.peaks)
+}
+
+// formatValue formats a sample value, Prometheus spells infinity +Inf and -Inf
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
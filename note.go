@@ -0,0 +1,54 @@
+package dft
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultReferencePitch is the default tuning reference, A4 = 440 Hz
+const DefaultReferencePitch = 440.0
+
+// PitchClassNames are the names of the 12 pitch classes starting at C
+var PitchClassNames = [12]string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+
+// Note is the nearest equal-tempered note to a frequency
+type Note struct {
+	Name   string  // note name without octave, e.g. "A" or "C#"
+	Octave int     // scientific pitch notation octave, A4 is the reference pitch
+	Cents  float64 // deviation of the frequency from the note in cents
+}
+
+// String formats the note as e.g. "A4 +3 cents"
+func (n Note) String() string {
+	return fmt.Sprintf("%s%d %+.0f cents", n.Name, n.Octave, n.Cents)
+}
+
+// NoteFromFrequency returns the note nearest to freq for the given reference pitch
+// of A4 in Hz. A reference pitch <= 0 uses DefaultReferencePitch.
+func NoteFromFrequency(freq, referenceHz float64) (Note, error) {
+	if freq <= 0 {
+		return Note{}, fmt.Errorf("invalid frequency %f", freq)
+	}
+	if referenceHz <= 0 {
+		referenceHz = DefaultReferencePitch
+	}
+
+	// MIDI note number, A4 = 69
+	midi := 69 + 12*math.Log2(freq/referenceHz)
+	nearest := math.Round(midi)
+	n := int(nearest)
+
+	return Note{
+		Name:   PitchClassNames[((n%12)+12)%12],
+		Octave: floorDiv(n, 12) - 1,
+		Cents:  (midi - nearest) * 100,
+	}, nil
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,94 @@
+package dft
+
+// PartitionedConvolver convolves a stream of samples with a long impulse
+// response using uniformly partitioned overlap-save convolution. The impulse
+// response is split into partitions of the block size, so the latency stays at
+// one block and the work per block grows only linearly with the length of the
+// impulse response, which makes multi-second reverbs usable in real-time.
+type PartitionedConvolver struct {
+	blockBuffer
+	irLength   int
+	partitions [][]complex128 // spectra of the impulse response partitions
+	delayLine  [][]complex128 // spectra of the recent input blocks, newest first
+	history    []float64      // last two input blocks
+}
+
+// NewPartitionedConvolver creates a PartitionedConvolver for impulseResponse
+// that processes blocks of blockSize samples.
+func NewPartitionedConvolver(impulseResponse []float64, blockSize int) *PartitionedConvolver {
+	if blockSize < 1 {
+		blockSize = 1
+	}
+
+	partitions := [][]complex128{}
+	for start := 0; start < len(impulseResponse); start += blockSize {
+		end := start + blockSize
+		if end > len(impulseResponse) {
+			end = len(impulseResponse)
+		}
+		padded := make([]float64, 2*blockSize)
+		copy(padded, impulseResponse[start:end])
+		partitions = append(partitions, Forward(padded))
+	}
+
+	c := &PartitionedConvolver{
+		irLength:   len(impulseResponse),
+		partitions: partitions,
+	}
+	c.blockBuffer = blockBuffer{blockSize: blockSize, process: c.processBlock}
+	c.Reset()
+	return c
+}
+
+// Latency returns the delay of the output in samples
+func (c *PartitionedConvolver) Latency() int {
+	return c.blockSize
+}
+
+// Reset clears the internal state as if no samples were processed
+func (c *PartitionedConvolver) Reset() {
+	c.history = make([]float64, 2*c.blockSize)
+	c.delayLine = make([][]complex128, len(c.partitions))
+	for i := range c.delayLine {
+		c.delayLine[i] = make([]complex128, c.blockSize+1)
+	}
+	c.reset()
+}
+
+// Process pushes in and returns the same number of output samples
+func (c *PartitionedConvolver) Process(in []float64) []float64 {
+	return c.push(in)
+}
+
+// Flush returns the remaining output including the tail of the impulse
+// response, as if silence was pushed until the convolution has decayed.
+func (c *PartitionedConvolver) Flush() []float64 {
+	return c.Process(make([]float64, c.irLength-1+c.blockSize))
+}
+
+// processBlock convolves a complete input block
+func (c *PartitionedConvolver) processBlock(block []float64) []float64 {
+	copy(c.history, c.history[c.blockSize:])
+	copy(c.history[c.blockSize:], block)
+
+	// Shift the frequency domain delay line, reusing the oldest buffer
+	if len(c.delayLine) == 0 {
+		return make([]float64, c.blockSize)
+	}
+	oldest := c.delayLine[len(c.delayLine)-1]
+	copy(c.delayLine[1:], c.delayLine[:len(c.delayLine)-1])
+	c.delayLine[0] = oldest
+	copy(c.delayLine[0], Forward(c.history))
+
+	acc := make([]complex128, c.blockSize+1)
+	for p, h := range c.partitions {
+		x := c.delayLine[p]
+		for k := range acc {
+			acc[k] += x[k] * h[k]
+		}
+	}
+	seq := Inverse(acc, 2*c.blockSize)
+
+	// The second half is free of circular wrap-around
+	return seq[c.blockSize:]
+}
@@ -0,0 +1,230 @@
+package dft
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// Peak is a detected spectral peak
+type Peak struct {
+	FreqHz      float64 // interpolated frequency in Hz
+	Magnitude   float64 // interpolated amplitude
+	MagnitudeDB float64 // interpolated amplitude in dB relative to 1.0
+	Phase       float64 // phase of the peak bin in radians
+	BinIndex    int     // index of the peak bin
+	Prominence  float64 // height above the surrounding minima, only set by FindPeaks
+}
+
+// PeakOrder is the order of the peaks returned by FindPeaks
+type PeakOrder int
+
+const (
+	ByFrequency PeakOrder = iota // ascending frequency
+	ByMagnitude                  // descending magnitude
+)
+
+// PeakOptions are the criteria used by FindPeaks, zero values disable a criterion
+type PeakOptions struct {
+	MinHeight     float64 // minimum magnitude of a peak
+	MinProminence float64 // minimum height of a peak above the higher of its two surrounding minima
+	MinDistanceHz float64 // minimum distance between two peaks, weaker peaks are dropped
+
+	// MinAboveFloorDB is the minimum height of a peak in dB above the local noise
+	// floor. Unlike MinHeight it does not depend on the level of the recording.
+	MinAboveFloorDB float64
+	FloorWindowHz   float64 // width of the noise floor filter, defaults to 64 bins
+	FloorPercentile float64 // percentile of the noise floor filter in the range (0..1), defaults to the median
+
+	MaxPeaks int       // return only the strongest MaxPeaks peaks, 0 returns all
+	Order    PeakOrder // order of the returned peaks
+}
+
+// FindMainPeaks detects main frequency peaks and filters side lobes. Frequency and
+// magnitude of each peak are refined with InterpolatePeak.
+func FindMainPeaks(s Spectrum, neighborhoodHz float64, threshold float64) []Peak {
+	return FindPeaks(s, PeakOptions{MinHeight: threshold, MinDistanceHz: neighborhoodHz})
+}
+
+// FindPeaks detects the local maxima of the magnitude spectrum of s that fulfill
+// all criteria of opts, similar to scipy's find_peaks. The peaks are returned in
+// the order given by opts.Order, ties are broken by bin index.
+//
+// Prominence describes how much a peak stands out from its surroundings: it is
+// found by walking from the peak in both directions until a higher bin (or the
+// end of the spectrum) is reached and taking the distance to the higher of the
+// two minima on the way. Quiet peaks in a quiet region have a high prominence
+// while noise bumps on the flank of a strong peak have a low one.
+func FindPeaks(s Spectrum, opts PeakOptions) []Peak {
+	mag := s.Magnitude
+	bins := localMaxima(mag)
+
+	// Height
+	kept := bins[:0]
+	for _, bin := range bins {
+		if mag[bin] >= opts.MinHeight {
+			kept = append(kept, bin)
+		}
+	}
+	bins = kept
+
+	// Height above the local noise floor
+	if opts.MinAboveFloorDB > 0 {
+		windowBins := defaultFloorWindowBins
+		if opts.FloorWindowHz > 0 {
+			windowBins = int(opts.FloorWindowHz / s.FreqRes())
+		}
+		percentile := defaultFloorPercentile
+		if opts.FloorPercentile > 0 && opts.FloorPercentile < 1 {
+			percentile = opts.FloorPercentile
+		}
+		floor := NoiseFloor(mag, windowBins, percentile)
+		kept := bins[:0]
+		for _, bin := range bins {
+			if aboveFloorDB(mag[bin], floor[bin]) >= opts.MinAboveFloorDB {
+				kept = append(kept, bin)
+			}
+		}
+		bins = kept
+	}
+
+	// Distance, the strongest peaks are kept first
+	if distance := int(opts.MinDistanceHz / s.FreqRes()); distance > 0 {
+		bins = filterDistance(mag, bins, distance)
+	}
+
+	peaks := []Peak{}
+	for _, bin := range bins {
+		prominence := peakProminence(mag, bin)
+		if prominence < opts.MinProminence {
+			continue
+		}
+		p := newPeak(s, bin)
+		p.Prominence = prominence
+		peaks = append(peaks, p)
+	}
+
+	if opts.MaxPeaks > 0 && len(peaks) > opts.MaxPeaks {
+		sortPeaks(peaks, ByMagnitude)
+		peaks = peaks[:opts.MaxPeaks]
+	}
+	sortPeaks(peaks, opts.Order)
+	return peaks
+}
+
+// sortPeaks sorts peaks in the given order, ties are broken by bin index
+func sortPeaks(peaks []Peak, order PeakOrder) {
+	sort.Slice(peaks, func(i, j int) bool {
+		a, b := peaks[i], peaks[j]
+		switch order {
+		case ByMagnitude:
+			if a.Magnitude != b.Magnitude {
+				return a.Magnitude > b.Magnitude
+			}
+		default:
+			if a.FreqHz != b.FreqHz {
+				return a.FreqHz < b.FreqHz
+			}
+		}
+		return a.BinIndex < b.BinIndex
+	})
+}
+
+// localMaxima returns the bins of all local maxima of mag. For flat peaks the
+// middle bin is returned.
+func localMaxima(mag []float64) []int {
+	maxima := []int{}
+	for i := 1; i < len(mag)-1; i++ {
+		if mag[i-1] >= mag[i] {
+			continue
+		}
+		// Skip over a plateau
+		j := i
+		for j+1 < len(mag)-1 && mag[j+1] == mag[i] {
+			j++
+		}
+		if mag[j+1] < mag[i] {
+			maxima = append(maxima, (i+j)/2)
+		}
+		i = j
+	}
+	return maxima
+}
+
+// filterDistance drops peaks that lie within distance bins of a higher peak
+func filterDistance(mag []float64, bins []int, distance int) []int {
+	order := make([]int, len(bins))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return mag[bins[order[a]]] > mag[bins[order[b]]]
+	})
+
+	removed := make([]bool, len(bins))
+	for _, i := range order {
+		if removed[i] {
+			continue
+		}
+		for j := i - 1; j >= 0 && bins[i]-bins[j] < distance; j-- {
+			removed[j] = true
+		}
+		for j := i + 1; j < len(bins) && bins[j]-bins[i] < distance; j++ {
+			removed[j] = true
+		}
+	}
+
+	kept := []int{}
+	for i, bin := range bins {
+		if !removed[i] {
+			kept = append(kept, bin)
+		}
+	}
+	return kept
+}
+
+// peakProminence returns the prominence of the peak at bin
+func peakProminence(mag []float64, bin int) float64 {
+	leftMin := mag[bin]
+	for i := bin - 1; i >= 0 && mag[i] <= mag[bin]; i-- {
+		leftMin = math.Min(leftMin, mag[i])
+	}
+	rightMin := mag[bin]
+	for i := bin + 1; i < len(mag) && mag[i] <= mag[bin]; i++ {
+		rightMin = math.Min(rightMin, mag[i])
+	}
+	return mag[bin] - math.Max(leftMin, rightMin)
+}
+
+// newPeak creates the Peak at bin of s
+func newPeak(s Spectrum, bin int) Peak {
+	exactBin, magnitude := InterpolatePeak(s.Magnitude, bin)
+	return Peak{
+		FreqHz:      exactBin * s.FreqRes(),
+		Magnitude:   magnitude,
+		MagnitudeDB: 20 * math.Log10(magnitude+minMagnitude),
+		Phase:       cmplx.Phase(s.Coefficients[bin]),
+		BinIndex:    bin,
+	}
+}
+
+// InterpolatePeak refines the location of a peak at bin in a magnitude spectrum
+// by fitting a parabola through the log magnitudes of the peak bin and its two
+// neighbours. It returns the fractional bin index of the vertex and the
+// interpolated magnitude. Peaks at the edges of the spectrum are returned unchanged.
+func InterpolatePeak(mag []float64, bin int) (exactBin float64, magnitude float64) {
+	if bin <= 0 || bin >= len(mag)-1 || mag[bin] <= 0 {
+		return float64(bin), mag[bin]
+	}
+
+	alpha := math.Log(mag[bin-1] + minMagnitude)
+	beta := math.Log(mag[bin])
+	gamma := math.Log(mag[bin+1] + minMagnitude)
+
+	denom := alpha - 2*beta + gamma
+	if denom == 0 {
+		return float64(bin), mag[bin]
+	}
+	p := 0.5 * (alpha - gamma) / denom
+	return float64(bin) + p, math.Exp(beta - 0.25*(alpha-gamma)*p)
+}
@@ -0,0 +1,77 @@
+package dft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// PhaseSpectrum returns the phase of each coefficient in radians. If unwrap is
+// set the 2*pi jumps between neighbouring bins are removed, see UnwrapPhase.
+func PhaseSpectrum(coeffs []complex128, unwrap bool) []float64 {
+	phase := make([]float64, len(coeffs))
+	for i, c := range coeffs {
+		phase[i] = cmplx.Phase(c)
+	}
+	if unwrap {
+		return UnwrapPhase(phase)
+	}
+	return phase
+}
+
+// UnwrapPhase removes 2*pi jumps between consecutive phase values
+func UnwrapPhase(phase []float64) []float64 {
+	unwrapped := make([]float64, len(phase))
+	if len(phase) == 0 {
+		return unwrapped
+	}
+
+	unwrapped[0] = phase[0]
+	offset := 0.0
+	for i := 1; i < len(phase); i++ {
+		d := phase[i] - phase[i-1]
+		if d > math.Pi {
+			offset -= 2 * math.Pi * math.Ceil((d-math.Pi)/(2*math.Pi))
+		} else if d < -math.Pi {
+			offset += 2 * math.Pi * math.Ceil((-d-math.Pi)/(2*math.Pi))
+		}
+		unwrapped[i] = phase[i] + offset
+	}
+	return unwrapped
+}
+
+// GroupDelay returns the group delay in seconds of each bin, the negative
+// derivative of the unwrapped phase with respect to angular frequency. The
+// coefficients are those of an fftSize point FFT. To measure a filter or
+// loudspeaker pass the plain FFT of its impulse response, a window would
+// distort the phase.
+func GroupDelay(coeffs []complex128, sampleRate, fftSize int) []float64 {
+	phase := PhaseSpectrum(coeffs, true)
+	delay := make([]float64, len(phase))
+	if len(phase) < 2 {
+		return delay
+	}
+
+	// Central differences, one sided at the edges
+	dOmega := 2 * math.Pi * float64(sampleRate) / float64(fftSize)
+	for i := range phase {
+		lo, hi := i-1, i+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi >= len(phase) {
+			hi = len(phase) - 1
+		}
+		delay[i] = -(phase[hi] - phase[lo]) / (float64(hi-lo) * dOmega)
+	}
+	return delay
+}
+
+// Phase returns the phase spectrum of s in radians, see PhaseSpectrum
+func (s Spectrum) Phase(unwrap bool) []float64 {
+	return PhaseSpectrum(s.Coefficients, unwrap)
+}
+
+// GroupDelay returns the group delay of s in seconds, see GroupDelay
+func (s Spectrum) GroupDelay() []float64 {
+	return GroupDelay(s.Coefficients, s.SampleRate, s.FFTSize)
+}
@@ -0,0 +1,229 @@
+// Package pitch implements fundamental frequency (f0) estimators.
+//
+// Peak picking on a magnitude spectrum often reports a strong harmonic instead
+// of the fundamental. The estimators in this package look at the periodicity of
+// the whole signal and return the fundamental frequency with a confidence score.
+package pitch
+
+import (
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Estimate is the result of a pitch estimator
+type Estimate struct {
+	Frequency  float64 // fundamental frequency in Hz, 0 if no pitch was found
+	Confidence float64 // confidence in the range [0..1]
+}
+
+// YIN estimates the fundamental frequency using the YIN algorithm
+// (de Cheveigné & Kawahara, 2002). The threshold for the cumulative mean
+// normalized difference function is typically in the range 0.1..0.2.
+// The signal must cover at least two periods of minHz.
+func YIN(samples []float64, sampleRate int, minHz, maxHz, threshold float64) Estimate {
+	minLag, maxLag := lagRange(sampleRate, minHz, maxHz)
+	w := len(samples) / 2
+	if maxLag >= w {
+		maxLag = w - 1
+	}
+	if minLag < 1 || minLag >= maxLag {
+		return Estimate{}
+	}
+
+	// Difference function
+	diff := make([]float64, maxLag+1)
+	for tau := 1; tau <= maxLag; tau++ {
+		for j := 0; j < w; j++ {
+			d := samples[j] - samples[j+tau]
+			diff[tau] += d * d
+		}
+	}
+
+	// Cumulative mean normalized difference function
+	cmnd := make([]float64, maxLag+1)
+	cmnd[0] = 1
+	sum := 0.0
+	for tau := 1; tau <= maxLag; tau++ {
+		sum += diff[tau]
+		if sum == 0 {
+			cmnd[tau] = 1
+			continue
+		}
+		cmnd[tau] = diff[tau] * float64(tau) / sum
+	}
+
+	// Absolute threshold, fall back to the global minimum
+	best := -1
+	for tau := minLag; tau <= maxLag; tau++ {
+		if cmnd[tau] < threshold {
+			for tau+1 <= maxLag && cmnd[tau+1] < cmnd[tau] {
+				tau++
+			}
+			best = tau
+			break
+		}
+	}
+	if best < 0 {
+		best = minLag
+		for tau := minLag; tau <= maxLag; tau++ {
+			if cmnd[tau] < cmnd[best] {
+				best = tau
+			}
+		}
+	}
+
+	lag := float64(best)
+	if best > minLag && best < maxLag {
+		lag += parabolicOffset(cmnd[best-1], cmnd[best], cmnd[best+1])
+	}
+
+	return Estimate{
+		Frequency:  float64(sampleRate) / lag,
+		Confidence: clamp01(1 - cmnd[best]),
+	}
+}
+
+// AutoCorrelation estimates the fundamental frequency as the lag with the
+// highest normalized autocorrelation within the given frequency range.
+func AutoCorrelation(samples []float64, sampleRate int, minHz, maxHz float64) Estimate {
+	minLag, maxLag := lagRange(sampleRate, minHz, maxHz)
+	if maxLag >= len(samples) {
+		maxLag = len(samples) - 1
+	}
+	if minLag < 1 || minLag >= maxLag {
+		return Estimate{}
+	}
+
+	r := dft.AutoCorr(samples)
+	if r[0] == 0 {
+		return Estimate{}
+	}
+
+	best := minLag
+	for lag := minLag; lag <= maxLag; lag++ {
+		if r[lag] > r[best] {
+			best = lag
+		}
+	}
+
+	lag := float64(best)
+	if best > minLag && best < maxLag {
+		lag += parabolicOffset(r[best-1], r[best], r[best+1])
+	}
+
+	return Estimate{
+		Frequency:  float64(sampleRate) / lag,
+		Confidence: clamp01(r[best] / r[0]),
+	}
+}
+
+// HPS estimates the fundamental frequency with the harmonic product spectrum.
+// The magnitude spectrum is downsampled by the factors 1..harmonics and
+// multiplied, so that the harmonics of the fundamental line up on its bin.
+// The confidence is the share of spectral energy found on the harmonics.
+func HPS(samples []float64, sampleRate int, minHz, maxHz float64, harmonics int) Estimate {
+	if len(samples) == 0 || harmonics < 1 {
+		return Estimate{}
+	}
+
+	fftSize := dft.NextPowerOfTwo(len(samples))
+	padded := make([]float64, fftSize)
+	copy(padded, samples)
+	dft.ApplyHanningWindow(padded[:len(samples)])
+
+	spectrum := dft.Forward(padded)
+	mag := make([]float64, len(spectrum))
+	for i, c := range spectrum {
+		mag[i] = math.Hypot(real(c), imag(c))
+	}
+	return HPSSpectrum(mag, sampleRate, fftSize, minHz, maxHz, harmonics)
+}
+
+// HPSSpectrum is HPS for a magnitude spectrum of the non-negative frequencies,
+// e.g. the Magnitude of a dft.Spectrum of a live block
+func HPSSpectrum(mag []float64, sampleRate, fftSize int, minHz, maxHz float64, harmonics int) Estimate {
+	if len(mag) == 0 || harmonics < 1 {
+		return Estimate{}
+	}
+
+	freqRes := float64(sampleRate) / float64(fftSize)
+	minBin := int(math.Ceil(minHz / freqRes))
+	maxBin := int(maxHz / freqRes)
+	if minBin < 1 {
+		minBin = 1
+	}
+	if maxBin*harmonics >= len(mag) {
+		maxBin = (len(mag) - 1) / harmonics
+	}
+	if minBin > maxBin {
+		return Estimate{}
+	}
+
+	// Use log magnitudes to keep the product in a sane numeric range
+	best := -1
+	bestScore := math.Inf(-1)
+	for bin := minBin; bin <= maxBin; bin++ {
+		score := 0.0
+		for h := 1; h <= harmonics; h++ {
+			score += math.Log(mag[bin*h] + 1e-12)
+		}
+		if score > bestScore {
+			best, bestScore = bin, score
+		}
+	}
+
+	total := 0.0
+	for bin := 1; bin < len(mag) && bin <= maxBin*harmonics+1; bin++ {
+		total += mag[bin] * mag[bin]
+	}
+	harmonic := 0.0
+	for h := 1; h <= harmonics; h++ {
+		// include the neighbouring bins, the Hanning main lobe is 4 bins wide
+		for b := best*h - 1; b <= best*h+1; b++ {
+			if b > 0 && b < len(mag) {
+				harmonic += mag[b] * mag[b]
+			}
+		}
+	}
+
+	bin := float64(best)
+	if best > 1 && best+1 < len(mag) {
+		bin += parabolicOffset(mag[best-1], mag[best], mag[best+1])
+	}
+
+	confidence := 0.0
+	if total > 0 {
+		confidence = clamp01(harmonic / total)
+	}
+	return Estimate{
+		Frequency:  bin * freqRes,
+		Confidence: confidence,
+	}
+}
+
+// lagRange converts a frequency range in Hz into a lag range in samples
+func lagRange(sampleRate int, minHz, maxHz float64) (minLag, maxLag int) {
+	if minHz <= 0 || maxHz <= minHz {
+		return 0, 0
+	}
+	minLag = int(math.Floor(float64(sampleRate) / maxHz))
+	maxLag = int(math.Ceil(float64(sampleRate) / minHz))
+	return minLag, maxLag
+}
+
+// parabolicOffset returns the offset of the vertex of the parabola through three
+// equally spaced points relative to the center point, in the range [-0.5..0.5].
+// It works for both minima and maxima.
+func parabolicOffset(a, b, c float64) float64 {
+	denom := a - 2*b + c
+	if denom == 0 {
+		return 0
+	}
+	offset := 0.5 * (a - c) / denom
+	return math.Max(-0.5, math.Min(0.5, offset))
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
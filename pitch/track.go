@@ -0,0 +1,78 @@
+package pitch
+
+import (
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Estimator estimates the pitch of a single frame of samples
+type Estimator func(frame []float64, sampleRate int) Estimate
+
+// Point is a single point of an f0 contour
+type Point struct {
+	Time float64 // center of the analyzed frame in seconds
+	Estimate
+}
+
+// YINEstimator returns an Estimator using YIN with the given parameters
+func YINEstimator(minHz, maxHz, threshold float64) Estimator {
+	return func(frame []float64, sampleRate int) Estimate {
+		return YIN(frame, sampleRate, minHz, maxHz, threshold)
+	}
+}
+
+// AutoCorrelationEstimator returns an Estimator using AutoCorrelation with the given parameters
+func AutoCorrelationEstimator(minHz, maxHz float64) Estimator {
+	return func(frame []float64, sampleRate int) Estimate {
+		return AutoCorrelation(frame, sampleRate, minHz, maxHz)
+	}
+}
+
+// SpectrumEstimator estimates the pitch of a single STFT frame from its
+// magnitude spectrum of fftSize bins
+type SpectrumEstimator func(mag []float64, sampleRate, fftSize int) Estimate
+
+// HPSEstimator returns an Estimator using HPS with the given parameters
+func HPSEstimator(minHz, maxHz float64, harmonics int) Estimator {
+	return func(frame []float64, sampleRate int) Estimate {
+		return HPS(frame, sampleRate, minHz, maxHz, harmonics)
+	}
+}
+
+// HPSSpectrumEstimator returns a SpectrumEstimator using HPSSpectrum with the given parameters
+func HPSSpectrumEstimator(minHz, maxHz float64, harmonics int) SpectrumEstimator {
+	return func(mag []float64, sampleRate, fftSize int) Estimate {
+		return HPSSpectrum(mag, sampleRate, fftSize, minHz, maxHz, harmonics)
+	}
+}
+
+// Track estimates the fundamental frequency for each frame of the raw samples
+// and returns the resulting f0 contour. It is meant for the time-domain
+// estimators YIN and AutoCorrelation, which need the unwindowed samples.
+// Frames are cut like those of dft.STFT, so that contour points line up with
+// spectrogram frames; TrackSTFT estimates from the STFT frames themselves.
+func Track(samples []float64, sampleRate, frameSize, hopSize int, estimate Estimator) []Point {
+	frames := dft.Frames(samples, frameSize, hopSize)
+	contour := make([]Point, len(frames))
+	for i, frame := range frames {
+		contour[i] = Point{
+			Time:     dft.FrameTime(i, sampleRate, frameSize, hopSize),
+			Estimate: estimate(frame, sampleRate),
+		}
+	}
+	return contour
+}
+
+// TrackSTFT returns the f0 contour of frames of dft.STFT with a frame size of
+// frameSize, e.g. of a spectrogram that is already computed. Each point is
+// estimated from the magnitude spectrum of its frame.
+func TrackSTFT(frames []dft.Frame, sampleRate, frameSize int, estimate SpectrumEstimator) []Point {
+	contour := make([]Point, len(frames))
+	for i, f := range frames {
+		s := dft.NewSpectrum(f.Spectrum, sampleRate, frameSize, frameSize)
+		contour[i] = Point{
+			Time:     f.Time,
+			Estimate: estimate(s.Magnitude, sampleRate, frameSize),
+		}
+	}
+	return contour
+}
@@ -0,0 +1,61 @@
+package pitch
+
+import (
+	"math"
+	"testing"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// steppedTone returns one second of a tone with three harmonics whose
+// fundamental steps from 220 Hz to 330 Hz after half a second
+func steppedTone(sampleRate int) []float64 {
+	x := make([]float64, sampleRate)
+	phase := 0.0
+	for i := range x {
+		f0 := 220.0
+		if i >= sampleRate/2 {
+			f0 = 330
+		}
+		phase += 2 * math.Pi * f0 / float64(sampleRate)
+		x[i] = 0.6*math.Sin(phase) + 0.3*math.Sin(2*phase) + 0.2*math.Sin(3*phase)
+	}
+	return x
+}
+
+func TestTrack(t *testing.T) {
+	const sampleRate, frameSize, hopSize = 16000, 2048, 512
+	samples := steppedTone(sampleRate)
+	frames := dft.STFT(samples, sampleRate, frameSize, hopSize)
+
+	for _, c := range []struct {
+		name    string
+		contour []Point
+	}{
+		{"Track YIN", Track(samples, sampleRate, frameSize, hopSize, YINEstimator(80, 1000, 0.1))},
+		{"TrackSTFT HPS", TrackSTFT(frames, sampleRate, frameSize, HPSSpectrumEstimator(80, 1000, 3))},
+	} {
+		if len(c.contour) != len(frames) {
+			t.Fatalf("%s: %d points, want one per STFT frame (%d)", c.name, len(c.contour), len(frames))
+		}
+		for i, p := range c.contour {
+			if p.Time != frames[i].Time {
+				t.Errorf("%s: point %d at %g s, want %g s", c.name, i, p.Time, frames[i].Time)
+			}
+			// Skip the frames that straddle the step
+			start, end := float64(i*hopSize), float64(i*hopSize+frameSize)
+			want := 0.0
+			switch {
+			case end <= sampleRate/2:
+				want = 220
+			case start >= sampleRate/2:
+				want = 330
+			default:
+				continue
+			}
+			if math.Abs(p.Frequency-want) > want*0.02 || p.Confidence < 0.5 {
+				t.Errorf("%s: %.1f Hz with confidence %.2f at %.3f s, want %g Hz", c.name, p.Frequency, p.Confidence, p.Time, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package plot
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Scale is the scale of a frequency axis
+type Scale int
+
+const (
+	Linear Scale = iota
+	Log
+	Mel // perceptual pitch scale, roughly linear below and logarithmic above 1 kHz
+)
+
+// ParseScale parses linear, log or mel
+func ParseScale(s string) (Scale, error) {
+	switch strings.ToLower(s) {
+	case "linear", "lin":
+		return Linear, nil
+	case "log":
+		return Log, nil
+	case "mel":
+		return Mel, nil
+	}
+	return Linear, fmt.Errorf("unknown frequency scale %q", s)
+}
+
+// hzToMel converts a frequency to the mel scale (HTK formula)
+func hzToMel(f float64) float64 {
+	return 2595 * math.Log10(1+f/700)
+}
+
+// melToHz is the inverse of hzToMel
+func melToHz(m float64) float64 {
+	return 700 * (math.Pow(10, m/2595) - 1)
+}
+
+// axis maps values to positions in [0..1]
+type axis struct {
+	min, max float64
+	scale    Scale
+}
+
+func (a axis) forward(v float64) float64 {
+	switch a.scale {
+	case Log:
+		return math.Log(v)
+	case Mel:
+		return hzToMel(v)
+	}
+	return v
+}
+
+func (a axis) inverse(v float64) float64 {
+	switch a.scale {
+	case Log:
+		return math.Exp(v)
+	case Mel:
+		return melToHz(v)
+	}
+	return v
+}
+
+// pos returns the relative position of v on the axis
+func (a axis) pos(v float64) float64 {
+	lo, hi := a.forward(a.min), a.forward(a.max)
+	return (a.forward(v) - lo) / (hi - lo)
+}
+
+// value is the inverse of pos
+func (a axis) value(p float64) float64 {
+	lo, hi := a.forward(a.min), a.forward(a.max)
+	return a.inverse(lo + p*(hi-lo))
+}
+
+// tick is a grid line of an axis, minor ticks have no label
+type tick struct {
+	value float64
+	label string
+}
+
+// ticks returns about n labeled ticks at round values. Log and mel axes get
+// labeled ticks at 1, 2 and 5 times the powers of ten, log axes also get
+// unlabeled ones in between.
+func (a axis) ticks(n int, format func(float64) string) []tick {
+	var ticks []tick
+	if a.scale == Log || a.scale == Mel {
+		lowest := a.min
+		if a.scale == Mel {
+			lowest = math.Max(lowest, 10) // the mel scale starts at 0 Hz
+		}
+		for decade := math.Pow(10, math.Floor(math.Log10(lowest))); decade <= a.max; decade *= 10 {
+			for m := 1.0; m < 10; m++ {
+				v := decade * m
+				labeled := m == 1 || m == 2 || m == 5
+				if v < a.min || v > a.max || (a.scale == Mel && !labeled) {
+					continue
+				}
+				t := tick{value: v}
+				if labeled {
+					t.label = format(v)
+				}
+				ticks = append(ticks, t)
+			}
+		}
+		return ticks
+	}
+
+	step := niceStep((a.max - a.min) / float64(n))
+	for v := math.Ceil(a.min/step) * step; v <= a.max+step*1e-9; v += step {
+		ticks = append(ticks, tick{value: v, label: format(v)})
+	}
+	return ticks
+}
+
+// niceStep rounds step up to 1, 2 or 5 times a power of ten
+func niceStep(step float64) float64 {
+	if step <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(step)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if m*magnitude >= step {
+			return m * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// formatHz formats a frequency, e.g. 50, 1.5k or 20k
+func formatHz(f float64) string {
+	if f >= 1000 {
+		return fmt.Sprintf("%gk", math.Round(f/100)/10)
+	}
+	return fmt.Sprintf("%g", math.Round(f*100)/100)
+}
+
+// formatDB formats a level in dB
+func formatDB(db float64) string {
+	return fmt.Sprintf("%g", math.Round(db*10)/10)
+}
@@ -0,0 +1,72 @@
+package plot
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// ColorMap maps values in [0..1] to colors by interpolating between evenly
+// spaced colors
+type ColorMap []color.RGBA
+
+// Color maps, Viridis, Magma and Inferno are the perceptually uniform maps of
+// matplotlib sampled at 9 points
+var (
+	Viridis = ColorMap{
+		{0x44, 0x01, 0x54, 0xff}, {0x48, 0x28, 0x78, 0xff}, {0x3e, 0x4a, 0x89, 0xff},
+		{0x31, 0x68, 0x8e, 0xff}, {0x26, 0x82, 0x8e, 0xff}, {0x1f, 0x9e, 0x89, 0xff},
+		{0x35, 0xb7, 0x79, 0xff}, {0x6d, 0xcd, 0x59, 0xff}, {0xfd, 0xe7, 0x25, 0xff},
+	}
+	Magma = ColorMap{
+		{0x00, 0x00, 0x04, 0xff}, {0x1c, 0x10, 0x44, 0xff}, {0x4f, 0x12, 0x7b, 0xff},
+		{0x81, 0x25, 0x81, 0xff}, {0xb5, 0x36, 0x7a, 0xff}, {0xe5, 0x50, 0x64, 0xff},
+		{0xfb, 0x87, 0x61, 0xff}, {0xfe, 0xc2, 0x87, 0xff}, {0xfc, 0xfd, 0xbf, 0xff},
+	}
+	Inferno = ColorMap{
+		{0x00, 0x00, 0x04, 0xff}, {0x1f, 0x0c, 0x48, 0xff}, {0x55, 0x0f, 0x6d, 0xff},
+		{0x88, 0x22, 0x6a, 0xff}, {0xba, 0x36, 0x55, 0xff}, {0xe3, 0x59, 0x33, 0xff},
+		{0xf9, 0x8c, 0x0a, 0xff}, {0xf9, 0xc9, 0x32, 0xff}, {0xfc, 0xff, 0xa4, 0xff},
+	}
+	Gray = ColorMap{{0x00, 0x00, 0x00, 0xff}, {0xff, 0xff, 0xff, 0xff}}
+)
+
+var colorMaps = map[string]ColorMap{
+	"viridis": Viridis,
+	"magma":   Magma,
+	"inferno": Inferno,
+	"gray":    Gray,
+	"grey":    Gray,
+}
+
+// ParseColorMap returns the color map with the given name: viridis, magma,
+// inferno or gray
+func ParseColorMap(name string) (ColorMap, error) {
+	m, ok := colorMaps[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown color map %q", name)
+	}
+	return m, nil
+}
+
+// At returns the color of v, values outside of [0..1] are clamped
+func (m ColorMap) At(v float64) color.RGBA {
+	if len(m) == 0 {
+		return color.RGBA{}
+	}
+	if math.IsNaN(v) {
+		v = 0
+	}
+	pos := math.Max(0, math.Min(1, v)) * float64(len(m)-1)
+	i := int(pos)
+	if i >= len(m)-1 {
+		return m[len(m)-1]
+	}
+	t := pos - float64(i)
+	a, b := m[i], m[i+1]
+	mix := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + t*(float64(y)-float64(x))))
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 0xff}
+}
@@ -0,0 +1,154 @@
+package plot
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Colors of the plots
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	foreground = color.RGBA{0x20, 0x20, 0x20, 0xff}
+	gridColor  = color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+	lineColor  = color.RGBA{0x1f, 0x77, 0xb4, 0xff}
+	peakColor  = color.RGBA{0xd6, 0x27, 0x28, 0xff}
+)
+
+// Margins around the plot area in pixels
+const (
+	marginLeft   = 56
+	marginRight  = 16
+	marginTop    = 28
+	marginBottom = 40
+)
+
+// WritePNG encodes img as PNG
+func WritePNG(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
+
+// fill fills r with c
+func fill(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// line draws a line from (x0, y0) to (x1, y1) with Bresenham's algorithm
+func line(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// frame is the plot area of an image with its two axes
+type frame struct {
+	img    *image.RGBA
+	area   image.Rectangle
+	xAxis  axis
+	yAxis  axis
+	xLabel string
+	yLabel string
+}
+
+// newFrame creates a white image of the given size with a plot area inside
+// the margins
+func newFrame(width, height int, x, y axis, xLabel, yLabel string) *frame {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fill(img, img.Bounds(), background)
+	return &frame{
+		img:    img,
+		area:   image.Rect(marginLeft, marginTop, width-marginRight, height-marginBottom),
+		xAxis:  x,
+		yAxis:  y,
+		xLabel: xLabel,
+		yLabel: yLabel,
+	}
+}
+
+// px returns the pixel column of the x value v
+func (f *frame) px(v float64) int {
+	return f.area.Min.X + int(f.xAxis.pos(v)*float64(f.area.Dx()-1)+0.5)
+}
+
+// py returns the pixel row of the y value v, clamped to the plot area
+func (f *frame) py(v float64) int {
+	p := f.yAxis.pos(v)
+	p = max(0, min(1, p))
+	return f.area.Max.Y - 1 - int(p*float64(f.area.Dy()-1)+0.5)
+}
+
+// grid draws the grid lines of both axes
+func (f *frame) grid(xFormat, yFormat func(float64) string) {
+	for _, t := range f.xAxis.ticks(10, xFormat) {
+		x := f.px(t.value)
+		line(f.img, x, f.area.Min.Y, x, f.area.Max.Y-1, gridColor)
+	}
+	for _, t := range f.yAxis.ticks(8, yFormat) {
+		y := f.py(t.value)
+		line(f.img, f.area.Min.X, y, f.area.Max.X-1, y, gridColor)
+	}
+}
+
+// axes draws the border of the plot area, the tick labels and the axis labels
+func (f *frame) axes(title string, xFormat, yFormat func(float64) string) {
+	a := f.area
+	line(f.img, a.Min.X-1, a.Min.Y-1, a.Max.X, a.Min.Y-1, foreground)
+	line(f.img, a.Min.X-1, a.Max.Y, a.Max.X, a.Max.Y, foreground)
+	line(f.img, a.Min.X-1, a.Min.Y-1, a.Min.X-1, a.Max.Y, foreground)
+	line(f.img, a.Max.X, a.Min.Y-1, a.Max.X, a.Max.Y, foreground)
+
+	// labels that would overlap the previous one are skipped
+	lastX := math.MinInt
+	for _, t := range f.xAxis.ticks(10, xFormat) {
+		x := f.px(t.value)
+		line(f.img, x, a.Max.Y, x, a.Max.Y+3, foreground)
+		if left := x - textWidth(t.label)/2; t.label != "" && left > lastX {
+			drawText(f.img, left, a.Max.Y+6, t.label, foreground)
+			lastX = left + textWidth(t.label) + glyphWidth
+		}
+	}
+	lastY := math.MaxInt
+	for _, t := range f.yAxis.ticks(8, yFormat) {
+		y := f.py(t.value)
+		line(f.img, a.Min.X-4, y, a.Min.X-1, y, foreground)
+		if t.label != "" && y+5 < lastY {
+			drawText(f.img, a.Min.X-6-textWidth(t.label), y-3, t.label, foreground)
+			lastY = y - 5
+		}
+	}
+
+	drawText(f.img, a.Min.X+(a.Dx()-textWidth(f.xLabel))/2, a.Max.Y+22, f.xLabel, foreground)
+	drawText(f.img, 4, a.Min.Y-12, f.yLabel, foreground)
+	drawText(f.img, a.Min.X+(a.Dx()-textWidth(title))/2, 8, title, foreground)
+}
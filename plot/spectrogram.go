@@ -0,0 +1,144 @@
+package plot
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// colorBarWidth is the space taken by the color bar right of a spectrogram
+const colorBarWidth = 64
+
+// SpectrogramOptions configures Spectrogram
+type SpectrogramOptions struct {
+	Width, Height int
+	MinHz, MaxHz  float64 // frequency range, 0 selects the first bin and the Nyquist frequency
+	FreqScale     Scale
+	MinDB, MaxDB  float64 // levels mapped to the ends of the color map, in dB relative to a magnitude of 1
+	ColorMap      ColorMap
+	Title         string
+}
+
+// DefaultSpectrogramOptions maps -120 to 0 dB to viridis over a logarithmic
+// frequency axis
+var DefaultSpectrogramOptions = SpectrogramOptions{
+	Width:     1200,
+	Height:    600,
+	FreqScale: Log,
+	MinDB:     -120,
+	MaxDB:     0,
+	ColorMap:  Viridis,
+}
+
+// Spectrogram renders STFT frames as an image with the time on the x axis, the
+// frequency on the y axis and the level as color. frameSize is the frame size
+// the STFT was computed with. Like Spectrum, every pixel shows the highest
+// level of the frames and bins it covers.
+func Spectrogram(frames []dft.Frame, sampleRate, frameSize int, opts SpectrogramOptions) (*image.RGBA, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no frames to plot")
+	}
+	res := float64(sampleRate) / float64(frameSize)
+	y, err := frequencyAxis(res, float64(sampleRate)/2, opts.MinHz, opts.MaxHz, opts.FreqScale)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxDB <= opts.MinDB {
+		return nil, fmt.Errorf("invalid dB range %g..%g", opts.MinDB, opts.MaxDB)
+	}
+	if opts.Width <= marginLeft+marginRight+colorBarWidth || opts.Height <= marginTop+marginBottom {
+		return nil, fmt.Errorf("image size %dx%d is too small", opts.Width, opts.Height)
+	}
+	cmap := opts.ColorMap
+	if len(cmap) == 0 {
+		cmap = Viridis
+	}
+
+	// each frame covers the hop around its center
+	hop := float64(frameSize) / float64(sampleRate)
+	if len(frames) > 1 {
+		hop = (frames[len(frames)-1].Time - frames[0].Time) / float64(len(frames)-1)
+	}
+	x := axis{min: frames[0].Time - hop/2, max: frames[len(frames)-1].Time + hop/2}
+
+	f := newFrame(opts.Width, opts.Height, x, y, "Time (s)", "Hz")
+	f.area.Max.X -= colorBarWidth
+
+	levels := make([][]float64, len(frames))
+	for i, fr := range frames {
+		levels[i] = dft.NewSpectrum(fr.Spectrum, sampleRate, frameSize, frameSize).DB(dft.DBOptions{})
+	}
+	level := axis{min: opts.MinDB, max: opts.MaxDB}
+
+	w, h := f.area.Dx(), f.area.Dy()
+	frameRange := make([][2]int, w)
+	for col := range frameRange {
+		lo := int(math.Ceil((x.value(float64(col)/float64(w)) - frames[0].Time) / hop))
+		hi := int(math.Ceil((x.value(float64(col+1)/float64(w))-frames[0].Time)/hop)) - 1
+		if hi < lo {
+			lo = int(math.Round((x.value((float64(col)+0.5)/float64(w)) - frames[0].Time) / hop))
+			hi = lo
+		}
+		frameRange[col] = [2]int{max(lo, 0), min(hi, len(frames)-1)}
+	}
+	binRange := binRanges(y, res, h, frameSize/2+1)
+	for row := 0; row < h; row++ {
+		// rows are counted from the top, frequencies from the bottom
+		lo, hi := binRange[h-1-row][0], binRange[h-1-row][1]
+		for col := 0; col < w; col++ {
+			db := math.Inf(-1)
+			for i := frameRange[col][0]; i <= frameRange[col][1]; i++ {
+				for _, v := range levels[i][lo : hi+1] {
+					db = math.Max(db, v)
+				}
+			}
+			f.img.SetRGBA(f.area.Min.X+col, f.area.Min.Y+row, cmap.At(level.pos(db)))
+		}
+	}
+
+	f.axes(opts.Title, formatSeconds, formatHz)
+	colorBar(f, cmap, level)
+	return f.img, nil
+}
+
+// colorBar draws the color map with the levels of its ends right of the plot
+// area of f
+func colorBar(f *frame, cmap ColorMap, level axis) {
+	left := f.area.Max.X + 12
+	bar := image.Rect(left, f.area.Min.Y, left+12, f.area.Max.Y)
+	for row := bar.Min.Y; row < bar.Max.Y; row++ {
+		c := cmap.At(float64(bar.Max.Y-1-row) / float64(bar.Dy()-1))
+		fill(f.img, image.Rect(bar.Min.X, row, bar.Max.X, row+1), c)
+	}
+	for _, t := range level.ticks(8, formatDB) {
+		row := bar.Max.Y - 1 - int(level.pos(t.value)*float64(bar.Dy()-1)+0.5)
+		line(f.img, bar.Max.X, row, bar.Max.X+3, row, foreground)
+		drawText(f.img, bar.Max.X+5, row-3, t.label, foreground)
+	}
+	drawText(f.img, bar.Min.X, bar.Min.Y-12, "dB", foreground)
+}
+
+// binRanges divides the frequency axis a into n pixels and returns the range
+// of the bins with a width of res Hz that fall into each pixel. A pixel that
+// is narrower than a bin gets the nearest bin.
+func binRanges(a axis, res float64, n, bins int) [][2]int {
+	ranges := make([][2]int, n)
+	for i := range ranges {
+		p0, p1 := float64(i)/float64(n), float64(i+1)/float64(n)
+		lo := int(math.Ceil(a.value(p0) / res))
+		hi := int(math.Ceil(a.value(p1)/res)) - 1
+		if hi < lo {
+			lo = int(math.Round(a.value((p0+p1)/2) / res))
+			hi = lo
+		}
+		ranges[i] = [2]int{max(lo, 0), min(hi, bins-1)}
+	}
+	return ranges
+}
+
+// formatSeconds formats a time in seconds
+func formatSeconds(t float64) string {
+	return fmt.Sprintf("%g", math.Round(t*1000)/1000)
+}
@@ -0,0 +1,111 @@
+// Package plot renders spectra and spectrograms as images without any
+// dependencies beyond the standard library.
+package plot
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// SpectrumOptions configures Spectrum
+type SpectrumOptions struct {
+	Width, Height int
+	MinHz, MaxHz  float64 // frequency range, 0 selects the first bin and the Nyquist frequency
+	LogFreq       bool    // logarithmic frequency axis
+	MinDB, MaxDB  float64 // level range in dB relative to a magnitude of 1
+	Peaks         []dft.Peak
+	Title         string
+}
+
+// DefaultSpectrumOptions plots from -120 to 0 dB over a logarithmic frequency axis
+var DefaultSpectrumOptions = SpectrumOptions{
+	Width:   1200,
+	Height:  600,
+	LogFreq: true,
+	MinDB:   -120,
+	MaxDB:   0,
+}
+
+// Spectrum renders the magnitude spectrum of s in dB. Every pixel column shows
+// the highest bin in its frequency range, so narrow peaks stay visible even if
+// many bins share a column. Peaks are marked and labeled with their frequency.
+func Spectrum(s dft.Spectrum, opts SpectrumOptions) (*image.RGBA, error) {
+	scale := Linear
+	if opts.LogFreq {
+		scale = Log
+	}
+	x, err := frequencyAxis(s.FreqRes(), float64(s.SampleRate)/2, opts.MinHz, opts.MaxHz, scale)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxDB <= opts.MinDB {
+		return nil, fmt.Errorf("invalid dB range %g..%g", opts.MinDB, opts.MaxDB)
+	}
+	if opts.Width <= marginLeft+marginRight || opts.Height <= marginTop+marginBottom {
+		return nil, fmt.Errorf("image size %dx%d is too small", opts.Width, opts.Height)
+	}
+
+	f := newFrame(opts.Width, opts.Height, x, axis{min: opts.MinDB, max: opts.MaxDB}, "Frequency (Hz)", "dB")
+	f.grid(formatHz, formatDB)
+
+	db := s.DB(dft.DBOptions{})
+	res := s.FreqRes()
+	prevX, prevY := -1, 0
+	for col := 0; col < f.area.Dx(); col++ {
+		// range of bins that fall into this column
+		lo := int(math.Ceil(f.xAxis.value((float64(col)-0.5)/float64(f.area.Dx()-1)) / res))
+		hi := int(math.Floor(f.xAxis.value((float64(col)+0.5)/float64(f.area.Dx()-1)) / res))
+		if hi < lo {
+			// less than one bin per column, use the nearest bin
+			pos := int(math.Round(f.xAxis.value(float64(col)/float64(f.area.Dx()-1)) / res))
+			lo, hi = pos, pos
+		}
+		lo, hi = max(lo, 0), min(hi, len(db)-1)
+		if lo > hi {
+			continue
+		}
+		level := db[lo]
+		for _, v := range db[lo+1 : hi+1] {
+			level = math.Max(level, v)
+		}
+
+		px, py := f.area.Min.X+col, f.py(level)
+		if prevX >= 0 {
+			line(f.img, prevX, prevY, px, py, lineColor)
+		}
+		prevX, prevY = px, py
+	}
+
+	for _, p := range opts.Peaks {
+		if p.FreqHz < x.min || p.FreqHz > x.max {
+			continue
+		}
+		px, py := f.px(p.FreqHz), f.py(p.MagnitudeDB)
+		line(f.img, px-3, py-3, px+3, py+3, peakColor)
+		line(f.img, px-3, py+3, px+3, py-3, peakColor)
+		label := fmt.Sprintf("%.1f Hz", p.FreqHz)
+		lx := min(px+5, f.area.Max.X-textWidth(label))
+		drawText(f.img, lx, max(py-12, f.area.Min.Y), label, peakColor)
+	}
+
+	f.axes(opts.Title, formatHz, formatDB)
+	return f.img, nil
+}
+
+// frequencyAxis returns the frequency axis from minHz to maxHz, defaulting to
+// the first bin and nyquist
+func frequencyAxis(res, nyquist, minHz, maxHz float64, scale Scale) (axis, error) {
+	if maxHz <= 0 || maxHz > nyquist {
+		maxHz = nyquist
+	}
+	if minHz <= 0 && scale == Log {
+		minHz = res
+	}
+	if minHz < 0 || minHz >= maxHz {
+		return axis{}, fmt.Errorf("invalid frequency range %g..%g Hz", minHz, maxHz)
+	}
+	return axis{min: minHz, max: maxHz, scale: scale}, nil
+}
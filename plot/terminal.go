@@ -0,0 +1,137 @@
+package plot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// TerminalOptions configures a Terminal
+type TerminalOptions struct {
+	Width, Height int     // columns and rows of the bar graph
+	MinHz, MaxHz  float64 // frequency range, 0 selects the first bin and the Nyquist frequency
+	FreqScale     Scale
+	MinDB, MaxDB  float64
+	ColorMap      ColorMap
+
+	// Waterfall prints one line of colored cells per spectrum, so the terminal
+	// scrolls through the history, instead of redrawing a bar graph in place
+	Waterfall bool
+}
+
+// DefaultTerminalOptions draws an 80x16 bar graph from -100 to 0 dB over a
+// logarithmic frequency axis
+var DefaultTerminalOptions = TerminalOptions{
+	Width:     80,
+	Height:    16,
+	FreqScale: Log,
+	MinDB:     -100,
+	MaxDB:     0,
+	ColorMap:  Viridis,
+}
+
+// blocks are the characters of the eighths of a bar
+var blocks = []rune(" ▁▂▃▄▅▆▇█")
+
+// Terminal draws spectra to a terminal with ANSI escape sequences and 24 bit
+// colors, e.g. for a quick look over SSH
+type Terminal struct {
+	out      *bufio.Writer
+	opts     TerminalOptions
+	cmap     ColorMap
+	level    axis
+	binRange [][2]int
+	labels   string
+	drawn    bool
+}
+
+// NewTerminal creates a Terminal writing to w for the magnitude spectra of
+// fftSize point FFTs at sampleRate
+func NewTerminal(w io.Writer, sampleRate, fftSize int, opts TerminalOptions) (*Terminal, error) {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return nil, fmt.Errorf("invalid terminal size %dx%d", opts.Width, opts.Height)
+	}
+	if opts.MaxDB <= opts.MinDB {
+		return nil, fmt.Errorf("invalid dB range %g..%g", opts.MinDB, opts.MaxDB)
+	}
+	res := float64(sampleRate) / float64(fftSize)
+	x, err := frequencyAxis(res, float64(sampleRate)/2, opts.MinHz, opts.MaxHz, opts.FreqScale)
+	if err != nil {
+		return nil, err
+	}
+	cmap := opts.ColorMap
+	if len(cmap) == 0 {
+		cmap = Viridis
+	}
+	return &Terminal{
+		out:      bufio.NewWriter(w),
+		opts:     opts,
+		cmap:     cmap,
+		level:    axis{min: opts.MinDB, max: opts.MaxDB},
+		binRange: binRanges(x, res, opts.Width, fftSize/2+1),
+		labels:   axisLabels(x, opts.Width),
+	}, nil
+}
+
+// Draw draws a magnitude spectrum, e.g. Spectrum.Magnitude
+func (t *Terminal) Draw(magnitude []float64) error {
+	levels := make([]float64, len(t.binRange))
+	for i, r := range t.binRange {
+		peak := 0.0
+		for _, m := range magnitude[min(r[0], len(magnitude)):min(r[1]+1, len(magnitude))] {
+			peak = math.Max(peak, m)
+		}
+		levels[i] = math.Max(0, math.Min(1, t.level.pos(dft.AmplitudeToDB(peak, dft.DBOptions{}))))
+	}
+
+	if t.opts.Waterfall {
+		if !t.drawn {
+			fmt.Fprintln(t.out, t.labels)
+		}
+		for _, l := range levels {
+			c := t.cmap.At(l)
+			fmt.Fprintf(t.out, "\x1b[48;2;%d;%d;%dm ", c.R, c.G, c.B)
+		}
+		fmt.Fprint(t.out, "\x1b[0m\n")
+	} else {
+		if t.drawn {
+			// move back to the top left corner of the graph
+			fmt.Fprintf(t.out, "\x1b[%dA\r", t.opts.Height+1)
+		}
+		for row := t.opts.Height - 1; row >= 0; row-- {
+			c := t.cmap.At(float64(row) / float64(max(t.opts.Height-1, 1)))
+			fmt.Fprintf(t.out, "\x1b[38;2;%d;%d;%dm", c.R, c.G, c.B)
+			for _, l := range levels {
+				eighths := int(math.Round(l*float64(t.opts.Height*8))) - row*8
+				fmt.Fprint(t.out, string(blocks[max(0, min(8, eighths))]))
+			}
+			fmt.Fprint(t.out, "\x1b[0m\n")
+		}
+		fmt.Fprintln(t.out, t.labels)
+	}
+	t.drawn = true
+	return t.out.Flush()
+}
+
+// axisLabels returns a line of frequency labels for a frequency axis of width
+// columns
+func axisLabels(x axis, width int) string {
+	line := []byte(strings.Repeat(" ", width))
+	next := 0
+	for _, tk := range x.ticks(width/10, formatHz) {
+		if tk.label == "" {
+			continue
+		}
+		col := int(x.pos(tk.value) * float64(width-1))
+		if col < next || col+len(tk.label) > width {
+			continue
+		}
+		copy(line[col:], tk.label)
+		next = col + len(tk.label) + 1
+	}
+	return string(line)
+}
@@ -0,0 +1,120 @@
+package plot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// WaterfallOptions configures a Waterfall
+type WaterfallOptions struct {
+	Width        int     // pixels along the frequency axis
+	History      int     // number of spectra shown, one pixel row each
+	MinHz, MaxHz float64 // frequency range, 0 selects the first bin and the Nyquist frequency
+	FreqScale    Scale
+	MinDB, MaxDB float64
+	ColorMap     ColorMap
+}
+
+// DefaultWaterfallOptions shows the last 256 spectra from -120 to 0 dB over a
+// logarithmic frequency axis
+var DefaultWaterfallOptions = WaterfallOptions{
+	Width:     800,
+	History:   256,
+	FreqScale: Log,
+	MinDB:     -120,
+	MaxDB:     0,
+	ColorMap:  Viridis,
+}
+
+// Waterfall is a scrolling spectrogram of a stream of spectra: the newest
+// spectrum is the top row and older ones move down until they drop out of the
+// history. Its images use a palette, so they can be written as animated GIF.
+type Waterfall struct {
+	opts     WaterfallOptions
+	palette  color.Palette
+	level    axis
+	binRange [][2]int
+	rows     [][]uint8 // palette indices of the rows, newest first
+}
+
+// NewWaterfall creates a Waterfall for the magnitude spectra of fftSize point
+// FFTs at sampleRate
+func NewWaterfall(sampleRate, fftSize int, opts WaterfallOptions) (*Waterfall, error) {
+	if opts.Width <= 0 || opts.History <= 0 {
+		return nil, fmt.Errorf("invalid waterfall size %dx%d", opts.Width, opts.History)
+	}
+	if opts.MaxDB <= opts.MinDB {
+		return nil, fmt.Errorf("invalid dB range %g..%g", opts.MinDB, opts.MaxDB)
+	}
+	res := float64(sampleRate) / float64(fftSize)
+	x, err := frequencyAxis(res, float64(sampleRate)/2, opts.MinHz, opts.MaxHz, opts.FreqScale)
+	if err != nil {
+		return nil, err
+	}
+	cmap := opts.ColorMap
+	if len(cmap) == 0 {
+		cmap = Viridis
+	}
+
+	palette := make(color.Palette, 256)
+	for i := range palette {
+		palette[i] = cmap.At(float64(i) / 255)
+	}
+	return &Waterfall{
+		opts:     opts,
+		palette:  palette,
+		level:    axis{min: opts.MinDB, max: opts.MaxDB},
+		binRange: binRanges(x, res, opts.Width, fftSize/2+1),
+	}, nil
+}
+
+// Push adds a magnitude spectrum, e.g. Spectrum.Magnitude, as the newest row
+func (w *Waterfall) Push(magnitude []float64) {
+	var row []uint8
+	if len(w.rows) == w.opts.History {
+		// reuse the oldest row
+		row = w.rows[len(w.rows)-1]
+		w.rows = w.rows[:len(w.rows)-1]
+	} else {
+		row = make([]uint8, w.opts.Width)
+	}
+
+	for i, r := range w.binRange {
+		peak := 0.0
+		for _, m := range magnitude[min(r[0], len(magnitude)):min(r[1]+1, len(magnitude))] {
+			peak = math.Max(peak, m)
+		}
+		p := w.level.pos(dft.AmplitudeToDB(peak, dft.DBOptions{}))
+		row[i] = uint8(math.Round(math.Max(0, math.Min(1, p)) * 255))
+	}
+	w.rows = append([][]uint8{row}, w.rows...)
+}
+
+// Image renders the current history. Rows that were not pushed yet are drawn
+// in the color of the lowest level.
+func (w *Waterfall) Image() *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, w.opts.Width, w.opts.History), w.palette)
+	for y, row := range w.rows {
+		copy(img.Pix[y*img.Stride:], row)
+	}
+	return img
+}
+
+// WriteGIF writes images as an animated GIF that shows every image for delay
+// hundredths of a second and loops forever
+func WriteGIF(out io.Writer, images []*image.Paletted, delay int) error {
+	if len(images) == 0 {
+		return fmt.Errorf("no images to write")
+	}
+	anim := &gif.GIF{Image: images, Delay: make([]int, len(images))}
+	for i := range anim.Delay {
+		anim.Delay[i] = delay
+	}
+	return gif.EncodeAll(out, anim)
+}
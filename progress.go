@@ -0,0 +1,50 @@
+package dft
+
+import "time"
+
+// Progress is the state of a long running operation, passed to a ProgressFunc
+type Progress struct {
+	Done    int64 // units of work done, e.g. samples or frames
+	Total   int64 // units of the whole operation, 0 if unknown
+	Elapsed time.Duration
+}
+
+// Fraction returns the done part of the operation in [0..1], or 0 if the total
+// is unknown
+func (p Progress) Fraction() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return min(float64(p.Done)/float64(p.Total), 1)
+}
+
+// ETA estimates the remaining time from the rate so far, 0 if the total is
+// unknown or nothing is done yet
+func (p Progress) ETA() time.Duration {
+	if p.Total <= 0 || p.Done <= 0 || p.Done >= p.Total {
+		return 0
+	}
+	return time.Duration(float64(p.Elapsed) * float64(p.Total-p.Done) / float64(p.Done))
+}
+
+// ProgressFunc is called repeatedly during a long running operation. It is
+// called from the goroutine of the operation and should return quickly.
+type ProgressFunc func(Progress)
+
+// progressReporter calls a ProgressFunc, if not nil, with the elapsed time
+// since its creation
+type progressReporter struct {
+	fn    ProgressFunc
+	start time.Time
+	total int64
+}
+
+func newProgressReporter(fn ProgressFunc, total int64) progressReporter {
+	return progressReporter{fn: fn, start: time.Now(), total: total}
+}
+
+func (r progressReporter) report(done int64) {
+	if r.fn != nil {
+		r.fn(Progress{Done: done, Total: r.total, Elapsed: time.Since(r.start)})
+	}
+}
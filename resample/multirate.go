@@ -0,0 +1,34 @@
+package resample
+
+import "fmt"
+
+// Decimate low-pass filters x below the new Nyquist frequency and keeps every
+// factor-th sample. The result has ceil(len(x)/factor) samples and no delay.
+func Decimate(x []float64, factor int) ([]float64, error) {
+	if factor < 1 {
+		return nil, fmt.Errorf("invalid decimation factor %d", factor)
+	}
+	if factor == 1 {
+		return append([]float64(nil), x...), nil
+	}
+	if factor > maxFactor {
+		return nil, fmt.Errorf("decimation factor %d exceeds %d", factor, maxFactor)
+	}
+	return polyphase(x, 1, factor), nil
+}
+
+// Interpolate inserts factor-1 samples between the samples of x and removes the
+// resulting images above the original Nyquist frequency. The result has
+// len(x)*factor samples and no delay.
+func Interpolate(x []float64, factor int) ([]float64, error) {
+	if factor < 1 {
+		return nil, fmt.Errorf("invalid interpolation factor %d", factor)
+	}
+	if factor == 1 {
+		return append([]float64(nil), x...), nil
+	}
+	if factor > maxFactor {
+		return nil, fmt.Errorf("interpolation factor %d exceeds %d", factor, maxFactor)
+	}
+	return polyphase(x, factor, 1), nil
+}
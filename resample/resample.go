@@ -0,0 +1,99 @@
+// Package resample converts signals between sample rates.
+package resample
+
+import (
+	"fmt"
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+const (
+	halfTaps   = 48   // taps of the prototype filter on each side of its center, per input or output sample
+	cutoff     = 0.92 // cutoff of the anti-alias filter relative to the lower Nyquist frequency
+	kaiserBeta = 8.6  // about 86 dB stopband attenuation
+	maxFactor  = 4096 // upper limit of the up- and downsampling factors
+)
+
+// Resample converts samples from fromRate to toRate with a polyphase
+// windowed-sinc filter. The rate ratio is reduced to up/down factors, e.g.
+// 44100 to 48000 Hz upsamples by 160 and downsamples by 147.
+//
+// The passband is flat up to about 86% of the lower Nyquist frequency and
+// aliases are attenuated by more than 80 dB. The output is aligned with the
+// input, i.e. it has no delay, and has ceil(len(samples)*toRate/fromRate)
+// samples.
+func Resample(samples []float64, fromRate, toRate int) ([]float64, error) {
+	if fromRate <= 0 || toRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rates %d and %d", fromRate, toRate)
+	}
+	g := gcd(fromRate, toRate)
+	up, down := toRate/g, fromRate/g
+	if up == down {
+		return append([]float64(nil), samples...), nil
+	}
+	if up > maxFactor || down > maxFactor {
+		return nil, fmt.Errorf("rate ratio %d/%d is too complex", toRate, fromRate)
+	}
+	return polyphase(samples, up, down), nil
+}
+
+// polyphase upsamples x by up, applies the anti-alias filter and downsamples by
+// down, only evaluating the taps that hit non-zero input samples
+func polyphase(x []float64, up, down int) []float64 {
+	h := prototype(up, down)
+	delay := (len(h) - 1) / 2
+
+	out := make([]float64, (len(x)*up+down-1)/down)
+	for m := range out {
+		pos := m*down + delay // position in the upsampled signal
+		sum := 0.0
+		for k := pos % up; k < len(h); k += up {
+			i := (pos - k) / up
+			if i < 0 {
+				break
+			}
+			if i < len(x) {
+				sum += h[k] * x[i]
+			}
+		}
+		out[m] = sum
+	}
+	return out
+}
+
+// prototype returns the low-pass filter at the upsampled rate, with a gain of up
+// to compensate for the inserted zeros
+func prototype(up, down int) []float64 {
+	factor := up
+	if down > factor {
+		factor = down
+	}
+	fc := cutoff * 0.5 / float64(factor)
+
+	n := 2*halfTaps*factor + 1
+	h := make([]float64, n)
+	w := dft.Window{Type: dft.Kaiser, Beta: kaiserBeta}.Coefficients(n)
+	sum := 0.0
+	for i := range h {
+		x := float64(i - (n-1)/2)
+		if x == 0 {
+			h[i] = 2 * fc
+		} else {
+			h[i] = math.Sin(2*math.Pi*fc*x) / (math.Pi * x)
+		}
+		h[i] *= w[i]
+		sum += h[i]
+	}
+	for i := range h {
+		h[i] *= float64(up) / sum
+	}
+	return h
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
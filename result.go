@@ -0,0 +1,121 @@
+package dft
+
+import "fmt"
+
+// AmplitudeNormalization is the Normalization of ComputeSpectrum and
+// ComputeWindowedSpectrum: the magnitudes are divided by the signal length and
+// the coherent gain of the window, so a sine of amplitude A has a magnitude of A
+const AmplitudeNormalization = "amplitude"
+
+// Provenance records every parameter that influenced the numbers of an
+// analysis, so that consumers can check whether two results are comparable
+type Provenance struct {
+	SampleRate    int     `json:"sample_rate"`
+	Window        Window  `json:"window"`
+	FFTSize       int     `json:"fft_size"`
+	FrameSize     int     `json:"frame_size"`         // samples per transformed segment before zero-padding
+	HopSize       int     `json:"hop_size,omitempty"` // samples between the segments, 0 for a single spectrum
+	Normalization string  `json:"normalization"`
+	Detrend       Detrend `json:"detrend,omitempty"`
+	GainDB        float64 `json:"gain_db,omitempty"` // gain applied to the input before the analysis, e.g. by normalization
+	Offset        int     `json:"offset"`            // first analyzed sample in the source signal
+	Length        int     `json:"length"`            // number of analyzed samples
+}
+
+// Overlap returns the overlap of consecutive segments in [0..1)
+func (p Provenance) Overlap() float64 {
+	if p.HopSize <= 0 || p.HopSize >= p.FrameSize {
+		return 0
+	}
+	return 1 - float64(p.HopSize)/float64(p.FrameSize)
+}
+
+// BinSpacing returns the distance of the bins in Hz, which zero-padding
+// narrows
+func (p Provenance) BinSpacing() float64 {
+	return float64(p.SampleRate) / float64(p.FFTSize)
+}
+
+// Resolution returns the effective frequency resolution in Hz, the noise
+// bandwidth of the window over a segment. Unlike the bin spacing it does not
+// improve with zero-padding, only with longer segments.
+func (p Provenance) Resolution() float64 {
+	return p.Window.ENBW() * float64(p.SampleRate) / float64(p.FrameSize)
+}
+
+// OffsetSeconds returns the start of the analyzed part of the source
+func (p Provenance) OffsetSeconds() float64 {
+	return float64(p.Offset) / float64(p.SampleRate)
+}
+
+// Comparable returns an error describing the first parameter that differs
+// between p and q in a way that changes the meaning of the numbers. Offsets
+// may differ, and so may the lengths of STFTs; the frame size of a single
+// spectrum is its length.
+func (p Provenance) Comparable(q Provenance) error {
+	switch {
+	case p.SampleRate != q.SampleRate:
+		return fmt.Errorf("sample rates differ: %d Hz and %d Hz", p.SampleRate, q.SampleRate)
+	case p.Window != q.Window:
+		return fmt.Errorf("windows differ: %s and %s", p.Window, q.Window)
+	case p.FFTSize != q.FFTSize:
+		return fmt.Errorf("FFT sizes differ: %d and %d", p.FFTSize, q.FFTSize)
+	case p.FrameSize != q.FrameSize:
+		return fmt.Errorf("frame sizes differ: %d and %d", p.FrameSize, q.FrameSize)
+	case p.HopSize != q.HopSize:
+		return fmt.Errorf("hop sizes differ: %d and %d", p.HopSize, q.HopSize)
+	case p.Normalization != q.Normalization:
+		return fmt.Errorf("normalizations differ: %s and %s", p.Normalization, q.Normalization)
+	case p.Detrend != q.Detrend:
+		return fmt.Errorf("detrending differs: %s and %s", p.Detrend, q.Detrend)
+	}
+	return nil
+}
+
+// Result is an analysis together with its Provenance. Spectrum is set by
+// Analyze, Frames by AnalyzeFrames.
+type Result struct {
+	Provenance
+	Spectrum Spectrum
+	Frames   []Frame
+}
+
+// Analyze computes the spectrum of wave with ComputeWindowedSpectrum. offset
+// is the position of wave in the source signal and only recorded.
+func Analyze(wave []float64, sampleRate, offset int, opts SpectrumOptions) (Result, error) {
+	s, err := ComputeWindowedSpectrum(wave, sampleRate, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Provenance: newProvenance(sampleRate, opts, s.FFTSize, len(wave), 0, offset, len(wave)),
+		Spectrum:   s,
+	}, nil
+}
+
+// AnalyzeFrames computes the frames of samples with WindowedSTFT. offset is
+// the position of samples in the source signal and only recorded.
+func AnalyzeFrames(samples []float64, sampleRate, offset, frameSize, hopSize int, opts SpectrumOptions) (Result, error) {
+	frames, fftSize, err := WindowedSTFT(samples, sampleRate, frameSize, hopSize, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Provenance: newProvenance(sampleRate, opts, fftSize, frameSize, hopSize, offset, len(samples)),
+		Frames:     frames,
+	}, nil
+}
+
+func newProvenance(sampleRate int, opts SpectrumOptions, fftSize, frameSize, hopSize, offset, length int) Provenance {
+	return Provenance{
+		SampleRate:    sampleRate,
+		Window:        opts.Window,
+		Detrend:       opts.Detrend,
+		FFTSize:       fftSize,
+		FrameSize:     frameSize,
+		HopSize:       hopSize,
+		Normalization: AmplitudeNormalization,
+		Offset:        offset,
+		Length:        length,
+	}
+}
@@ -0,0 +1,94 @@
+package rhythm
+
+import (
+	"math"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// DefaultTightness is a sensible default for the tightness parameter of TrackBeats
+const DefaultTightness = 100.0
+
+// BeatGrid is the result of TrackBeats
+type BeatGrid struct {
+	Tempo TempoEstimate
+	Beats []float64 // beat times in seconds
+}
+
+// TrackBeats estimates the tempo of samples and returns the times of the
+// individual beats.
+//
+// Beats are placed with dynamic programming (Ellis, 2007): every frame is scored
+// by its onset strength plus the best score of a previous beat, penalized by how
+// far the inter-beat interval deviates from the estimated tempo. Higher values
+// of tightness keep the beats closer to a strict grid, see DefaultTightness.
+func TrackBeats(samples []float64, sampleRate, frameSize, hopSize int, minBPM, maxBPM, tightness float64) BeatGrid {
+	env := onsetEnvelope(samples, sampleRate, frameSize, hopSize)
+	frameRate := float64(sampleRate) / float64(hopSize)
+	tempo := tempoFromEnvelope(env, frameRate, minBPM, maxBPM)
+	if tempo.BPM == 0 {
+		return BeatGrid{Tempo: tempo, Beats: []float64{}}
+	}
+
+	beats := []float64{}
+	for _, frame := range trackBeatFrames(env, 60*frameRate/tempo.BPM, tightness) {
+		beats = append(beats, dft.FrameTime(frame, sampleRate, frameSize, hopSize))
+	}
+	return BeatGrid{Tempo: tempo, Beats: beats}
+}
+
+// trackBeatFrames returns the frame indices of the beats in env for a beat
+// period given in frames.
+func trackBeatFrames(env []float64, period, tightness float64) []int {
+	n := len(env)
+	if n == 0 || period < 1 {
+		return nil
+	}
+
+	score := make([]float64, n)
+	backlink := make([]int, n)
+	for t := 0; t < n; t++ {
+		score[t] = env[t]
+		backlink[t] = -1
+
+		// Search previous beats between half and twice the period
+		from := t - int(math.Round(2*period))
+		to := t - int(math.Round(period/2))
+		if from < 0 {
+			from = 0
+		}
+		best := math.Inf(-1)
+		for prev := from; prev <= to; prev++ {
+			penalty := math.Log(float64(t-prev) / period)
+			s := score[prev] - tightness*penalty*penalty
+			if s > best {
+				best = s
+				backlink[t] = prev
+			}
+		}
+		if backlink[t] >= 0 {
+			score[t] += best
+		}
+	}
+
+	// The last beat is the best scoring frame within the last period
+	last := n - 1
+	start := n - int(math.Round(period))
+	if start < 0 {
+		start = 0
+	}
+	for t := start; t < n; t++ {
+		if score[t] > score[last] {
+			last = t
+		}
+	}
+
+	frames := []int{}
+	for t := last; t >= 0; t = backlink[t] {
+		frames = append(frames, t)
+	}
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames
+}
@@ -0,0 +1,75 @@
+// Package rhythm implements onset detection, tempo estimation and beat tracking
+// on top of the short-time fourier transform.
+package rhythm
+
+import (
+	"sort"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/features"
+)
+
+// thresholdWindow is the length in seconds of the half window used for the
+// adaptive onset threshold and the minimum distance between two onsets
+const thresholdWindow = 0.05
+
+// DetectOnsets returns the onset times in seconds found in samples.
+//
+// The novelty curve (see features.Novelty) is peak picked against an adaptive
+// threshold: a frame is an onset if it is a local maximum and exceeds the local
+// median by at least delta. Typical values for delta are 0.03..0.1.
+func DetectOnsets(samples []float64, sampleRate, frameSize, hopSize int, delta float64) []float64 {
+	flux := onsetEnvelope(samples, sampleRate, frameSize, hopSize)
+	w := int(thresholdWindow * float64(sampleRate) / float64(hopSize))
+	if w < 1 {
+		w = 1
+	}
+
+	onsets := []float64{}
+	last := -w - 1
+	for i := range flux {
+		start, end := i-w, i+w
+		if start < 0 {
+			start = 0
+		}
+		if end >= len(flux) {
+			end = len(flux) - 1
+		}
+
+		isMax := true
+		for j := start; j <= end; j++ {
+			if flux[j] > flux[i] {
+				isMax = false
+				break
+			}
+		}
+		if !isMax || flux[i] < median(flux[start:end+1])+delta {
+			continue
+		}
+		if i-last <= w {
+			continue
+		}
+
+		last = i
+		onsets = append(onsets, dft.FrameTime(i, sampleRate, frameSize, hopSize))
+	}
+	return onsets
+}
+
+// onsetEnvelope computes the novelty curve of samples, one value per STFT frame
+func onsetEnvelope(samples []float64, sampleRate, frameSize, hopSize int) []float64 {
+	return features.Novelty(samples, sampleRate, frameSize, hopSize)
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,110 @@
+package rhythm
+
+import (
+	"math"
+	"sort"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// preferredBPM is the center of the tempo prior used to resolve octave errors
+const preferredBPM = 120.0
+
+// maxTempoCandidates is the number of candidates returned by EstimateTempo
+const maxTempoCandidates = 5
+
+// TempoCandidate is a possible tempo with its relative strength
+type TempoCandidate struct {
+	BPM      float64
+	Strength float64 // weighted normalized autocorrelation in the range [0..1]
+}
+
+// TempoEstimate is the result of EstimateTempo
+type TempoEstimate struct {
+	BPM        float64 // most likely tempo, 0 if none was found
+	Confidence float64 // strength of the best candidate in the range [0..1]
+	Candidates []TempoCandidate
+}
+
+// EstimateTempo estimates the tempo of samples in beats per minute.
+//
+// The autocorrelation of the onset envelope (see DetectOnsets) is searched for
+// periodicities between minBPM and maxBPM. The peaks are weighted with a
+// log-normal prior centered at 120 BPM to reduce octave errors and returned as
+// candidates, strongest first.
+func EstimateTempo(samples []float64, sampleRate, frameSize, hopSize int, minBPM, maxBPM float64) TempoEstimate {
+	env := onsetEnvelope(samples, sampleRate, frameSize, hopSize)
+	return tempoFromEnvelope(env, float64(sampleRate)/float64(hopSize), minBPM, maxBPM)
+}
+
+// tempoFromEnvelope estimates the tempo from an onset envelope sampled at frameRate frames per second
+func tempoFromEnvelope(env []float64, frameRate, minBPM, maxBPM float64) TempoEstimate {
+	if len(env) < 2 || minBPM <= 0 || maxBPM <= minBPM {
+		return TempoEstimate{}
+	}
+
+	// Remove the mean so the autocorrelation is not dominated by the DC offset
+	mean := 0.0
+	for _, v := range env {
+		mean += v
+	}
+	mean /= float64(len(env))
+	centered := make([]float64, len(env))
+	for i, v := range env {
+		centered[i] = v - mean
+	}
+
+	r := dft.AutoCorr(centered)
+	if r[0] <= 0 {
+		return TempoEstimate{}
+	}
+
+	minLag := int(math.Floor(60 * frameRate / maxBPM))
+	maxLag := int(math.Ceil(60 * frameRate / minBPM))
+	if minLag < 1 {
+		minLag = 1
+	}
+	if maxLag >= len(r)-1 {
+		maxLag = len(r) - 2
+	}
+
+	candidates := []TempoCandidate{}
+	for lag := minLag; lag <= maxLag; lag++ {
+		if r[lag] <= 0 || r[lag] < r[lag-1] || r[lag] < r[lag+1] {
+			continue
+		}
+
+		// Parabolic interpolation of the peak lag
+		exact := float64(lag)
+		denom := r[lag-1] - 2*r[lag] + r[lag+1]
+		if denom != 0 {
+			exact += 0.5 * (r[lag-1] - r[lag+1]) / denom
+		}
+
+		bpm := 60 * frameRate / exact
+		if bpm < minBPM || bpm > maxBPM {
+			continue
+		}
+		prior := math.Exp(-0.5 * math.Pow(math.Log2(bpm/preferredBPM), 2))
+		candidates = append(candidates, TempoCandidate{
+			BPM:      bpm,
+			Strength: math.Min(1, r[lag]/r[0]*prior),
+		})
+	}
+	if len(candidates) == 0 {
+		return TempoEstimate{}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Strength > candidates[j].Strength
+	})
+	if len(candidates) > maxTempoCandidates {
+		candidates = candidates[:maxTempoCandidates]
+	}
+
+	return TempoEstimate{
+		BPM:        candidates[0].BPM,
+		Confidence: candidates[0].Strength,
+		Candidates: candidates,
+	}
+}
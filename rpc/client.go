@@ -0,0 +1,207 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the Analysis service of a gRPC server over unencrypted HTTP/2
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient returns a Client for the server at addr, e.g. "localhost:9090"
+func NewClient(addr string) *Client {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	return &Client{
+		baseURL: "http://" + addr + servicePath,
+		http:    &http.Client{Transport: &http.Transport{Protocols: &protocols}},
+	}
+}
+
+// call starts a call of method with the request body body
+func (c *Client) call(ctx context.Context, method string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+method, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/grpc+proto")
+	req.Header.Set("Te", "trailers")
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set("Grpc-Timeout", strconv.FormatInt(max(time.Until(deadline).Milliseconds(), 0), 10)+"m")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errorf(CodeInternal, "unexpected HTTP status %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// clientStream reads the response messages of a call
+type clientStream struct {
+	resp *http.Response
+}
+
+// recv reads the next message into m. At the end of the response it returns
+// io.EOF if the call succeeded and its status otherwise.
+func (s *clientStream) recv(m interface{ Unmarshal([]byte) error }) error {
+	data, err := readMessage(s.resp.Body, s.resp.Header.Get("Grpc-Encoding"), 0)
+	if err == io.EOF {
+		return s.status()
+	}
+	if err != nil {
+		return err
+	}
+	return m.Unmarshal(data)
+}
+
+// status returns the status of the finished call, io.EOF on success
+func (s *clientStream) status() error {
+	code := s.resp.Trailer.Get("Grpc-Status")
+	msg := s.resp.Trailer.Get("Grpc-Message")
+	if code == "" {
+		// Trailers-only response
+		code, msg = s.resp.Header.Get("Grpc-Status"), s.resp.Header.Get("Grpc-Message")
+	}
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return errorf(CodeInternal, "missing status")
+	}
+	if n == CodeOK {
+		return io.EOF
+	}
+	if m, err := decodeMessage(msg); err == nil {
+		msg = m
+	}
+	return &Error{Code: n, Message: msg}
+}
+
+// decodeMessage reverses encodeMessage
+func decodeMessage(msg string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		if msg[i] != '%' {
+			b.WriteByte(msg[i])
+			continue
+		}
+		if i+2 >= len(msg) {
+			return "", fmt.Errorf("invalid escape in %q", msg)
+		}
+		v, err := strconv.ParseUint(msg[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(byte(v))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+func encodeRequest(m interface{ Marshal() []byte }) io.Reader {
+	var b bytes.Buffer
+	writeMessage(&b, m.Marshal())
+	return &b
+}
+
+// Analyze computes the spectrum and peaks of a signal
+func (c *Client) Analyze(ctx context.Context, req *AnalyzeRequest) (*AnalyzeResponse, error) {
+	resp, err := c.call(ctx, "Analyze", encodeRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	s := &clientStream{resp: resp}
+	var out AnalyzeResponse
+	if err := s.recv(&out); err != nil {
+		if err == io.EOF {
+			err = errorf(CodeInternal, "missing response message")
+		}
+		return nil, err
+	}
+	if err := s.recv(&out); err != io.EOF {
+		if err == nil {
+			err = errorf(CodeInternal, "unexpected response message")
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Spectrogram computes the short-time fourier transform of a signal and calls
+// fn for every frame
+func (c *Client) Spectrogram(ctx context.Context, req *SpectrogramRequest, fn func(*SpectrogramFrame) error) error {
+	resp, err := c.call(ctx, "Spectrogram", encodeRequest(req))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	s := &clientStream{resp: resp}
+	for {
+		var frame SpectrogramFrame
+		if err := s.recv(&frame); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(&frame); err != nil {
+			return err
+		}
+	}
+}
+
+// AnalyzeStream is a StreamAnalyze call. Send and Recv may be used
+// concurrently.
+type AnalyzeStream struct {
+	w    *io.PipeWriter
+	recv clientStream
+}
+
+// StreamAnalyze starts the analysis of a stream of audio chunks
+func (c *Client) StreamAnalyze(ctx context.Context) (*AnalyzeStream, error) {
+	pr, pw := io.Pipe()
+	resp, err := c.call(ctx, "StreamAnalyze", pr)
+	if err != nil {
+		pw.Close()
+		return nil, err
+	}
+	return &AnalyzeStream{w: pw, recv: clientStream{resp: resp}}, nil
+}
+
+// Send sends a chunk of audio, the first chunk configures the stream
+func (s *AnalyzeStream) Send(chunk *AudioChunk) error {
+	return writeMessage(s.w, chunk.Marshal())
+}
+
+// CloseSend ends the audio stream, the server sends the remaining blocks and
+// finishes the call
+func (s *AnalyzeStream) CloseSend() error {
+	return s.w.Close()
+}
+
+// Recv returns the next spectrum block. It returns io.EOF when the call has
+// finished successfully.
+func (s *AnalyzeStream) Recv() (*SpectrumBlock, error) {
+	var block SpectrumBlock
+	if err := s.recv.recv(&block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// Close aborts the call
+func (s *AnalyzeStream) Close() error {
+	s.w.CloseWithError(io.ErrClosedPipe)
+	return s.recv.resp.Body.Close()
+}
@@ -0,0 +1,332 @@
+package rpc
+
+import (
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// The message types mirror analysis.proto. Marshal encodes a message in the
+// protocol buffer wire format and Unmarshal decodes it, unknown fields are
+// skipped.
+
+// AnalyzeRequest is the request of Analyze
+type AnalyzeRequest struct {
+	Samples    []float64
+	SampleRate int32
+	Peaks      *PeakOptions
+}
+
+// AnalyzeResponse is the response of Analyze
+type AnalyzeResponse struct {
+	SampleRate int32
+	FFTSize    int32
+	FreqRes    float64
+	Magnitude  []float64
+	Peaks      []Peak
+}
+
+// AudioChunk is a request message of StreamAnalyze. SampleRate, FrameSize,
+// HopSize and Peaks are only read from the first chunk of a stream.
+type AudioChunk struct {
+	Samples    []float64
+	SampleRate int32
+	FrameSize  int32
+	HopSize    int32
+	Peaks      *PeakOptions
+}
+
+// SpectrumBlock is a response message of StreamAnalyze
+type SpectrumBlock struct {
+	Index     int32
+	Time      float64
+	FreqRes   float64
+	Magnitude []float64
+	Peaks     []Peak
+}
+
+// SpectrogramRequest is the request of Spectrogram
+type SpectrogramRequest struct {
+	Samples    []float64
+	SampleRate int32
+	FrameSize  int32
+	HopSize    int32
+}
+
+// SpectrogramFrame is a response message of Spectrogram
+type SpectrogramFrame struct {
+	Time      float64
+	Magnitude []float64
+}
+
+// PeakOptions are the criteria of the peak detection, see dft.PeakOptions
+type PeakOptions struct {
+	MinHeight       float64
+	MinProminence   float64
+	MinDistanceHz   float64
+	MinAboveFloorDB float64
+	MaxPeaks        int32
+}
+
+// Peak is a detected peak, see dft.Peak
+type Peak struct {
+	FreqHz      float64
+	Magnitude   float64
+	MagnitudeDB float64
+	Phase       float64
+	Bin         int32
+	Prominence  float64
+}
+
+func (o *PeakOptions) options() dft.PeakOptions {
+	if o == nil {
+		return dft.PeakOptions{}
+	}
+	return dft.PeakOptions{
+		MinHeight:       o.MinHeight,
+		MinProminence:   o.MinProminence,
+		MinDistanceHz:   o.MinDistanceHz,
+		MinAboveFloorDB: o.MinAboveFloorDB,
+		MaxPeaks:        int(o.MaxPeaks),
+	}
+}
+
+func newPeaks(peaks []dft.Peak) []Peak {
+	out := make([]Peak, len(peaks))
+	for i, p := range peaks {
+		out[i] = Peak{
+			FreqHz:      p.FreqHz,
+			Magnitude:   p.Magnitude,
+			MagnitudeDB: p.MagnitudeDB,
+			Phase:       p.Phase,
+			Bin:         int32(p.BinIndex),
+			Prominence:  p.Prominence,
+		}
+	}
+	return out
+}
+
+func (o *PeakOptions) Marshal() []byte {
+	var b []byte
+	b = appendDouble(b, 1, o.MinHeight)
+	b = appendDouble(b, 2, o.MinProminence)
+	b = appendDouble(b, 3, o.MinDistanceHz)
+	b = appendDouble(b, 4, o.MinAboveFloorDB)
+	return appendInt32(b, 5, o.MaxPeaks)
+}
+
+func (o *PeakOptions) Unmarshal(b []byte) error {
+	return parseFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			o.MinHeight = f.double()
+		case 2:
+			o.MinProminence = f.double()
+		case 3:
+			o.MinDistanceHz = f.double()
+		case 4:
+			o.MinAboveFloorDB = f.double()
+		case 5:
+			o.MaxPeaks = f.int32()
+		}
+		return nil
+	})
+}
+
+func (p *Peak) Marshal() []byte {
+	var b []byte
+	b = appendDouble(b, 1, p.FreqHz)
+	b = appendDouble(b, 2, p.Magnitude)
+	b = appendDouble(b, 3, p.MagnitudeDB)
+	b = appendDouble(b, 4, p.Phase)
+	b = appendInt32(b, 5, p.Bin)
+	return appendDouble(b, 6, p.Prominence)
+}
+
+func (p *Peak) Unmarshal(b []byte) error {
+	return parseFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			p.FreqHz = f.double()
+		case 2:
+			p.Magnitude = f.double()
+		case 3:
+			p.MagnitudeDB = f.double()
+		case 4:
+			p.Phase = f.double()
+		case 5:
+			p.Bin = f.int32()
+		case 6:
+			p.Prominence = f.double()
+		}
+		return nil
+	})
+}
+
+func appendPeaks(b []byte, field int, peaks []Peak) []byte {
+	for i := range peaks {
+		b = appendMessage(b, field, peaks[i].Marshal())
+	}
+	return b
+}
+
+func parsePeak(peaks []Peak, f field) ([]Peak, error) {
+	var p Peak
+	if err := p.Unmarshal(f.bytes); err != nil {
+		return peaks, err
+	}
+	return append(peaks, p), nil
+}
+
+func parsePeakOptions(f field) (*PeakOptions, error) {
+	o := &PeakOptions{}
+	return o, o.Unmarshal(f.bytes)
+}
+
+func (r *AnalyzeRequest) Marshal() []byte {
+	var b []byte
+	b = appendDoubles(b, 1, r.Samples)
+	b = appendInt32(b, 2, r.SampleRate)
+	if r.Peaks != nil {
+		b = appendMessage(b, 3, r.Peaks.Marshal())
+	}
+	return b
+}
+
+func (r *AnalyzeRequest) Unmarshal(b []byte) error {
+	return parseFields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			r.Samples, err = f.doubles(r.Samples)
+		case 2:
+			r.SampleRate = f.int32()
+		case 3:
+			r.Peaks, err = parsePeakOptions(f)
+		}
+		return err
+	})
+}
+
+func (r *AnalyzeResponse) Marshal() []byte {
+	var b []byte
+	b = appendInt32(b, 1, r.SampleRate)
+	b = appendInt32(b, 2, r.FFTSize)
+	b = appendDouble(b, 3, r.FreqRes)
+	b = appendDoubles(b, 4, r.Magnitude)
+	return appendPeaks(b, 5, r.Peaks)
+}
+
+func (r *AnalyzeResponse) Unmarshal(b []byte) error {
+	return parseFields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			r.SampleRate = f.int32()
+		case 2:
+			r.FFTSize = f.int32()
+		case 3:
+			r.FreqRes = f.double()
+		case 4:
+			r.Magnitude, err = f.doubles(r.Magnitude)
+		case 5:
+			r.Peaks, err = parsePeak(r.Peaks, f)
+		}
+		return err
+	})
+}
+
+func (c *AudioChunk) Marshal() []byte {
+	var b []byte
+	b = appendDoubles(b, 1, c.Samples)
+	b = appendInt32(b, 2, c.SampleRate)
+	b = appendInt32(b, 3, c.FrameSize)
+	b = appendInt32(b, 4, c.HopSize)
+	if c.Peaks != nil {
+		b = appendMessage(b, 5, c.Peaks.Marshal())
+	}
+	return b
+}
+
+func (c *AudioChunk) Unmarshal(b []byte) error {
+	return parseFields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			c.Samples, err = f.doubles(c.Samples)
+		case 2:
+			c.SampleRate = f.int32()
+		case 3:
+			c.FrameSize = f.int32()
+		case 4:
+			c.HopSize = f.int32()
+		case 5:
+			c.Peaks, err = parsePeakOptions(f)
+		}
+		return err
+	})
+}
+
+func (s *SpectrumBlock) Marshal() []byte {
+	var b []byte
+	b = appendInt32(b, 1, s.Index)
+	b = appendDouble(b, 2, s.Time)
+	b = appendDouble(b, 3, s.FreqRes)
+	b = appendDoubles(b, 4, s.Magnitude)
+	return appendPeaks(b, 5, s.Peaks)
+}
+
+func (s *SpectrumBlock) Unmarshal(b []byte) error {
+	return parseFields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			s.Index = f.int32()
+		case 2:
+			s.Time = f.double()
+		case 3:
+			s.FreqRes = f.double()
+		case 4:
+			s.Magnitude, err = f.doubles(s.Magnitude)
+		case 5:
+			s.Peaks, err = parsePeak(s.Peaks, f)
+		}
+		return err
+	})
+}
+
+func (r *SpectrogramRequest) Marshal() []byte {
+	var b []byte
+	b = appendDoubles(b, 1, r.Samples)
+	b = appendInt32(b, 2, r.SampleRate)
+	b = appendInt32(b, 3, r.FrameSize)
+	return appendInt32(b, 4, r.HopSize)
+}
+
+func (r *SpectrogramRequest) Unmarshal(b []byte) error {
+	return parseFields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			r.Samples, err = f.doubles(r.Samples)
+		case 2:
+			r.SampleRate = f.int32()
+		case 3:
+			r.FrameSize = f.int32()
+		case 4:
+			r.HopSize = f.int32()
+		}
+		return err
+	})
+}
+
+func (s *SpectrogramFrame) Marshal() []byte {
+	var b []byte
+	b = appendDouble(b, 1, s.Time)
+	return appendDoubles(b, 2, s.Magnitude)
+}
+
+func (s *SpectrogramFrame) Unmarshal(b []byte) error {
+	return parseFields(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			s.Time = f.double()
+		case 2:
+			s.Magnitude, err = f.doubles(s.Magnitude)
+		}
+		return err
+	})
+}
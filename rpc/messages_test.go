@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// unhex decodes hex bytes with spaces
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// The expected encodings are worked out by hand from the protocol buffer
+// encoding specification and analysis.proto
+func TestMarshal(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		msg  interface{ Marshal() []byte }
+		want string
+	}{
+		{
+			"AnalyzeRequest",
+			&AnalyzeRequest{
+				Samples:    []float64{1, -0.5},
+				SampleRate: 48000,
+				Peaks:      &PeakOptions{MinAboveFloorDB: 20, MaxPeaks: 3},
+			},
+			// samples: tag 1 length delimited, 16 bytes of little-endian doubles
+			"0a 10 000000000000f03f 000000000000e0bf" +
+				// sample_rate: tag 2 varint 48000
+				"10 80f702" +
+				// peaks: tag 3, 11 bytes with min_above_floor_db (tag 4 fixed64) and max_peaks (tag 5 varint)
+				"1a 0b 21 0000000000003440 28 03",
+		},
+		{
+			// A negative int32 is sign extended to 10 varint bytes
+			"Peak",
+			&Peak{FreqHz: 0.5, Bin: -1},
+			"09 000000000000e03f 28 ffffffffffffffffff01",
+		},
+		{
+			// Zero values are omitted
+			"SpectrogramRequest",
+			&SpectrogramRequest{SampleRate: 1, HopSize: 2},
+			"10 01 20 02",
+		},
+	} {
+		if got, want := c.msg.Marshal(), unhex(t, c.want); !bytes.Equal(got, want) {
+			t.Errorf("%s: % x, want % x", c.name, got, want)
+		}
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	// Unpacked repeated doubles as older encoders write them, and unknown
+	// fields of every wire type, which are skipped
+	b := unhex(t, "09 000000000000f03f 09 000000000000e0bf"+
+		"0a 08 0000000000000040"+
+		"10 80f702"+
+		"78 01 8201 03 616263 8d01 01000000 91010000000000000000")
+	var req AnalyzeRequest
+	if err := req.Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Samples) != 3 || req.Samples[0] != 1 || req.Samples[1] != -0.5 || req.Samples[2] != 2 {
+		t.Errorf("samples %v, want [1 -0.5 2]", req.Samples)
+	}
+	if req.SampleRate != 48000 {
+		t.Errorf("sample rate %d, want 48000", req.SampleRate)
+	}
+
+	for _, bad := range []string{"0a 10 0000", "0a 03 000000", "0b", "10"} {
+		if err := new(AnalyzeRequest).Unmarshal(unhex(t, bad)); err == nil {
+			t.Errorf("no error for % s", bad)
+		}
+	}
+}
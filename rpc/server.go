@@ -0,0 +1,400 @@
+// Package rpc provides the analysis of package dft as a gRPC service, so other
+// services can offload their FFT analysis. The API is defined in
+// analysis.proto:
+//
+//	Analyze        spectrum and peaks of a signal
+//	StreamAnalyze  spectra and peaks of a stream of audio chunks, e.g. live audio
+//	Spectrogram    short-time fourier transform of a signal
+//
+// The package implements the gRPC protocol over unencrypted HTTP/2 and the
+// protocol buffer encoding of the messages with the standard library, after
+// the gRPC over HTTP/2 and protocol buffer encoding specifications. Client is
+// a client for Go programs. The tests check the messages against encodings
+// worked out from the specification and call a Server with Client and with
+// raw HTTP/2 requests, but not with a client generated from analysis.proto,
+// so interoperability with generated clients is not verified yet.
+package rpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// servicePath is the path prefix of the methods of the Analysis service
+const servicePath = "/dft.v1.Analysis/"
+
+// gRPC status codes
+const (
+	CodeOK                = 0
+	CodeCanceled          = 1
+	CodeInvalidArgument   = 3
+	CodeDeadlineExceeded  = 4
+	CodeResourceExhausted = 8
+	CodeUnimplemented     = 12
+	CodeInternal          = 13
+)
+
+// Error is an error with a gRPC status code
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error: code %d: %s", e.Code, e.Message)
+}
+
+func errorf(code int, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Options configures a Server
+type Options struct {
+	FrameSize, HopSize int             // default STFT parameters of Spectrogram and StreamAnalyze
+	Peaks              dft.PeakOptions // peak detection of requests without peak options
+	MaxMessageBytes    int             // maximum size of a request message
+}
+
+// DefaultOptions uses frames of 2048 samples and detects peaks 20 dB above the
+// noise floor
+var DefaultOptions = Options{
+	FrameSize: 2048,
+	HopSize:   512,
+	Peaks: dft.PeakOptions{
+		MinAboveFloorDB: 20,
+		MinDistanceHz:   3,
+		MaxPeaks:        20,
+		Order:           dft.ByFrequency,
+	},
+	MaxMessageBytes: 64 << 20,
+}
+
+// Server is the gRPC server of the Analysis service. It is an http.Handler,
+// use ListenAndServe or an http.Server with unencrypted HTTP/2 or TLS.
+type Server struct {
+	opts Options
+}
+
+// New creates a Server
+func New(opts Options) *Server {
+	return &Server{opts: opts}
+}
+
+// ListenAndServe serves gRPC over unencrypted HTTP/2 (h2c) on addr
+func (s *Server) ListenAndServe(addr string) error {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	srv := &http.Server{Addr: addr, Handler: s, Protocols: &protocols}
+	return srv.ListenAndServe()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "gRPC requests only", http.StatusUnsupportedMediaType)
+		return
+	}
+	if r.ProtoMajor != 2 {
+		http.Error(w, "gRPC requires HTTP/2", http.StatusHTTPVersionNotSupported)
+		return
+	}
+
+	ctx := r.Context()
+	if timeout := r.Header.Get("Grpc-Timeout"); timeout != "" {
+		d, err := parseTimeout(timeout)
+		if err != nil {
+			writeStatus(w, errorf(CodeInvalidArgument, "%v", err))
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	w.Header().Set("Content-Type", "application/grpc+proto")
+	w.Header().Set("Grpc-Accept-Encoding", "gzip")
+	w.WriteHeader(http.StatusOK)
+	http.NewResponseController(w).Flush()
+
+	st := &stream{
+		ctx:      ctx,
+		w:        w,
+		r:        r.Body,
+		encoding: r.Header.Get("Grpc-Encoding"),
+		maxSize:  s.opts.MaxMessageBytes,
+	}
+	var err error
+	switch strings.TrimPrefix(r.URL.Path, servicePath) {
+	case "Analyze":
+		err = s.analyze(st)
+	case "StreamAnalyze":
+		err = s.streamAnalyze(st)
+	case "Spectrogram":
+		err = s.spectrogram(st)
+	default:
+		err = errorf(CodeUnimplemented, "unknown method %s", r.URL.Path)
+	}
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	writeStatus(w, err)
+}
+
+// writeStatus sends the status of the call as trailers
+func writeStatus(w http.ResponseWriter, err error) {
+	var e *Error
+	switch {
+	case err == nil:
+		e = &Error{Code: CodeOK}
+	case errors.As(err, &e):
+	case errors.Is(err, context.DeadlineExceeded):
+		e = errorf(CodeDeadlineExceeded, "deadline exceeded")
+	case errors.Is(err, context.Canceled):
+		e = errorf(CodeCanceled, "canceled")
+	default:
+		e = errorf(CodeInternal, "%v", err)
+	}
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(e.Code))
+	if e.Message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", encodeMessage(e.Message))
+	}
+}
+
+// encodeMessage percent-encodes a status message as required for the
+// Grpc-Message trailer
+func encodeMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// parseTimeout parses the value of the Grpc-Timeout header, e.g. "100m"
+func parseTimeout(s string) (time.Duration, error) {
+	units := map[byte]time.Duration{
+		'H': time.Hour, 'M': time.Minute, 'S': time.Second,
+		'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond,
+	}
+	if len(s) < 2 || len(s) > 9 {
+		return 0, fmt.Errorf("invalid timeout %q", s)
+	}
+	unit, ok := units[s[len(s)-1]]
+	v, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if !ok || err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid timeout %q", s)
+	}
+	return time.Duration(v) * unit, nil
+}
+
+// stream reads and writes the length-prefixed messages of a call
+type stream struct {
+	ctx      context.Context
+	w        http.ResponseWriter
+	r        io.Reader
+	encoding string // compression of the request messages
+	maxSize  int
+}
+
+// recv reads the next message into m, it returns io.EOF at the end of the
+// request stream
+func (s *stream) recv(m interface{ Unmarshal([]byte) error }) error {
+	data, err := readMessage(s.r, s.encoding, s.maxSize)
+	if err != nil {
+		return err
+	}
+	if err := m.Unmarshal(data); err != nil {
+		return errorf(CodeInvalidArgument, "invalid message: %v", err)
+	}
+	return nil
+}
+
+// send writes m and flushes it to the client
+func (s *stream) send(m interface{ Marshal() []byte }) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if err := writeMessage(s.w, m.Marshal()); err != nil {
+		return err
+	}
+	return http.NewResponseController(s.w).Flush()
+}
+
+// readMessage reads a length-prefixed message, a compressed message is
+// decompressed with encoding
+func readMessage(r io.Reader, encoding string, maxSize int) ([]byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errorf(CodeInvalidArgument, "truncated message")
+		}
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(hdr[1:])
+	if maxSize > 0 && size > uint32(maxSize) {
+		return nil, errorf(CodeResourceExhausted, "message of %d bytes exceeds the limit of %d bytes", size, maxSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, errorf(CodeInvalidArgument, "truncated message")
+	}
+	if hdr[0] == 0 {
+		return data, nil
+	}
+	if encoding != "gzip" {
+		return nil, errorf(CodeUnimplemented, "unsupported message encoding %q", encoding)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errorf(CodeInvalidArgument, "invalid compressed message: %v", err)
+	}
+	var limit int64 = 1 << 62
+	if maxSize > 0 {
+		limit = int64(maxSize) + 1
+	}
+	data, err = io.ReadAll(io.LimitReader(zr, limit))
+	if err != nil {
+		return nil, errorf(CodeInvalidArgument, "invalid compressed message: %v", err)
+	}
+	if maxSize > 0 && len(data) > maxSize {
+		return nil, errorf(CodeResourceExhausted, "message exceeds the limit of %d bytes", maxSize)
+	}
+	return data, nil
+}
+
+// writeMessage writes an uncompressed length-prefixed message
+func writeMessage(w io.Writer, data []byte) error {
+	var hdr [5]byte
+	binary.BigEndian.PutUint32(hdr[1:], uint32(len(data)))
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// recvOne reads the single request message of a unary or server streaming call
+func recvOne(s *stream, m interface{ Unmarshal([]byte) error }) error {
+	err := s.recv(m)
+	if err == io.EOF {
+		return errorf(CodeInvalidArgument, "missing request message")
+	}
+	return err
+}
+
+func (s *Server) peakOptions(o *PeakOptions) dft.PeakOptions {
+	if o == nil {
+		return s.opts.Peaks
+	}
+	return o.options()
+}
+
+func (s *Server) analyze(st *stream) error {
+	var req AnalyzeRequest
+	if err := recvOne(st, &req); err != nil {
+		return err
+	}
+	if req.SampleRate <= 0 || len(req.Samples) == 0 {
+		return errorf(CodeInvalidArgument, "invalid sample rate %d or empty signal", req.SampleRate)
+	}
+	spectrum := dft.ComputeSpectrum(req.Samples, int(req.SampleRate))
+	return st.send(&AnalyzeResponse{
+		SampleRate: req.SampleRate,
+		FFTSize:    int32(spectrum.FFTSize),
+		FreqRes:    spectrum.FreqRes(),
+		Magnitude:  spectrum.Magnitude,
+		Peaks:      newPeaks(dft.FindPeaks(spectrum, s.peakOptions(req.Peaks))),
+	})
+}
+
+// frameSizes returns the STFT parameters of a request, zero values select the
+// defaults of the server
+func (s *Server) frameSizes(frameSize, hopSize int32) (int, int, error) {
+	f, h := int(frameSize), int(hopSize)
+	if f == 0 {
+		f = s.opts.FrameSize
+	}
+	if h == 0 {
+		h = min(s.opts.HopSize, f)
+	}
+	if f <= 0 || h <= 0 || f > 1<<20 {
+		return 0, 0, errorf(CodeInvalidArgument, "invalid frame size %d or hop size %d", frameSize, hopSize)
+	}
+	return f, h, nil
+}
+
+func (s *Server) spectrogram(st *stream) error {
+	var req SpectrogramRequest
+	if err := recvOne(st, &req); err != nil {
+		return err
+	}
+	frameSize, hopSize, err := s.frameSizes(req.FrameSize, req.HopSize)
+	if err != nil {
+		return err
+	}
+	if req.SampleRate <= 0 {
+		return errorf(CodeInvalidArgument, "invalid sample rate %d", req.SampleRate)
+	}
+	for _, f := range dft.STFT(req.Samples, int(req.SampleRate), frameSize, hopSize) {
+		spectrum := dft.NewSpectrum(f.Spectrum, int(req.SampleRate), frameSize, frameSize)
+		if err := st.send(&SpectrogramFrame{Time: f.Time, Magnitude: spectrum.Magnitude}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) streamAnalyze(st *stream) error {
+	var analyzer *dft.StreamAnalyzer
+	var peaks dft.PeakOptions
+	index := 0
+	for {
+		var chunk AudioChunk
+		if err := st.recv(&chunk); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if analyzer == nil {
+			frameSize, hopSize, err := s.frameSizes(chunk.FrameSize, chunk.HopSize)
+			if err != nil {
+				return err
+			}
+			analyzer, err = dft.NewStreamAnalyzer(int(chunk.SampleRate), frameSize, hopSize, dft.Window{Type: dft.Hanning})
+			if err != nil {
+				return errorf(CodeInvalidArgument, "%v", err)
+			}
+			peaks = s.peakOptions(chunk.Peaks)
+		}
+
+		for _, f := range analyzer.Push(chunk.Samples) {
+			spectrum := dft.NewSpectrum(f.Spectrum, analyzer.SampleRate(), analyzer.FrameSize(), analyzer.FrameSize())
+			err := st.send(&SpectrumBlock{
+				Index:     int32(index),
+				Time:      f.Time,
+				FreqRes:   spectrum.FreqRes(),
+				Magnitude: spectrum.Magnitude,
+				Peaks:     newPeaks(dft.FindPeaks(spectrum, peaks)),
+			})
+			if err != nil {
+				return err
+			}
+			index++
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package rpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestServer starts a Server on unencrypted HTTP/2
+func newTestServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewUnstartedServer(New(DefaultOptions))
+	srv.Config.Protocols = &http.Protocols{}
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func sine(freq float64, n, sampleRate int) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = math.Sin(2 * math.Pi * freq * float64(i) / float64(sampleRate))
+	}
+	return x
+}
+
+func TestClient(t *testing.T) {
+	srv := newTestServer(t)
+	client := NewClient(strings.TrimPrefix(srv.URL, "http://"))
+	ctx := context.Background()
+	signal := sine(1000, 8000, 8000)
+
+	resp, err := client.Analyze(ctx, &AnalyzeRequest{Samples: signal, SampleRate: 8000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Peaks) != 1 || math.Abs(resp.Peaks[0].FreqHz-1000) > 1 {
+		t.Errorf("Analyze: peaks %+v, want one at 1000 Hz", resp.Peaks)
+	}
+
+	frames := 0
+	err = client.Spectrogram(ctx, &SpectrogramRequest{Samples: signal, SampleRate: 8000, FrameSize: 1024, HopSize: 512}, func(f *SpectrogramFrame) error {
+		if len(f.Magnitude) != 513 {
+			t.Errorf("Spectrogram: frame with %d bins, want 513", len(f.Magnitude))
+		}
+		frames++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (len(signal)-1024)/512 + 1; frames != want {
+		t.Errorf("Spectrogram: %d frames, want %d", frames, want)
+	}
+
+	stream, err := client.StreamAnalyze(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for i := 0; i < len(signal); i += 1000 {
+			chunk := &AudioChunk{Samples: signal[i : i+1000]}
+			if i == 0 {
+				chunk.SampleRate, chunk.FrameSize, chunk.HopSize = 8000, 2048, 2048
+			}
+			if err := stream.Send(chunk); err != nil {
+				t.Error(err)
+			}
+		}
+		stream.CloseSend()
+	}()
+	blocks := 0
+	for {
+		block, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int(block.Index) != blocks || len(block.Peaks) == 0 || math.Abs(block.Peaks[0].FreqHz-1000) > 4 {
+			t.Errorf("StreamAnalyze: block %d with peaks %+v", block.Index, block.Peaks)
+		}
+		blocks++
+	}
+	if blocks != len(signal)/2048 {
+		t.Errorf("StreamAnalyze: %d blocks, want %d", blocks, len(signal)/2048)
+	}
+
+	_, err = client.Analyze(ctx, &AnalyzeRequest{Samples: signal})
+	var e *Error
+	if !errors.As(err, &e) || e.Code != CodeInvalidArgument {
+		t.Errorf("Analyze without sample rate: %v, want code %d", err, CodeInvalidArgument)
+	}
+}
+
+// TestRawRequest calls the server like a gRPC client after the gRPC over
+// HTTP/2 specification, with a gzip compressed request message
+func TestRawRequest(t *testing.T) {
+	srv := newTestServer(t)
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: &protocols}}
+
+	call := func(method string, msg []byte) (*http.Response, []byte) {
+		var zipped bytes.Buffer
+		zw := gzip.NewWriter(&zipped)
+		zw.Write(msg)
+		zw.Close()
+		body := []byte{1, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(body[1:], uint32(zipped.Len()))
+		body = append(body, zipped.Bytes()...)
+
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/dft.v1.Analysis/"+method, bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/grpc")
+		req.Header.Set("Te", "trailers")
+		req.Header.Set("Grpc-Encoding", "gzip")
+		req.Header.Set("Grpc-Timeout", "10S")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body) // the trailers follow the body
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.ProtoMajor != 2 || resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: %s %s, want HTTP/2 200", method, resp.Proto, resp.Status)
+		}
+		return resp, data
+	}
+
+	req := &AnalyzeRequest{Samples: sine(440, 4096, 44100), SampleRate: 44100}
+	resp, data := call("Analyze", req.Marshal())
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/grpc") {
+		t.Errorf("content type %q", ct)
+	}
+	if status := resp.Trailer.Get("Grpc-Status"); status != "0" {
+		t.Fatalf("status %q, message %q", status, resp.Trailer.Get("Grpc-Message"))
+	}
+	if len(data) < 5 || data[0] != 0 || int(binary.BigEndian.Uint32(data[1:])) != len(data)-5 {
+		t.Fatalf("response is not a single uncompressed message: % x", data[:min(len(data), 5)])
+	}
+	var out AnalyzeResponse
+	if err := out.Unmarshal(data[5:]); err != nil {
+		t.Fatal(err)
+	}
+	if out.SampleRate != 44100 || out.FFTSize != 4096 || len(out.Magnitude) != 2049 {
+		t.Errorf("response with sample rate %d, FFT size %d and %d bins", out.SampleRate, out.FFTSize, len(out.Magnitude))
+	}
+
+	resp, _ = call("Analyze", (&AnalyzeRequest{SampleRate: 44100}).Marshal())
+	if status := resp.Trailer.Get("Grpc-Status"); status != "3" {
+		t.Errorf("empty signal: status %q, want 3 (INVALID_ARGUMENT)", status)
+	}
+	resp, _ = call("Transcribe", nil)
+	if status := resp.Trailer.Get("Grpc-Status"); status != "12" {
+		t.Errorf("unknown method: status %q, want 12 (UNIMPLEMENTED)", status)
+	}
+}
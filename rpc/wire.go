@@ -0,0 +1,130 @@
+package rpc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Protocol buffer wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+func appendTag(b []byte, field, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wireType))
+}
+
+func appendInt32(b []byte, field int, v int32) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return binary.AppendUvarint(b, uint64(int64(v))) // negative values take 10 bytes
+}
+
+func appendDouble(b []byte, field int, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireFixed64)
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+}
+
+// appendDoubles appends a packed repeated double field
+func appendDoubles(b []byte, field int, v []float64) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(8*len(v)))
+	for _, x := range v {
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(x))
+	}
+	return b
+}
+
+func appendMessage(b []byte, field int, msg []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(msg)))
+	return append(b, msg...)
+}
+
+// field is a decoded field of a message
+type field struct {
+	num      int
+	wireType int
+	varint   uint64 // value of varint and fixed fields
+	bytes    []byte // value of length delimited fields
+}
+
+func (f field) int32() int32 {
+	return int32(f.varint)
+}
+
+func (f field) double() float64 {
+	return math.Float64frombits(f.varint)
+}
+
+// doubles appends the values of a repeated double field, which is either
+// packed or a single value
+func (f field) doubles(dst []float64) ([]float64, error) {
+	if f.wireType == wireFixed64 {
+		return append(dst, f.double()), nil
+	}
+	if f.wireType != wireBytes || len(f.bytes)%8 != 0 {
+		return dst, fmt.Errorf("invalid repeated double field %d", f.num)
+	}
+	for i := 0; i < len(f.bytes); i += 8 {
+		dst = append(dst, math.Float64frombits(binary.LittleEndian.Uint64(f.bytes[i:])))
+	}
+	return dst, nil
+}
+
+// parseFields calls fn for every field of the encoded message b
+func parseFields(b []byte, fn func(f field) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return fmt.Errorf("invalid field tag")
+		}
+		b = b[n:]
+		f := field{num: int(tag >> 3), wireType: int(tag & 7)}
+		switch f.wireType {
+		case wireVarint:
+			f.varint, n = binary.Uvarint(b)
+			if n <= 0 {
+				return fmt.Errorf("invalid varint in field %d", f.num)
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return fmt.Errorf("truncated field %d", f.num)
+			}
+			f.varint = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return fmt.Errorf("truncated field %d", f.num)
+			}
+			f.varint = uint64(binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < length {
+				return fmt.Errorf("truncated field %d", f.num)
+			}
+			f.bytes = b[n : n+int(length)]
+			b = b[n+int(length):]
+		default:
+			return fmt.Errorf("unsupported wire type %d in field %d", f.wireType, f.num)
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
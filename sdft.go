@@ -0,0 +1,120 @@
+package dft
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// SlidingDFT tracks a few bins of the DFT of the latest n samples and updates
+// them with every incoming sample in O(1) per bin, instead of computing a full
+// FFT per frame. It suits low-latency monitoring of a handful of frequencies.
+//
+// The bins are updated with the recursion X[k] = (X[k] + x[new] - x[old]) *
+// e^(j2πk/n). To keep rounding errors from accumulating, they are recomputed
+// directly every n samples, which costs another O(1) per bin and sample.
+type SlidingDFT struct {
+	n       int
+	bins    []int        // requested bins
+	tracked []int        // requested bins and their neighbours, for the Hann window
+	index   map[int]int  // bin -> position in tracked
+	twiddle []complex128 // e^(j2πk/n) of the tracked bins
+	values  []complex128
+	ring    []float64
+	pos     int
+	count   int // samples since the last recomputation
+}
+
+// NewSlidingDFT returns a sliding DFT over n samples for the given bins
+// (0 <= bin <= n/2). All bins start at zero, as if n zeros had been pushed.
+func NewSlidingDFT(n int, bins []int) (*SlidingDFT, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid length %d", n)
+	}
+	s := &SlidingDFT{n: n, bins: append([]int(nil), bins...), index: map[int]int{}, ring: make([]float64, n)}
+	for _, k := range bins {
+		if k < 0 || k > n/2 {
+			return nil, fmt.Errorf("bin %d is out of range (0..%d)", k, n/2)
+		}
+		for _, b := range []int{k - 1, k, k + 1} {
+			if _, ok := s.index[b]; !ok {
+				s.index[b] = len(s.tracked)
+				s.tracked = append(s.tracked, b)
+				s.twiddle = append(s.twiddle, cmplx.Rect(1, 2*math.Pi*float64(b)/float64(n)))
+			}
+		}
+	}
+	s.values = make([]complex128, len(s.tracked))
+	return s, nil
+}
+
+// Update pushes a single sample
+func (s *SlidingDFT) Update(x float64) {
+	old := s.ring[s.pos]
+	s.ring[s.pos] = x
+	s.pos = (s.pos + 1) % s.n
+
+	s.count++
+	if s.count == s.n {
+		s.recompute()
+		return
+	}
+	delta := complex(x-old, 0)
+	for i := range s.values {
+		s.values[i] = (s.values[i] + delta) * s.twiddle[i]
+	}
+}
+
+// Push pushes samples one by one
+func (s *SlidingDFT) Push(samples []float64) {
+	for _, x := range samples {
+		s.Update(x)
+	}
+}
+
+// recompute computes the tracked bins directly from the ring buffer
+func (s *SlidingDFT) recompute() {
+	s.count = 0
+	for i, k := range s.tracked {
+		var sum complex128
+		for j := 0; j < s.n; j++ {
+			x := s.ring[(s.pos+j)%s.n] // oldest sample first
+			sum += complex(x, 0) * cmplx.Rect(1, -2*math.Pi*float64(k*j%s.n)/float64(s.n))
+		}
+		s.values[i] = sum
+	}
+}
+
+// Coefficients returns the DFT coefficients of the requested bins for the
+// latest n samples without a window, in the order of the bins passed to
+// NewSlidingDFT. They equal those of Forward for the same n samples.
+func (s *SlidingDFT) Coefficients() []complex128 {
+	out := make([]complex128, len(s.bins))
+	for i, k := range s.bins {
+		out[i] = s.values[s.index[k]]
+	}
+	return out
+}
+
+// HannCoefficients returns the coefficients of the requested bins with a
+// periodic Hann window applied in the frequency domain:
+// 0.5*X[k] - 0.25*(X[k-1] + X[k+1]).
+func (s *SlidingDFT) HannCoefficients() []complex128 {
+	out := make([]complex128, len(s.bins))
+	for i, k := range s.bins {
+		out[i] = 0.5*s.values[s.index[k]] - 0.25*(s.values[s.index[k-1]]+s.values[s.index[k+1]])
+	}
+	return out
+}
+
+// Magnitudes returns the amplitudes of the requested bins, scaled like
+// Spectrum.Magnitude so a sine of amplitude A centered on a bin shows up with
+// a magnitude of A
+func (s *SlidingDFT) Magnitudes() []float64 {
+	coeffs := s.HannCoefficients()
+	mag := make([]float64, len(coeffs))
+	for i, c := range coeffs {
+		mag[i] = cmplx.Abs(c) * 2 / float64(s.n) / hanningGain
+	}
+	return mag
+}
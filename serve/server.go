@@ -0,0 +1,215 @@
+// Package serve provides an HTTP server with an interactive web page that shows
+// the spectrum and spectrogram of a signal, e.g. to share an analysis with
+// colleagues that don't use Go.
+//
+// The page is embedded into the binary and talks to a small JSON API:
+//
+//	GET  /api/analysis  spectrum, peaks and spectrogram of the current signal
+//	POST /api/analyze   analyzes the uploaded audio file (the request body)
+package serve
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/audio"
+	"github.com/epikur-io/go-discrete-fourier-transform/export"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// Options configures the analysis of a Server
+type Options struct {
+	FrameSize, HopSize int // STFT parameters of the spectrogram
+	MaxFrames          int // the spectrogram is reduced to at most MaxFrames frames
+	MinDB, MaxDB       float64
+	Peaks              dft.PeakOptions
+	MaxUploadBytes     int64
+}
+
+// DefaultOptions shows peaks 20 dB above the noise floor and a spectrogram of
+// 2048 sample frames from -120 to 0 dB
+var DefaultOptions = Options{
+	FrameSize: 2048,
+	HopSize:   512,
+	MaxFrames: 1500,
+	MinDB:     -120,
+	MaxDB:     0,
+	Peaks: dft.PeakOptions{
+		MinAboveFloorDB: 20,
+		MinDistanceHz:   3,
+		MaxPeaks:        20,
+		Order:           dft.ByFrequency,
+	},
+	MaxUploadBytes: 256 << 20,
+}
+
+// Analysis is the JSON document served by /api/analysis
+type Analysis struct {
+	Name        string        `json:"name"`
+	SampleRate  int           `json:"sample_rate"`
+	Duration    float64       `json:"duration_s"`
+	Spectrum    SpectrumData  `json:"spectrum"`
+	Peaks       []export.Peak `json:"peaks"`
+	Spectrogram Spectrogram   `json:"spectrogram"`
+}
+
+// SpectrumData is the magnitude spectrum in dB
+type SpectrumData struct {
+	FreqRes float64   `json:"freq_res_hz"`
+	DB      []float32 `json:"db"`
+}
+
+// Spectrogram holds the levels of the STFT frames quantized to bytes between
+// MinDB (0) and MaxDB (255), frame after frame
+type Spectrogram struct {
+	Frames    int     `json:"frames"`
+	Bins      int     `json:"bins"`
+	TimeStart float64 `json:"time_start_s"` // center of the first frame
+	TimeStep  float64 `json:"time_step_s"`
+	FreqRes   float64 `json:"freq_res_hz"`
+	MinDB     float64 `json:"min_db"`
+	MaxDB     float64 `json:"max_db"`
+	Levels    []byte  `json:"levels"` // encoded as base64 in JSON
+}
+
+// Server serves the web page and the analysis of the current signal
+type Server struct {
+	opts Options
+	mux  *http.ServeMux
+
+	mu       sync.RWMutex
+	analysis []byte // JSON encoded Analysis, nil if nothing was loaded yet
+}
+
+// New creates a Server, use Load to provide the first signal
+func New(opts Options) *Server {
+	s := &Server{opts: opts, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /{$}", s.handleIndex)
+	s.mux.HandleFunc("GET /api/analysis", s.handleAnalysis)
+	s.mux.HandleFunc("POST /api/analyze", s.handleAnalyze)
+	return s
+}
+
+// Handle registers an additional handler, e.g. for streaming endpoints
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Load analyzes samples and serves the result
+func (s *Server) Load(name string, samples []float64, sampleRate int) error {
+	a, err := Analyze(name, samples, sampleRate, s.opts)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.analysis = data
+	s.mu.Unlock()
+	return nil
+}
+
+// Analyze computes the spectrum, peaks and spectrogram of samples
+func Analyze(name string, samples []float64, sampleRate int, opts Options) (Analysis, error) {
+	if len(samples) < opts.FrameSize {
+		return Analysis{}, fmt.Errorf("signal of %d samples is shorter than a frame of %d samples", len(samples), opts.FrameSize)
+	}
+	spectrum := dft.ComputeSpectrum(samples, sampleRate)
+	db := spectrum.DB(dft.DBOptions{})
+	a := Analysis{
+		Name:       name,
+		SampleRate: sampleRate,
+		Duration:   float64(len(samples)) / float64(sampleRate),
+		Spectrum:   SpectrumData{FreqRes: spectrum.FreqRes(), DB: make([]float32, len(db))},
+		Peaks:      export.NewReport(spectrum, dft.FindPeaks(spectrum, opts.Peaks)).Peaks,
+	}
+	for i, v := range db {
+		a.Spectrum.DB[i] = float32(v)
+	}
+	for i, p := range a.Peaks {
+		if note, err := dft.NoteFromFrequency(p.FreqHz, dft.DefaultReferencePitch); err == nil {
+			a.Peaks[i].Note = note.String()
+		}
+	}
+
+	frames := dft.STFT(samples, sampleRate, opts.FrameSize, opts.HopSize)
+	// merge neighboring frames, keeping the highest level, to limit the size
+	step := 1
+	if opts.MaxFrames > 0 {
+		step = (len(frames) + opts.MaxFrames - 1) / opts.MaxFrames
+	}
+	bins := opts.FrameSize/2 + 1
+	sg := Spectrogram{
+		Frames:    (len(frames) + step - 1) / step,
+		Bins:      bins,
+		TimeStart: frames[0].Time,
+		TimeStep:  float64(step*opts.HopSize) / float64(sampleRate),
+		FreqRes:   float64(sampleRate) / float64(opts.FrameSize),
+		MinDB:     opts.MinDB,
+		MaxDB:     opts.MaxDB,
+	}
+	sg.Levels = make([]byte, sg.Frames*bins)
+	for i, f := range frames {
+		row := sg.Levels[(i/step)*bins : (i/step+1)*bins]
+		mag := dft.NewSpectrum(f.Spectrum, sampleRate, opts.FrameSize, opts.FrameSize).DB(dft.DBOptions{})
+		for j, v := range mag {
+			q := byte(math.Round(math.Max(0, math.Min(1, (v-opts.MinDB)/(opts.MaxDB-opts.MinDB))) * 255))
+			row[j] = max(row[j], q)
+		}
+	}
+	a.Spectrogram = sg
+	return a, nil
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexHTML)
+}
+
+func (s *Server) handleAnalysis(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	data := s.analysis
+	s.mu.RUnlock()
+	if data == nil {
+		http.Error(w, "no signal loaded, upload a file", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// handleAnalyze analyzes the audio file in the request body. The name query
+// parameter is shown on the page and its extension is used as format hint.
+func (s *Server) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.opts.MaxUploadBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	samples, sampleRate, _, err := audio.LoadAudio(bytes.NewReader(data), audio.FormatFromExtension(name))
+	if err == nil {
+		err = s.Load(name, samples, sampleRate)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	s.handleAnalysis(w, r)
+}
@@ -0,0 +1,156 @@
+package serve
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+	"github.com/epikur-io/go-discrete-fourier-transform/export"
+)
+
+// clientBuffer is the number of messages queued for a client, further messages
+// are dropped until the client catches up
+const clientBuffer = 8
+
+// Stream publishes spectra to WebSocket clients, e.g. the blocks of a live
+// capture. Clients connect to its ServeHTTP handler and receive every spectrum
+// as a JSON text message (the default) or, with the query parameter
+// format=binary, as a binary message with the little-endian layout
+//
+//	uint32   block index
+//	float64  time in seconds
+//	float32  bin width in Hz
+//	uint32   number of bins n
+//	float32  n levels in dB
+//	uint32   number of peaks m
+//	m times  float32 frequency in Hz, float32 level in dB
+type Stream struct {
+	mu      sync.Mutex
+	clients map[*streamClient]struct{}
+}
+
+type streamClient struct {
+	binary bool
+	queue  chan []byte
+}
+
+// Message is the JSON message of a spectrum
+type Message struct {
+	Index      int           `json:"index"`
+	Time       float64       `json:"time_s"`
+	SampleRate int           `json:"sample_rate"`
+	FreqRes    float64       `json:"freq_res_hz"`
+	DB         []float32     `json:"db"`
+	Peaks      []export.Peak `json:"peaks"`
+}
+
+// NewStream creates a Stream without clients
+func NewStream() *Stream {
+	return &Stream{clients: map[*streamClient]struct{}{}}
+}
+
+// Clients returns the number of connected clients
+func (s *Stream) Clients() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.clients)
+}
+
+// Publish sends the spectrum of block index at time seconds and its peaks to
+// all clients. It does not block, clients that are too slow miss messages.
+func (s *Stream) Publish(index int, time float64, spectrum dft.Spectrum, peaks []dft.Peak) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.clients) == 0 {
+		return
+	}
+
+	db := spectrum.DB(dft.DBOptions{})
+	var text, bin []byte
+	for c := range s.clients {
+		msg := text
+		if c.binary {
+			if bin == nil {
+				bin = encodeBinary(index, time, spectrum.FreqRes(), db, peaks)
+			}
+			msg = bin
+		} else if text == nil {
+			m := Message{
+				Index:      index,
+				Time:       time,
+				SampleRate: spectrum.SampleRate,
+				FreqRes:    spectrum.FreqRes(),
+				DB:         make([]float32, len(db)),
+				Peaks:      export.NewReport(spectrum, peaks).Peaks,
+			}
+			for i, v := range db {
+				m.DB[i] = float32(v)
+			}
+			var err error
+			if text, err = json.Marshal(m); err != nil {
+				log.Println("failed to encode spectrum:", err)
+				return
+			}
+			msg = text
+		}
+
+		select {
+		case c.queue <- msg:
+		default:
+		}
+	}
+}
+
+func encodeBinary(index int, time, freqRes float64, db []float64, peaks []dft.Peak) []byte {
+	b := make([]byte, 0, 24+4*len(db)+8*len(peaks))
+	b = binary.LittleEndian.AppendUint32(b, uint32(index))
+	b = binary.LittleEndian.AppendUint64(b, math.Float64bits(time))
+	b = binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(freqRes)))
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(db)))
+	for _, v := range db {
+		b = binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(v)))
+	}
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(peaks)))
+	for _, p := range peaks {
+		b = binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(p.FreqHz)))
+		b = binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(p.MagnitudeDB)))
+	}
+	return b
+}
+
+// ServeHTTP upgrades the request to a WebSocket connection and sends the
+// published spectra until the client disconnects
+func (s *Stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	c := &streamClient{binary: r.URL.Query().Get("format") == "binary", queue: make(chan []byte, clientBuffer)}
+	s.mu.Lock()
+	s.clients[c] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case msg := <-c.queue:
+			if err := conn.Write(c.binary, msg); err != nil {
+				return
+			}
+		case <-conn.Done():
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
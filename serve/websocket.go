@@ -0,0 +1,198 @@
+package serve
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// WebSocket opcodes of RFC 6455
+const (
+	opText   = 0x1
+	opBinary = 0x2
+	opClose  = 0x8
+	opPing   = 0x9
+	opPong   = 0xa
+)
+
+// websocketGUID is appended to the client key to compute the accept key
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxClientFrame limits the size of frames read from clients, which only send
+// control frames
+const maxClientFrame = 1 << 16
+
+// wsConn is the server side of a WebSocket connection. It supports sending
+// messages and answers pings and close frames of the client.
+type wsConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+
+	mu     sync.Mutex // serializes writes
+	closed chan struct{}
+	once   sync.Once
+}
+
+// upgrade performs the WebSocket handshake for r
+func upgrade(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "expected a WebSocket upgrade", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("not a WebSocket request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusBadRequest)
+		return nil, fmt.Errorf("unsupported WebSocket version %q", r.Header.Get("Sec-WebSocket-Version"))
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, fmt.Errorf("missing Sec-WebSocket-Key")
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(sum[:]))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c := &wsConn{conn: conn, rw: rw, closed: make(chan struct{})}
+	go c.readLoop()
+	return c, nil
+}
+
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Write sends a text or binary message
+func (c *wsConn) Write(isBinary bool, payload []byte) error {
+	op := byte(opText)
+	if isBinary {
+		op = opBinary
+	}
+	return c.writeFrame(op, payload)
+}
+
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	header := []byte{0x80 | op} // FIN
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xffff:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// Done is closed when the connection is closed
+func (c *wsConn) Done() <-chan struct{} {
+	return c.closed
+}
+
+// Close closes the connection
+func (c *wsConn) Close() error {
+	var err error
+	c.once.Do(func() {
+		close(c.closed)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// readLoop reads the frames of the client until the connection is closed.
+// Data frames are discarded.
+func (c *wsConn) readLoop() {
+	defer c.Close()
+	for {
+		op, payload, err := c.readFrame()
+		if err != nil {
+			return
+		}
+		switch op {
+		case opClose:
+			c.writeFrame(opClose, payload[:min(len(payload), 2)])
+			return
+		case opPing:
+			if c.writeFrame(opPong, payload) != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (op byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.rw, head[:]); err != nil {
+		return 0, nil, err
+	}
+	op = head[0] & 0x0f
+	masked := head[1]&0x80 != 0
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxClientFrame {
+		return 0, nil, fmt.Errorf("client frame of %d bytes is too large", n)
+	}
+	if !masked {
+		return 0, nil, fmt.Errorf("unmasked client frame")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return op, payload, nil
+}
@@ -0,0 +1,66 @@
+package siggen
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// Multitone returns the sum of the tones
+func Multitone(tones []Tone, sampleRate int, duration float64) []float64 {
+	out := make([]float64, length(sampleRate, duration))
+	for _, t := range tones {
+		omega := 2 * math.Pi * t.FreqHz / float64(sampleRate)
+		for i := range out {
+			out[i] += t.Amplitude * math.Sin(omega*float64(i)+t.Phase)
+		}
+	}
+	return out
+}
+
+// SchroederPhases returns a copy of tones with the phases of Schroeder's
+// formula, which keeps the crest factor of a multitone with many tones of
+// similar amplitude low. Tones with phases 0 instead add up to a peak of the
+// sum of all amplitudes at the start.
+func SchroederPhases(tones []Tone) []Tone {
+	out := append([]Tone(nil), tones...)
+	total := 0.0
+	for _, t := range tones {
+		total += t.Amplitude * t.Amplitude
+	}
+	if total == 0 {
+		return out
+	}
+	// φ_k = φ_1 - 2π·Σ_{l<k} (k-l)·p_l with the relative power p_l of tone l
+	for k := range out {
+		phase := 0.0
+		for l := 0; l < k; l++ {
+			phase -= 2 * math.Pi * float64(k-l) * tones[l].Amplitude * tones[l].Amplitude / total
+		}
+		out[k].Phase = math.Mod(phase, 2*math.Pi)
+	}
+	return out
+}
+
+// RandomPhases returns a copy of tones with uniformly random phases from a
+// generator seeded with seed
+func RandomPhases(tones []Tone, seed uint64) []Tone {
+	rng := rand.New(rand.NewPCG(seed, 0))
+	out := append([]Tone(nil), tones...)
+	for i := range out {
+		out[i].Phase = 2 * math.Pi * rng.Float64()
+	}
+	return out
+}
+
+// CrestFactor returns the ratio of the peak to the RMS level of x
+func CrestFactor(x []float64) float64 {
+	peak, sum := 0.0, 0.0
+	for _, v := range x {
+		peak = math.Max(peak, math.Abs(v))
+		sum += v * v
+	}
+	if sum == 0 {
+		return 0
+	}
+	return peak / math.Sqrt(sum/float64(len(x)))
+}
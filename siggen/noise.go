@@ -0,0 +1,84 @@
+package siggen
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// NoiseColor selects the spectral slope of noise
+type NoiseColor int
+
+const (
+	White NoiseColor = iota // flat power spectral density
+	Pink                    // -3 dB per octave, equal power per octave
+	Brown                   // -6 dB per octave, integrated white noise
+)
+
+var noiseNames = map[NoiseColor]string{
+	White: "white",
+	Pink:  "pink",
+	Brown: "brown",
+}
+
+func (c NoiseColor) String() string {
+	if name, ok := noiseNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("NoiseColor(%d)", int(c))
+}
+
+// ParseNoiseColor returns the NoiseColor named by String
+func ParseNoiseColor(s string) (NoiseColor, error) {
+	for c, name := range noiseNames {
+		if name == s {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown noise color %q", s)
+}
+
+// Noise returns Gaussian noise of the given color, scaled to an RMS level of
+// rms, from a generator seeded with seed. Pink and brown noise are shaped in
+// the frequency domain, so their slope is exact from the lowest bin to the
+// Nyquist frequency and the signal loops without a discontinuity. They have
+// no DC component.
+func Noise(color NoiseColor, rms float64, sampleRate int, duration float64, seed uint64) []float64 {
+	n := length(sampleRate, duration)
+	rng := rand.New(rand.NewPCG(seed, 0))
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = rng.NormFloat64()
+	}
+	if n < 2 {
+		return out
+	}
+
+	if color != White {
+		// The power falls with 1/f^exponent, the amplitudes with the root
+		exponent := 1.0
+		if color == Brown {
+			exponent = 2
+		}
+		coeffs := dft.Forward(out)
+		coeffs[0] = 0
+		for k := 1; k < len(coeffs); k++ {
+			coeffs[k] *= complex(math.Pow(float64(k), -exponent/2), 0)
+		}
+		out = dft.Inverse(coeffs, n)
+	}
+
+	sum := 0.0
+	for _, v := range out {
+		sum += v * v
+	}
+	if sum > 0 {
+		scale := rms / math.Sqrt(sum/float64(n))
+		for i := range out {
+			out[i] *= scale
+		}
+	}
+	return out
+}
@@ -0,0 +1,151 @@
+// Package siggen generates test signals such as sines, band-limited square,
+// sawtooth and triangle waves, impulses, colored noise and multitones, e.g.
+// for tests and calibration. Lengths are given as duration in seconds and
+// rounded down to whole samples.
+package siggen
+
+import (
+	"fmt"
+	"math"
+)
+
+// Tone is a sinusoidal component A·sin(2π·f·t + φ)
+type Tone struct {
+	FreqHz    float64
+	Amplitude float64
+	Phase     float64 // start phase in radians
+}
+
+// Waveform selects the shape of a periodic signal
+type Waveform int
+
+const (
+	Sine Waveform = iota
+	Square
+	Sawtooth
+	Triangle
+)
+
+var waveformNames = map[Waveform]string{
+	Sine:     "sine",
+	Square:   "square",
+	Sawtooth: "sawtooth",
+	Triangle: "triangle",
+}
+
+func (w Waveform) String() string {
+	if name, ok := waveformNames[w]; ok {
+		return name
+	}
+	return fmt.Sprintf("Waveform(%d)", int(w))
+}
+
+// ParseWaveform returns the Waveform named by String
+func ParseWaveform(s string) (Waveform, error) {
+	for w, name := range waveformNames {
+		if name == s {
+			return w, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown waveform %q", s)
+}
+
+// length returns the number of samples of duration seconds
+func length(sampleRate int, duration float64) int {
+	return max(0, int(float64(sampleRate)*duration))
+}
+
+// Composite returns the sum of sine waves with the given frequencies in Hz and
+// amplitudes, all starting at phase 0
+func Composite(freqs, amplitudes []float64, sampleRate int, duration float64) []float64 {
+	tones := make([]Tone, len(freqs))
+	for i, f := range freqs {
+		tones[i] = Tone{FreqHz: f, Amplitude: amplitudes[i]}
+	}
+	return Multitone(tones, sampleRate, duration)
+}
+
+// Periodic returns a waveform with the frequency, amplitude and phase of t.
+// Square, sawtooth and triangle waves are synthesized from their harmonics
+// below the Nyquist frequency, so unlike naively sampled waves they do not
+// alias. Their edges therefore ring, the square wave overshoots its amplitude
+// by about 9% (Gibbs phenomenon). The phase is that of the fundamental.
+// Frequencies outside (0, Nyquist) give silence.
+func Periodic(w Waveform, t Tone, sampleRate int, duration float64) []float64 {
+	n := length(sampleRate, duration)
+	out := make([]float64, n)
+	nyquist := float64(sampleRate) / 2
+	if t.FreqHz <= 0 || t.FreqHz >= nyquist {
+		return out
+	}
+	harmonics := int(math.Ceil(nyquist/t.FreqHz)) - 1
+	if w == Sine {
+		harmonics = 1
+	}
+
+	// gain returns the amplitude of the k-th harmonic relative to the
+	// fundamental's Fourier coefficient
+	var scale float64
+	var gain func(k int) float64
+	switch w {
+	case Sine:
+		scale, gain = 1, func(int) float64 { return 1 }
+	case Square:
+		scale = 4 / math.Pi
+		gain = func(k int) float64 {
+			if k%2 == 0 {
+				return 0
+			}
+			return 1 / float64(k)
+		}
+	case Sawtooth:
+		// Rising ramp, sin(kx) terms with alternating signs
+		scale = 2 / math.Pi
+		gain = func(k int) float64 {
+			if k%2 == 0 {
+				return -1 / float64(k)
+			}
+			return 1 / float64(k)
+		}
+	case Triangle:
+		scale = 8 / (math.Pi * math.Pi)
+		gain = func(k int) float64 {
+			switch k % 4 {
+			case 1:
+				return 1 / float64(k*k)
+			case 3:
+				return -1 / float64(k*k)
+			}
+			return 0
+		}
+	}
+
+	gains := make([]float64, harmonics+1)
+	for k := 1; k <= harmonics; k++ {
+		gains[k] = gain(k)
+	}
+	omega := 2 * math.Pi * t.FreqHz / float64(sampleRate)
+	for i := range out {
+		x := omega*float64(i) + t.Phase
+		// sin(kx) by the recurrence sin((k+1)x) = 2cos(x)sin(kx) - sin((k-1)x)
+		c := 2 * math.Cos(x)
+		prev, cur := 0.0, math.Sin(x)
+		sum := 0.0
+		for k := 1; k <= harmonics; k++ {
+			sum += gains[k] * cur
+			prev, cur = cur, c*cur-prev
+		}
+		out[i] = t.Amplitude * scale * sum
+	}
+	return out
+}
+
+// Impulse returns a signal of zeros with a single sample of amplitude at the
+// time at in seconds
+func Impulse(amplitude float64, at float64, sampleRate int, duration float64) []float64 {
+	out := make([]float64, length(sampleRate, duration))
+	if i := int(math.Round(at * float64(sampleRate))); i >= 0 && i < len(out) {
+		out[i] = amplitude
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package siggen
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Sweep is an exponential (logarithmic) sine sweep as used by Farina's method
+// of measuring impulse responses. The frequency rises from StartHz to EndHz
+// exponentially, so every octave takes the same time.
+type Sweep struct {
+	StartHz    float64
+	EndHz      float64
+	Duration   float64 // seconds
+	Amplitude  float64
+	SampleRate int
+	Fade       float64 // seconds of the half Hann fade-in and fade-out, avoids clicks
+}
+
+// DefaultSweep covers the audio band in 5 seconds
+var DefaultSweep = Sweep{StartHz: 20, EndHz: 20000, Duration: 5, Amplitude: 0.5, SampleRate: 48000, Fade: 0.01}
+
+// validate checks the parameters of s
+func (s Sweep) validate() error {
+	if s.SampleRate <= 0 || s.Duration <= 0 {
+		return fmt.Errorf("invalid sample rate %d or duration %g of the sweep", s.SampleRate, s.Duration)
+	}
+	if s.StartHz <= 0 || s.EndHz <= s.StartHz || s.EndHz > float64(s.SampleRate)/2 {
+		return fmt.Errorf("invalid sweep range %g Hz to %g Hz, it must rise and end below the Nyquist frequency", s.StartHz, s.EndHz)
+	}
+	if s.Fade < 0 || 2*s.Fade > s.Duration {
+		return fmt.Errorf("invalid fade of %g s for a sweep of %g s", s.Fade, s.Duration)
+	}
+	return nil
+}
+
+// rate returns the time in seconds in which the frequency rises by a factor e
+func (s Sweep) rate() float64 {
+	return s.Duration / math.Log(s.EndHz/s.StartHz)
+}
+
+// HarmonicDelay returns how many seconds the impulse response of the n-th
+// harmonic distortion product precedes the linear response after
+// deconvolution
+func (s Sweep) HarmonicDelay(n int) float64 {
+	return s.rate() * math.Log(float64(n))
+}
+
+// Generate returns the samples of the sweep
+func (s Sweep) Generate() ([]float64, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	l := s.rate()
+	out := make([]float64, length(s.SampleRate, s.Duration))
+	for i := range out {
+		t := float64(i) / float64(s.SampleRate)
+		out[i] = s.Amplitude * math.Sin(2*math.Pi*s.StartHz*l*(math.Exp(t/l)-1))
+	}
+
+	fade := min(int(s.Fade*float64(s.SampleRate)), len(out)/2)
+	for i := 0; i < fade; i++ {
+		g := 0.5 - 0.5*math.Cos(math.Pi*float64(i)/float64(fade))
+		out[i] *= g
+		out[len(out)-1-i] *= g
+	}
+	return out, nil
+}
+
+// InverseFilter returns the filter that turns the sweep into an impulse by
+// convolution: the time reversed sweep with an amplitude falling by 6 dB per
+// octave, which compensates the energy that the sweep spends per octave. It
+// is normalized to a gain of 1 in the middle of the sweep range, so a system
+// with a gain of 1 yields an impulse of height 1.
+func (s Sweep) InverseFilter() ([]float64, error) {
+	sweep, err := s.Generate()
+	if err != nil {
+		return nil, err
+	}
+	l := s.rate()
+	n := len(sweep)
+	inv := make([]float64, n)
+	for i := range inv {
+		t := float64(i) / float64(s.SampleRate)
+		inv[i] = sweep[n-1-i] * math.Exp(-t/l)
+	}
+
+	// Measure the gain of sweep*inv at the geometric center of the range
+	size := dft.NextPowerOfTwo(2 * n)
+	a := make([]float64, size)
+	b := make([]float64, size)
+	copy(a, sweep)
+	copy(b, inv)
+	bin := int(math.Round(math.Sqrt(s.StartHz*s.EndHz) * float64(size) / float64(s.SampleRate)))
+	gain := cmplx.Abs(dft.Forward(a)[bin] * dft.Forward(b)[bin])
+	for i := range inv {
+		inv[i] /= gain
+	}
+	return inv, nil
+}
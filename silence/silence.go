@@ -0,0 +1,126 @@
+// Package silence finds the silent parts of recordings, to trim the leading
+// and trailing silence or to split a recording at its pauses, so analyses
+// cover only the programme material.
+package silence
+
+import (
+	"fmt"
+	"math"
+)
+
+// Options configures Detect
+type Options struct {
+	// Frames with an RMS level below ThresholdDB (dBFS, a full scale sine
+	// is -3 dBFS) in all channels are silent
+	ThresholdDB   float64
+	MinDuration   float64 // shorter pauses inside the material are not silence, in seconds
+	FrameDuration float64 // length of the level frames in seconds
+}
+
+// DefaultOptions detect pauses of half a second below -60 dBFS
+var DefaultOptions = Options{
+	ThresholdDB:   -60,
+	MinDuration:   0.5,
+	FrameDuration: 0.01,
+}
+
+// Region is a part of a recording
+type Region struct {
+	Start  float64 `json:"start_s"`
+	End    float64 `json:"end_s"`
+	Silent bool    `json:"silent"`
+}
+
+// Duration returns the length of the region in seconds
+func (r Region) Duration() float64 { return r.End - r.Start }
+
+// Detect splits a recording into alternating silent and non-silent regions
+// that cover it from start to end. Leading and trailing silence is a region
+// of its own regardless of its length.
+func Detect(channels [][]float64, sampleRate int, opts Options) ([]Region, error) {
+	silent, frameSize, err := frames(channels, sampleRate, opts)
+	if err != nil {
+		return nil, err
+	}
+	n := len(channels[0])
+	seconds := func(frame int) float64 {
+		return float64(min(frame*frameSize, n)) / float64(sampleRate)
+	}
+	minFrames := int(math.Ceil(opts.MinDuration * float64(sampleRate) / float64(frameSize)))
+
+	var regions []Region
+	for start := 0; start < len(silent); {
+		end := start + 1
+		for end < len(silent) && silent[end] == silent[start] {
+			end++
+		}
+		r := Region{Start: seconds(start), End: seconds(end), Silent: silent[start]}
+		// Short pauses inside the material belong to it
+		if r.Silent && start > 0 && end < len(silent) && end-start < minFrames {
+			r.Silent = false
+		}
+		if len(regions) > 0 && regions[len(regions)-1].Silent == r.Silent {
+			regions[len(regions)-1].End = r.End
+		} else {
+			regions = append(regions, r)
+		}
+		start = end
+	}
+	return regions, nil
+}
+
+// Trim returns the range of samples [start, end) without the leading and
+// trailing silence. It returns an error if the recording is silent
+// throughout.
+func Trim(channels [][]float64, sampleRate int, opts Options) (start, end int, err error) {
+	silent, frameSize, err := frames(channels, sampleRate, opts)
+	if err != nil {
+		return 0, 0, err
+	}
+	first, last := -1, -1
+	for i, s := range silent {
+		if !s {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return 0, 0, fmt.Errorf("the recording is silent below %g dBFS", opts.ThresholdDB)
+	}
+	return first * frameSize, min((last+1)*frameSize, len(channels[0])), nil
+}
+
+// frames returns whether each frame of the recording is silent and the
+// length of the frames in samples
+func frames(channels [][]float64, sampleRate int, opts Options) ([]bool, int, error) {
+	if len(channels) == 0 || len(channels[0]) == 0 {
+		return nil, 0, fmt.Errorf("no samples")
+	}
+	if sampleRate <= 0 {
+		return nil, 0, fmt.Errorf("invalid sample rate %d", sampleRate)
+	}
+	frameSize := int(math.Round(opts.FrameDuration * float64(sampleRate)))
+	if frameSize < 1 {
+		return nil, 0, fmt.Errorf("invalid frame duration %g s", opts.FrameDuration)
+	}
+	n := len(channels[0])
+	threshold := math.Pow(10, opts.ThresholdDB/10)
+	silent := make([]bool, (n+frameSize-1)/frameSize)
+	for i := range silent {
+		silent[i] = true
+		start, end := i*frameSize, min((i+1)*frameSize, n)
+		for _, x := range channels {
+			power := 0.0
+			for _, v := range x[start:min(end, len(x))] {
+				power += v * v
+			}
+			if power/float64(end-start) >= threshold {
+				silent[i] = false
+				break
+			}
+		}
+	}
+	return silent, frameSize, nil
+}
@@ -0,0 +1,82 @@
+package sinusoidal
+
+import (
+	"math"
+)
+
+// Resynthesize renders tracks with an oscillator bank into a signal of length
+// samples, so the result contains exactly what the analysis captured.
+//
+// Between two points of a track the amplitude is interpolated linearly and the
+// phase with the cubic polynomial of McAulay & Quatieri, which matches both the
+// measured frequencies and phases at the frame centers. Tracks fade in over
+// hopSize samples before their birth and fade out over hopSize samples after
+// their death.
+func Resynthesize(tracks []Track, sampleRate, hopSize, length int) []float64 {
+	out := make([]float64, length)
+	sr := float64(sampleRate)
+	hop := float64(hopSize)
+
+	for _, track := range tracks {
+		if len(track.Points) == 0 {
+			continue
+		}
+
+		// Birth, constant frequency and amplitude ramp from zero
+		first := track.Points[0]
+		start := first.Time * sr
+		omega := 2 * math.Pi * first.Frequency / sr
+		for n := int(math.Ceil(start - hop)); float64(n) < start; n++ {
+			if n < 0 || n >= length {
+				continue
+			}
+			t := float64(n) - start
+			out[n] += first.Amplitude * (1 + t/hop) * math.Cos(first.Phase+omega*t)
+		}
+
+		for i := 0; i+1 < len(track.Points); i++ {
+			synthesizeSegment(out, track.Points[i], track.Points[i+1], sr)
+		}
+
+		// Death, constant frequency and amplitude ramp to zero
+		last := track.Points[len(track.Points)-1]
+		end := last.Time * sr
+		omega = 2 * math.Pi * last.Frequency / sr
+		for n := int(math.Ceil(end)); float64(n) < end+hop; n++ {
+			if n < 0 || n >= length {
+				continue
+			}
+			t := float64(n) - end
+			out[n] += last.Amplitude * (1 - t/hop) * math.Cos(last.Phase+omega*t)
+		}
+	}
+	return out
+}
+
+// synthesizeSegment adds the oscillator output between two consecutive points
+func synthesizeSegment(out []float64, a, b Point, sr float64) {
+	start, end := a.Time*sr, b.Time*sr
+	T := end - start
+	if T <= 0 {
+		return
+	}
+
+	// Cubic phase interpolation, M is the number of extra cycles that gives the
+	// smoothest phase track
+	w0 := 2 * math.Pi * a.Frequency / sr
+	w1 := 2 * math.Pi * b.Frequency / sr
+	M := math.Round(((a.Phase + w0*T - b.Phase) + (w1-w0)*T/2) / (2 * math.Pi))
+	d := b.Phase - a.Phase - w0*T + 2*math.Pi*M
+	alpha := 3/(T*T)*d - (w1-w0)/T
+	beta := -2/(T*T*T)*d + (w1-w0)/(T*T)
+
+	for n := int(math.Ceil(start)); float64(n) < end; n++ {
+		if n < 0 || n >= len(out) {
+			continue
+		}
+		t := float64(n) - start
+		phase := a.Phase + w0*t + alpha*t*t + beta*t*t*t
+		amp := a.Amplitude + (b.Amplitude-a.Amplitude)*t/T
+		out[n] += amp * math.Cos(phase)
+	}
+}
@@ -0,0 +1,116 @@
+// Package sinusoidal implements sinusoidal modeling: tracking of partials across
+// STFT frames (McAulay & Quatieri, 1986) and additive resynthesis.
+package sinusoidal
+
+import (
+	"math"
+	"sort"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Point is the state of a partial in a single frame
+type Point struct {
+	Frame     int     // STFT frame index
+	Time      float64 // center of the frame in seconds
+	Frequency float64 // Hz
+	Amplitude float64
+	Phase     float64 // radians, at the center of the frame
+}
+
+// Track is a partial that was followed across consecutive frames. The first
+// point is its birth, the last point its death.
+type Track struct {
+	ID     int
+	Points []Point
+}
+
+// TrackOptions configures TrackPartials
+type TrackOptions struct {
+	Peaks          dft.PeakOptions // peak picking in each frame
+	MaxDeviationHz float64         // maximum frequency jump between two frames of a track
+	MinFrames      int             // tracks shorter than this are discarded
+}
+
+// TrackPartials picks the peaks of every STFT frame of samples and connects
+// them into tracks.
+//
+// Every active track is continued by the closest peak of the next frame within
+// MaxDeviationHz, closer pairs are matched first. Tracks without a match die and
+// peaks without a match give birth to new tracks. Tracks are returned in order
+// of birth.
+func TrackPartials(samples []float64, sampleRate, frameSize, hopSize int, opts TrackOptions) []Track {
+	frames := dft.STFT(samples, sampleRate, frameSize, hopSize)
+
+	tracks := []*Track{}
+	active := []*Track{}
+	for f, frame := range frames {
+		spectrum := dft.NewSpectrum(frame.Spectrum, sampleRate, frameSize, frameSize)
+		peaks := dft.FindPeaks(spectrum, opts.Peaks)
+
+		// All candidate continuations, closest first
+		type pair struct {
+			track, peak int
+			distance    float64
+		}
+		pairs := []pair{}
+		for t, track := range active {
+			last := track.Points[len(track.Points)-1]
+			for p, peak := range peaks {
+				d := math.Abs(peak.FreqHz - last.Frequency)
+				if d <= opts.MaxDeviationHz {
+					pairs = append(pairs, pair{t, p, d})
+				}
+			}
+		}
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return pairs[i].distance < pairs[j].distance
+		})
+
+		trackUsed := make([]bool, len(active))
+		peakUsed := make([]bool, len(peaks))
+		next := []*Track{}
+		for _, pr := range pairs {
+			if trackUsed[pr.track] || peakUsed[pr.peak] {
+				continue
+			}
+			trackUsed[pr.track], peakUsed[pr.peak] = true, true
+			track := active[pr.track]
+			track.Points = append(track.Points, newPoint(f, frame.Time, peaks[pr.peak]))
+			next = append(next, track)
+		}
+
+		// Births
+		for p, peak := range peaks {
+			if peakUsed[p] {
+				continue
+			}
+			track := &Track{ID: len(tracks), Points: []Point{newPoint(f, frame.Time, peak)}}
+			tracks = append(tracks, track)
+			next = append(next, track)
+		}
+		active = next
+	}
+
+	result := []Track{}
+	for _, track := range tracks {
+		if len(track.Points) >= opts.MinFrames {
+			result = append(result, *track)
+		}
+	}
+	return result
+}
+
+func newPoint(frame int, time float64, peak dft.Peak) Point {
+	// The FFT phase refers to the start of the frame. For a window that is
+	// symmetric around the frame center the phase at the center is obtained by
+	// advancing by half a frame at the frequency of the peak bin.
+	phase := peak.Phase + math.Pi*float64(peak.BinIndex)
+	return Point{
+		Frame:     frame,
+		Time:      time,
+		Frequency: peak.FreqHz,
+		Amplitude: peak.Magnitude,
+		Phase:     math.Remainder(phase, 2*math.Pi),
+	}
+}
@@ -0,0 +1,144 @@
+package dft
+
+import (
+	"fmt"
+	"math/cmplx"
+)
+
+// hanningGain is the coherent gain of the Hanning window
+const hanningGain = 0.5
+
+// Spectrum is the single-sided spectrum of a real valued signal
+type Spectrum struct {
+	Coefficients []complex128 // FFT coefficients of the non-negative frequencies
+	Magnitude    []float64    // amplitude of each bin, a full scale sine has a magnitude of 1
+	SampleRate   int
+	FFTSize      int
+	SignalLength int // number of samples before zero-padding
+}
+
+// FreqRes returns the width of a bin in Hz
+func (s Spectrum) FreqRes() float64 {
+	return float64(s.SampleRate) / float64(s.FFTSize)
+}
+
+// ComputeSpectrum applies a Hanning window to a copy of wave, zero-pads it to
+// the next power of two and computes its amplitude spectrum. The magnitudes are
+// scaled by the original signal length and the window gain so a sine of
+// amplitude A shows up with a magnitude of A.
+func ComputeSpectrum(wave []float64, sampleRate int) Spectrum {
+	fftSize := NextPowerOfTwo(len(wave))
+
+	// Zero-pad
+	padded := make([]float64, fftSize)
+	copy(padded, wave)
+	ApplyHanningWindow(padded[:len(wave)])
+
+	return NewSpectrum(Forward(padded), sampleRate, fftSize, len(wave))
+}
+
+// SpectrumOptions selects the window and the FFT size of
+// ComputeWindowedSpectrum and WindowedSTFT
+type SpectrumOptions struct {
+	Window  Window
+	FFTSize int // size after zero-padding, 0 selects the next power of two (or fast length) of the signal or frame length
+
+	// Padding is the zero-padding factor, e.g. 4 selects the next power of
+	// two of 4 times the signal or frame length. It interpolates the
+	// spectrum between the bins of the unpadded length without improving its
+	// resolution. 0 and 1 pad to the next power of two only; Padding cannot
+	// be combined with FFTSize.
+	Padding int
+
+	// FastLen pads to the next 5-smooth length (see NextFastLen) instead of
+	// the next power of two, which saves memory and time for lengths just
+	// above a power of two
+	FastLen bool
+
+	// Detrend is applied before the window, to the signal or to each frame
+	// of WindowedSTFT. The DC blocker filters the whole signal.
+	Detrend Detrend
+
+	// Progress is called after every frame of WindowedSTFT. It is optional.
+	Progress ProgressFunc
+}
+
+// DefaultSpectrumOptions are the choices of ComputeSpectrum and STFT
+var DefaultSpectrumOptions = SpectrumOptions{Window: Window{Type: Hanning}}
+
+// fftSize returns the FFT size for a signal of n samples
+func (o SpectrumOptions) fftSize(n int) (int, error) {
+	if o.Padding < 0 {
+		return 0, fmt.Errorf("invalid zero-padding factor %d", o.Padding)
+	}
+	if o.FFTSize == 0 {
+		if o.FastLen {
+			return NextFastLen(n * max(o.Padding, 1)), nil
+		}
+		return NextPowerOfTwo(n * max(o.Padding, 1)), nil
+	}
+	if o.Padding > 1 {
+		return 0, fmt.Errorf("the FFT size %d and the zero-padding factor %d are exclusive", o.FFTSize, o.Padding)
+	}
+	if o.FFTSize < n {
+		return 0, fmt.Errorf("FFT size %d is smaller than the %d samples to transform", o.FFTSize, n)
+	}
+	return o.FFTSize, nil
+}
+
+// coherentGain returns the coherent gain of w, exactly hanningGain for the
+// Hanning window so the results equal those of ComputeSpectrum and STFT
+func coherentGain(w Window) float64 {
+	if w.Type == Hanning {
+		return hanningGain
+	}
+	return w.CoherentGain()
+}
+
+// ComputeWindowedSpectrum is ComputeSpectrum with the window and FFT size of
+// opts. The magnitudes are corrected for the coherent gain of the window.
+func ComputeWindowedSpectrum(wave []float64, sampleRate int, opts SpectrumOptions) (Spectrum, error) {
+	fftSize, err := opts.fftSize(len(wave))
+	if err != nil {
+		return Spectrum{}, err
+	}
+	padded := make([]float64, fftSize)
+	copy(padded, wave)
+	opts.Detrend.Apply(padded[:len(wave)])
+	opts.Window.Apply(padded[:len(wave)])
+
+	s := NewSpectrum(Forward(padded), sampleRate, fftSize, len(wave))
+	scale := hanningGain / coherentGain(opts.Window)
+	for i := range s.Magnitude {
+		s.Magnitude[i] *= scale
+	}
+	return s, nil
+}
+
+// ComputeSpectra computes the spectrum of every channel of a multi-channel
+// signal with ComputeSpectrum
+func ComputeSpectra(channels [][]float64, sampleRate int) []Spectrum {
+	spectra := make([]Spectrum, len(channels))
+	for i, wave := range channels {
+		spectra[i] = ComputeSpectrum(wave, sampleRate)
+	}
+	return spectra
+}
+
+// NewSpectrum creates the Spectrum of FFT coefficients of a Hanning windowed
+// signal of signalLength samples that was zero-padded to fftSize samples, e.g.
+// a Frame of STFT.
+func NewSpectrum(coeffs []complex128, sampleRate, fftSize, signalLength int) Spectrum {
+	mag := make([]float64, len(coeffs))
+	for i, c := range coeffs {
+		mag[i] = cmplx.Abs(c) * 2 / float64(signalLength) / hanningGain
+	}
+
+	return Spectrum{
+		Coefficients: coeffs,
+		Magnitude:    mag,
+		SampleRate:   sampleRate,
+		FFTSize:      fftSize,
+		SignalLength: signalLength,
+	}
+}
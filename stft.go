@@ -0,0 +1,129 @@
+package dft
+
+import "fmt"
+
+// Frame is a single frame of a short-time fourier transform
+type Frame struct {
+	Time     float64      // center of the frame in seconds
+	Spectrum []complex128 // coefficients of the non-negative frequencies
+}
+
+// Frames splits samples into frames of frameSize samples, advancing by hopSize
+// samples. Trailing samples that do not fill a whole frame are dropped.
+// The returned frames share memory with samples.
+func Frames(samples []float64, frameSize, hopSize int) [][]float64 {
+	if frameSize <= 0 || hopSize <= 0 {
+		return nil
+	}
+	frames := [][]float64{}
+	for start := 0; start+frameSize <= len(samples); start += hopSize {
+		frames = append(frames, samples[start:start+frameSize])
+	}
+	return frames
+}
+
+// FrameTime returns the time in seconds of the center of the i-th frame
+func FrameTime(i, sampleRate, frameSize, hopSize int) float64 {
+	return (float64(i*hopSize) + float64(frameSize)/2) / float64(sampleRate)
+}
+
+// STFT computes the short-time fourier transform of samples using a Hanning
+// window of frameSize samples and a hop of hopSize samples between frames.
+func STFT(samples []float64, sampleRate, frameSize, hopSize int) []Frame {
+	frames := Frames(samples, frameSize, hopSize)
+	result := make([]Frame, len(frames))
+	buf := make([]float64, frameSize)
+	for i, frame := range frames {
+		copy(buf, frame)
+		ApplyHanningWindow(buf)
+		result[i] = Frame{
+			Time:     FrameTime(i, sampleRate, frameSize, hopSize),
+			Spectrum: Forward(buf),
+		}
+	}
+	return result
+}
+
+// WindowedSTFT is STFT with the window and FFT size of opts and returns the
+// FFT size. Each frame is zero-padded to the FFT size and its coefficients are
+// scaled so that the frames can be used like STFT frames with a frame size of
+// the FFT size, e.g. NewSpectrum(f.Spectrum, sampleRate, fftSize, fftSize)
+// returns the amplitudes. Such frames cannot be inverted with ISTFT.
+func WindowedSTFT(samples []float64, sampleRate, frameSize, hopSize int, opts SpectrumOptions) ([]Frame, int, error) {
+	if frameSize <= 0 || hopSize <= 0 {
+		return nil, 0, fmt.Errorf("invalid frame size %d or hop size %d", frameSize, hopSize)
+	}
+	fftSize, err := opts.fftSize(frameSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	window := opts.Window.Coefficients(frameSize)
+	scale := complex(float64(fftSize)/float64(frameSize)*hanningGain/coherentGain(opts.Window), 0)
+
+	detrend := opts.Detrend
+	if detrend == DetrendDC {
+		// The filter would settle anew in every frame
+		samples = append([]float64(nil), samples...)
+		BlockDC(samples, DCBlockPole)
+		detrend = NoDetrend
+	}
+	frames := Frames(samples, frameSize, hopSize)
+	result := make([]Frame, len(frames))
+	buf := make([]float64, fftSize)
+	progress := newProgressReporter(opts.Progress, int64(len(frames)))
+	for i, frame := range frames {
+		copy(buf, frame)
+		detrend.Apply(buf[:len(frame)])
+		for j := range frame {
+			buf[j] *= window[j]
+		}
+		coeffs := Forward(buf)
+		for j := range coeffs {
+			coeffs[j] *= scale
+		}
+		result[i] = Frame{
+			Time:     FrameTime(i, sampleRate, frameSize, hopSize),
+			Spectrum: coeffs,
+		}
+		progress.report(int64(i + 1))
+	}
+	return result, fftSize, nil
+}
+
+// ISTFT reconstructs a signal of length samples from STFT frames computed with
+// the given frame and hop size. The frames are windowed again and overlap-added,
+// then normalized by the sum of the squared windows, which inverts STFT exactly
+// where the windows overlap. Samples not covered by any window with a
+// significant weight, such as the first and last sample, are set to zero; pad
+// the signal by frameSize samples on both ends before the STFT to avoid this.
+func ISTFT(frames []Frame, frameSize, hopSize, length int) []float64 {
+	out := make([]float64, length)
+	norm := make([]float64, length)
+
+	window := make([]float64, frameSize)
+	for i := range window {
+		window[i] = 1
+	}
+	ApplyHanningWindow(window)
+
+	for f, frame := range frames {
+		seq := Inverse(frame.Spectrum, frameSize)
+		start := f * hopSize
+		for i, v := range seq {
+			if start+i >= length {
+				break
+			}
+			out[start+i] += v * window[i]
+			norm[start+i] += window[i] * window[i]
+		}
+	}
+
+	for i := range out {
+		if norm[i] > 1e-8 {
+			out[i] /= norm[i]
+		} else {
+			out[i] = 0
+		}
+	}
+	return out
+}
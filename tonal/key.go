@@ -0,0 +1,112 @@
+// Package tonal implements tonal analysis such as musical key detection.
+package tonal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Mode is the mode of a musical key
+type Mode int
+
+const (
+	Major Mode = iota
+	Minor
+)
+
+func (m Mode) String() string {
+	if m == Minor {
+		return "minor"
+	}
+	return "major"
+}
+
+// Krumhansl–Kessler key profiles, starting at the tonic
+var (
+	majorProfile = [12]float64{6.35, 2.23, 3.48, 2.33, 4.38, 4.09, 2.52, 5.19, 2.39, 3.66, 2.29, 2.88}
+	minorProfile = [12]float64{6.33, 2.68, 3.52, 5.38, 2.60, 3.53, 2.54, 4.75, 3.98, 2.69, 3.34, 3.17}
+)
+
+// Key is a musical key with the correlation of the analyzed chroma to its profile
+type Key struct {
+	Tonic       string // pitch class of the tonic, e.g. "A" or "F#"
+	Mode        Mode
+	Correlation float64 // Pearson correlation in the range [-1..1]
+}
+
+// String formats the key as e.g. "A minor"
+func (k Key) String() string {
+	return fmt.Sprintf("%s %s", k.Tonic, k.Mode)
+}
+
+// KeyEstimate is the result of DetectKey
+type KeyEstimate struct {
+	Key    Key   // best matching key
+	Scores []Key // all 24 keys, best match first
+}
+
+// DetectKey estimates the musical key of a chroma vector (see dft.Chroma) by
+// correlating it with the rotated Krumhansl–Kessler profiles of all major and
+// minor keys.
+func DetectKey(chroma [12]float64) KeyEstimate {
+	scores := make([]Key, 0, 24)
+	for tonic := 0; tonic < 12; tonic++ {
+		for _, mode := range []Mode{Major, Minor} {
+			profile := majorProfile
+			if mode == Minor {
+				profile = minorProfile
+			}
+			var rotated [12]float64
+			for i := range rotated {
+				rotated[(i+tonic)%12] = profile[i]
+			}
+			scores = append(scores, Key{
+				Tonic:       dft.PitchClassNames[tonic],
+				Mode:        mode,
+				Correlation: correlation(chroma, rotated),
+			})
+		}
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].Correlation > scores[j].Correlation
+	})
+	return KeyEstimate{Key: scores[0], Scores: scores}
+}
+
+// EstimateKey estimates the musical key of samples from the sum of their chromagram
+func EstimateKey(samples []float64, sampleRate, frameSize, hopSize int, referenceHz float64) KeyEstimate {
+	var total [12]float64
+	for _, chroma := range dft.Chromagram(samples, sampleRate, frameSize, hopSize, referenceHz) {
+		for i, v := range chroma {
+			total[i] += v
+		}
+	}
+	return DetectKey(total)
+}
+
+// correlation returns the Pearson correlation coefficient of a and b
+func correlation(a, b [12]float64) float64 {
+	meanA, meanB := 0.0, 0.0
+	for i := range a {
+		meanA += a[i]
+		meanB += b[i]
+	}
+	meanA /= 12
+	meanB /= 12
+
+	cov, varA, varB := 0.0, 0.0, 0.0
+	for i := range a {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
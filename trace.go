@@ -0,0 +1,118 @@
+package dft
+
+import (
+	"fmt"
+	"math"
+)
+
+// TraceMode is the way a Trace combines consecutive spectra, like the trace
+// modes of a spectrum analyzer
+type TraceMode int
+
+const (
+	ClearWrite TraceMode = iota // show the latest spectrum
+	Average                     // exponential average of the power
+	MaxHold                     // maximum of every bin since the last reset
+	MinHold                     // minimum of every bin since the last reset
+)
+
+var traceModeNames = map[TraceMode]string{
+	ClearWrite: "clear",
+	Average:    "average",
+	MaxHold:    "max-hold",
+	MinHold:    "min-hold",
+}
+
+func (m TraceMode) String() string {
+	return traceModeNames[m]
+}
+
+// ParseTraceMode parses clear, average, max-hold or min-hold
+func ParseTraceMode(s string) (TraceMode, error) {
+	for mode, name := range traceModeNames {
+		if s == name {
+			return mode, nil
+		}
+	}
+	switch s {
+	case "avg":
+		return Average, nil
+	case "max":
+		return MaxHold, nil
+	case "min":
+		return MinHold, nil
+	}
+	return 0, fmt.Errorf("invalid trace mode %q", s)
+}
+
+// Trace combines the magnitude spectra of a stream, e.g. of StreamAnalyzer
+// frames turned into a Spectrum with NewSpectrum
+type Trace struct {
+	Mode TraceMode
+
+	// Alpha is the weight of the latest spectrum in Average mode, e.g. 0.1 for
+	// an average over about 10 spectra. The first spectrum after a reset is
+	// taken as is.
+	Alpha float64
+
+	values []float64 // magnitudes, or powers in Average mode
+	out    []float64
+}
+
+// NewTrace returns an empty Trace
+func NewTrace(mode TraceMode, alpha float64) *Trace {
+	return &Trace{Mode: mode, Alpha: alpha}
+}
+
+// Update adds a magnitude spectrum and returns the resulting trace. The result
+// is only valid until the next call. A spectrum with a different number of
+// bins than the previous ones resets the trace.
+func (t *Trace) Update(magnitude []float64) []float64 {
+	if len(t.values) != len(magnitude) {
+		t.values = nil
+	}
+	if t.values == nil {
+		t.values = make([]float64, len(magnitude))
+		t.out = make([]float64, len(magnitude))
+		for i, m := range magnitude {
+			t.values[i] = m
+			if t.Mode == Average {
+				t.values[i] = m * m
+			}
+		}
+		return t.Values()
+	}
+
+	for i, m := range magnitude {
+		switch t.Mode {
+		case Average:
+			t.values[i] = t.Alpha*m*m + (1-t.Alpha)*t.values[i]
+		case MaxHold:
+			t.values[i] = math.Max(t.values[i], m)
+		case MinHold:
+			t.values[i] = math.Min(t.values[i], m)
+		default:
+			t.values[i] = m
+		}
+	}
+	return t.Values()
+}
+
+// Values returns the current trace, nil before the first update
+func (t *Trace) Values() []float64 {
+	if t.values == nil {
+		return nil
+	}
+	for i, v := range t.values {
+		if t.Mode == Average {
+			v = math.Sqrt(v)
+		}
+		t.out[i] = v
+	}
+	return t.out
+}
+
+// Reset clears the trace, e.g. to restart max-hold after a change of the input
+func (t *Trace) Reset() {
+	t.values = nil
+}
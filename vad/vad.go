@@ -0,0 +1,236 @@
+// Package vad detects voice activity, the regions of a recording that
+// contain speech, e.g. to restrict pitch or formant analysis to them.
+package vad
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Options configures Detect. Durations are in seconds.
+type Options struct {
+	FrameDuration float64 // length of the analysis frames
+	HopDuration   float64 // distance of the analysis frames
+
+	// A frame is speech if its energy exceeds the noise floor by EnergyDB
+	// and the normalized spectral entropy of the speech band differs from
+	// that of the noise by EntropyDelta. The noise floor is estimated from
+	// the quietest frames of the recording.
+	EnergyDB     float64
+	EntropyDelta float64
+	MinHz, MaxHz float64 // speech band of the entropy
+
+	MinSpeech  float64 // shorter speech regions are dropped
+	MinSilence float64 // shorter pauses are merged into the surrounding speech
+	Padding    float64 // speech regions are extended by Padding on both sides
+}
+
+// DefaultOptions are suited for speech at common sample rates
+var DefaultOptions = Options{
+	FrameDuration: 0.025,
+	HopDuration:   0.010,
+	EnergyDB:      9,
+	EntropyDelta:  0.05,
+	MinHz:         250,
+	MaxHz:         4000,
+	MinSpeech:     0.1,
+	MinSilence:    0.3,
+	Padding:       0.05,
+}
+
+// Segment is a region of a recording
+type Segment struct {
+	Start  float64 `json:"start_s"`
+	End    float64 `json:"end_s"`
+	Speech bool    `json:"speech"`
+}
+
+// Duration returns the length of the segment in seconds
+func (s Segment) Duration() float64 { return s.End - s.Start }
+
+// Frame is the analysis of a single frame
+type Frame struct {
+	Time     float64 // center of the frame in seconds
+	EnergyDB float64 // mean power in dB
+	Entropy  float64 // spectral entropy of the speech band in [0..1], 1 for white noise
+	Speech   bool    // the raw decision before smoothing
+}
+
+// Detect splits a recording into alternating speech and non-speech segments
+// that cover it from start to end. Each frame is classified by its energy
+// and spectral entropy relative to the noise; the decisions are then
+// smoothed with the minimum durations of opts and the speech is padded.
+func Detect(samples []float64, sampleRate int, opts Options) ([]Segment, error) {
+	frames, err := Analyze(samples, sampleRate, opts)
+	if err != nil {
+		return nil, err
+	}
+	hop := opts.HopDuration
+	active := make([]bool, len(frames))
+	for i, f := range frames {
+		active[i] = f.Speech
+	}
+	fill(active, int(math.Round(opts.MinSilence/hop)), false)
+	fill(active, int(math.Round(opts.MinSpeech/hop)), true)
+
+	total := float64(len(samples)) / float64(sampleRate)
+	var speech []Segment
+	for i := 0; i < len(active); {
+		if !active[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < len(active) && active[j] {
+			j++
+		}
+		// Frame i covers the hop around its center
+		start := math.Max(0, frames[i].Time-hop/2-opts.Padding)
+		end := math.Min(total, frames[j-1].Time+hop/2+opts.Padding)
+		if n := len(speech); n > 0 && start <= speech[n-1].End {
+			speech[n-1].End = end
+		} else {
+			speech = append(speech, Segment{Start: start, End: end, Speech: true})
+		}
+		i = j
+	}
+
+	var segments []Segment
+	at := 0.0
+	for _, s := range speech {
+		if s.Start > at {
+			segments = append(segments, Segment{Start: at, End: s.Start})
+		}
+		segments = append(segments, s)
+		at = s.End
+	}
+	if at < total {
+		segments = append(segments, Segment{Start: at, End: total})
+	}
+	return segments, nil
+}
+
+// fill inverts the runs of value shorter than n frames. Pauses (false) are
+// only filled between speech, not at the start or end of the recording.
+func fill(active []bool, n int, value bool) {
+	for i := 0; i < len(active); {
+		if active[i] != value {
+			i++
+			continue
+		}
+		j := i
+		for j < len(active) && active[j] == value {
+			j++
+		}
+		inner := i > 0 && j < len(active)
+		if j-i < n && (inner || value) {
+			for k := i; k < j; k++ {
+				active[k] = !value
+			}
+		}
+		i = j
+	}
+}
+
+// Analyze computes the energy and entropy of each frame and classifies it
+func Analyze(samples []float64, sampleRate int, opts Options) ([]Frame, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate %d", sampleRate)
+	}
+	frameSize := int(math.Round(opts.FrameDuration * float64(sampleRate)))
+	hopSize := int(math.Round(opts.HopDuration * float64(sampleRate)))
+	if frameSize < 2 || hopSize < 1 {
+		return nil, fmt.Errorf("invalid frame duration %g s or hop duration %g s", opts.FrameDuration, opts.HopDuration)
+	}
+	fftSize := dft.NextPowerOfTwo(frameSize)
+	res := float64(sampleRate) / float64(fftSize)
+	lo := max(1, int(math.Ceil(opts.MinHz/res)))
+	hi := min(fftSize/2, int(opts.MaxHz/res))
+	if hi-lo < 2 {
+		return nil, fmt.Errorf("the speech band %g to %g Hz is too narrow", opts.MinHz, opts.MaxHz)
+	}
+
+	raw := dft.Frames(samples, frameSize, hopSize)
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("the recording is shorter than a frame of %g s", opts.FrameDuration)
+	}
+	window := dft.Window{Type: dft.Hanning}.Coefficients(frameSize)
+	buf := make([]float64, fftSize)
+	frames := make([]Frame, len(raw))
+	for i, x := range raw {
+		power := 0.0
+		for n, v := range x {
+			power += v * v
+			buf[n] = v * window[n]
+		}
+		frames[i].Time = dft.FrameTime(i, sampleRate, frameSize, hopSize)
+		frames[i].EnergyDB = 10 * math.Log10(power/float64(frameSize)+1e-20)
+		frames[i].Entropy = entropy(dft.Forward(buf)[lo : hi+1])
+	}
+
+	// The noise is described by the quietest tenth of the frames
+	order := make([]int, len(frames))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return frames[order[a]].EnergyDB < frames[order[b]].EnergyDB })
+	quiet := order[:max(1, len(order)/10)]
+	noiseDB := frames[quiet[len(quiet)-1]].EnergyDB
+	entropies := make([]float64, len(quiet))
+	for i, k := range quiet {
+		entropies[i] = frames[k].Entropy
+	}
+	sort.Float64s(entropies)
+	noiseEntropy := entropies[len(entropies)/2]
+
+	for i := range frames {
+		f := &frames[i]
+		f.Speech = f.EnergyDB > noiseDB+opts.EnergyDB && math.Abs(f.Entropy-noiseEntropy) > opts.EntropyDelta
+	}
+	return frames, nil
+}
+
+// entropy returns the Shannon entropy of the power distribution over the
+// bins, normalized to 1 for a flat spectrum
+func entropy(bins []complex128) float64 {
+	total := 0.0
+	for _, c := range bins {
+		total += real(c)*real(c) + imag(c)*imag(c)
+	}
+	if total == 0 {
+		return 1
+	}
+	h := 0.0
+	for _, c := range bins {
+		if p := (real(c)*real(c) + imag(c)*imag(c)) / total; p > 0 {
+			h -= p * math.Log(p)
+		}
+	}
+	return h / math.Log(float64(len(bins)))
+}
+
+// IsSpeech reports whether the time t in seconds lies in a speech segment,
+// e.g. to drop the points of a pitch contour outside of speech
+func IsSpeech(segments []Segment, t float64) bool {
+	i := sort.Search(len(segments), func(i int) bool { return segments[i].End > t })
+	return i < len(segments) && segments[i].Start <= t && segments[i].Speech
+}
+
+// Speech returns the samples of the speech segments
+func Speech(samples []float64, sampleRate int, segments []Segment) [][]float64 {
+	var parts [][]float64
+	for _, s := range segments {
+		if !s.Speech {
+			continue
+		}
+		start := min(len(samples), int(math.Round(s.Start*float64(sampleRate))))
+		end := min(len(samples), int(math.Round(s.End*float64(sampleRate))))
+		if end > start {
+			parts = append(parts, samples[start:end])
+		}
+	}
+	return parts
+}
@@ -0,0 +1,174 @@
+package dft
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"math/rand"
+)
+
+// NaiveDFT computes the DFT of a real valued signal by its definition
+// X[k] = Σ x[n]·e^(-2πikn/N) in O(N²) and returns the len(samples)/2+1
+// coefficients of the non-negative frequencies, like Forward. It is the
+// reference of VerifyForward and too slow for anything else.
+func NaiveDFT(samples []float64) []complex128 {
+	n := len(samples)
+	coeffs := make([]complex128, n/2+1)
+	for k := range coeffs {
+		var sum complex128
+		for i, v := range samples {
+			sum += complex(v, 0) * twiddle(k*i, n)
+		}
+		coeffs[k] = sum
+	}
+	return coeffs
+}
+
+// NaiveDFTComplex is NaiveDFT for a complex valued signal and returns all
+// len(samples) coefficients in FFT order, like ForwardComplex
+func NaiveDFTComplex(samples []complex128) []complex128 {
+	n := len(samples)
+	coeffs := make([]complex128, n)
+	for k := range coeffs {
+		var sum complex128
+		for i, v := range samples {
+			sum += v * twiddle(k*i, n)
+		}
+		coeffs[k] = sum
+	}
+	return coeffs
+}
+
+// twiddle returns e^(-2πi·kn/N). Reducing kn modulo N first keeps the angle
+// exact for large products.
+func twiddle(kn, n int) complex128 {
+	return cmplx.Rect(1, -2*math.Pi*float64(kn%n)/float64(n))
+}
+
+// VerifyOptions configures the Verify functions
+type VerifyOptions struct {
+	Sizes  []int   // transform lengths, odd and prime ones included
+	Trials int     // random signals per size
+	Seed   int64   // of the random signals, for reproducible runs
+	MaxErr float64 // highest error relative to the largest reference coefficient
+}
+
+// DefaultVerifyOptions cover powers of two, 5-smooth, odd and prime lengths
+var DefaultVerifyOptions = VerifyOptions{
+	Sizes:  []int{1, 2, 3, 4, 5, 7, 8, 12, 16, 17, 31, 60, 64, 97, 100, 128, 255, 256, 1000, 1024},
+	Trials: 3,
+	Seed:   1,
+	MaxErr: 1e-9,
+}
+
+// VerifyForward cross-checks a transform with the contract of Forward, e.g. a
+// custom FFT backend, against NaiveDFT on random signals. It returns the
+// largest relative error, and an error describing the first signal whose
+// coefficients differ by more than opts.MaxErr or have the wrong number.
+func VerifyForward(forward func(samples []float64) []complex128, opts VerifyOptions) (float64, error) {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	worst := 0.0
+	for _, n := range opts.Sizes {
+		for trial := range opts.Trials {
+			x := make([]float64, n)
+			for i := range x {
+				x[i] = rng.NormFloat64()
+			}
+			got, want := forward(append([]float64(nil), x...)), NaiveDFT(x)
+			e, err := compareCoefficients(got, want)
+			if err == nil && e > opts.MaxErr {
+				err = fmt.Errorf("relative error %.3g exceeds %.3g", e, opts.MaxErr)
+			}
+			if err != nil {
+				return worst, fmt.Errorf("size %d, trial %d: %w", n, trial+1, err)
+			}
+			worst = math.Max(worst, e)
+		}
+	}
+	return worst, nil
+}
+
+// VerifyForwardComplex is VerifyForward for a transform with the contract of
+// ForwardComplex
+func VerifyForwardComplex(forward func(samples []complex128) []complex128, opts VerifyOptions) (float64, error) {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	worst := 0.0
+	for _, n := range opts.Sizes {
+		for trial := range opts.Trials {
+			x := make([]complex128, n)
+			for i := range x {
+				x[i] = complex(rng.NormFloat64(), rng.NormFloat64())
+			}
+			got, want := forward(append([]complex128(nil), x...)), NaiveDFTComplex(x)
+			e, err := compareCoefficients(got, want)
+			if err == nil && e > opts.MaxErr {
+				err = fmt.Errorf("relative error %.3g exceeds %.3g", e, opts.MaxErr)
+			}
+			if err != nil {
+				return worst, fmt.Errorf("size %d, trial %d: %w", n, trial+1, err)
+			}
+			worst = math.Max(worst, e)
+		}
+	}
+	return worst, nil
+}
+
+// VerifyInverse checks that an inverse transform with the contract of Inverse
+// reproduces random signals from their NaiveDFT coefficients, which catches a
+// missing or doubled 1/N normalization. The error is relative to the largest
+// sample.
+func VerifyInverse(inverse func(coeffs []complex128, n int) []float64, opts VerifyOptions) (float64, error) {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	worst := 0.0
+	for _, n := range opts.Sizes {
+		for trial := range opts.Trials {
+			x := make([]float64, n)
+			for i := range x {
+				x[i] = rng.NormFloat64()
+			}
+			got := inverse(NaiveDFT(x), n)
+			e, err := compareSamples(got, x)
+			if err == nil && e > opts.MaxErr {
+				err = fmt.Errorf("relative error %.3g exceeds %.3g", e, opts.MaxErr)
+			}
+			if err != nil {
+				return worst, fmt.Errorf("size %d, trial %d: %w", n, trial+1, err)
+			}
+			worst = math.Max(worst, e)
+		}
+	}
+	return worst, nil
+}
+
+// compareCoefficients returns the largest difference of got and want
+// relative to the largest coefficient of want
+func compareCoefficients(got, want []complex128) (float64, error) {
+	if len(got) != len(want) {
+		return 0, fmt.Errorf("%d coefficients instead of %d", len(got), len(want))
+	}
+	scale, diff := 0.0, 0.0
+	for k := range want {
+		scale = math.Max(scale, cmplx.Abs(want[k]))
+		diff = math.Max(diff, cmplx.Abs(got[k]-want[k]))
+	}
+	if scale == 0 {
+		return diff, nil
+	}
+	return diff / scale, nil
+}
+
+// compareSamples is compareCoefficients for real samples
+func compareSamples(got, want []float64) (float64, error) {
+	if len(got) != len(want) {
+		return 0, fmt.Errorf("%d samples instead of %d", len(got), len(want))
+	}
+	scale, diff := 0.0, 0.0
+	for i := range want {
+		scale = math.Max(scale, math.Abs(want[i]))
+		diff = math.Max(diff, math.Abs(got[i]-want[i]))
+	}
+	if scale == 0 {
+		return diff, nil
+	}
+	return diff / scale, nil
+}
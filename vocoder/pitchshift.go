@@ -0,0 +1,68 @@
+package vocoder
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/epikur-io/go-discrete-fourier-transform/resample"
+)
+
+// maxRatioTerm bounds the numerator and denominator of the resampling ratio
+const maxRatioTerm = 1000
+
+// PitchShift shifts the pitch of samples by semitones without changing their
+// duration. The signal is time stretched by 2^(semitones/12) and resampled back
+// to its original length. To correct a note detected as dft.Note, shift by
+// -note.Cents/100 semitones.
+func PitchShift(samples []float64, semitones float64, opts StretchOptions) ([]float64, error) {
+	if math.IsInf(semitones, 0) || math.IsNaN(semitones) {
+		return nil, fmt.Errorf("invalid pitch shift %g", semitones)
+	}
+	factor := math.Pow(2, semitones/12)
+	if semitones == 0 {
+		return append([]float64(nil), samples...), nil
+	}
+
+	stretched, err := TimeStretch(samples, factor, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resampling by a rational approximation of the factor keeps the pitch
+	// accurate to a fraction of a cent
+	num, den := rationalApprox(factor, maxRatioTerm)
+	shifted, err := resample.Resample(stretched, num, den)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]float64, len(samples))
+	copy(result, shifted)
+	return result, nil
+}
+
+// rationalApprox returns the continued fraction approximation num/den of x > 0
+// with the smallest error whose numerator and denominator do not exceed limit
+func rationalApprox(x float64, limit int) (num, den int) {
+	// Convergents h/k of the continued fraction of x
+	h0, h1 := 0, 1
+	k0, k1 := 1, 0
+	r := x
+	for {
+		a := int(math.Floor(r))
+		h2, k2 := a*h1+h0, a*k1+k0
+		if h2 > limit || k2 > limit {
+			break
+		}
+		h0, h1, k0, k1 = h1, h2, k1, k2
+		frac := r - float64(a)
+		if frac < 1e-12 {
+			break
+		}
+		r = 1 / frac
+	}
+	if h1 == 0 || k1 == 0 {
+		return 1, 1
+	}
+	return h1, k1
+}
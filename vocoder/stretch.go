@@ -0,0 +1,93 @@
+package vocoder
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// transientWindow is the number of frames on each side of a frame that are used
+// for the adaptive transient threshold
+const transientWindow = 4
+
+// StretchOptions configures TimeStretch
+type StretchOptions struct {
+	FrameSize int
+
+	// PreserveTransients resets the phases of the frames with a sharp rise of
+	// the spectral flux, so attacks of drums and plucked notes are not smeared
+	PreserveTransients bool
+
+	// TransientThreshold is how much the normalized spectral flux of a frame
+	// has to exceed its local median to count as a transient, typically 0.1
+	TransientThreshold float64
+}
+
+// DefaultStretchOptions suit music at 44.1 or 48 kHz
+var DefaultStretchOptions = StretchOptions{FrameSize: 2048, PreserveTransients: true, TransientThreshold: 0.1}
+
+// TimeStretch changes the duration of samples by ratio without changing the
+// pitch, e.g. a ratio of 2 plays twice as long. The synthesis hop is a quarter
+// frame and the analysis hop is derived from the ratio. The result has
+// round(len(samples)*ratio) samples.
+func TimeStretch(samples []float64, ratio float64, opts StretchOptions) ([]float64, error) {
+	if ratio <= 0 || math.IsInf(ratio, 0) || math.IsNaN(ratio) {
+		return nil, fmt.Errorf("invalid stretch ratio %g", ratio)
+	}
+	if opts.FrameSize < 4 {
+		return nil, fmt.Errorf("invalid frame size %d", opts.FrameSize)
+	}
+	synthesisHop := opts.FrameSize / 4
+	analysisHop := int(math.Round(float64(synthesisHop) / ratio))
+	if analysisHop < 1 {
+		return nil, fmt.Errorf("stretch ratio %g is too large for frames of %d samples", ratio, opts.FrameSize)
+	}
+
+	out := run(samples, Options{
+		FrameSize:    opts.FrameSize,
+		AnalysisHop:  analysisHop,
+		SynthesisHop: synthesisHop,
+	}, func(frames []Frame) {
+		if opts.PreserveTransients {
+			markTransients(frames, opts.TransientThreshold)
+		}
+	})
+
+	length := int(math.Round(float64(len(samples)) * ratio))
+	result := make([]float64, length)
+	copy(result, out)
+	return result, nil
+}
+
+// markTransients sets Reset on the frames whose normalized spectral flux is a
+// local maximum and exceeds the local median by more than threshold
+func markTransients(frames []Frame, threshold float64) {
+	flux := make([]float64, len(frames))
+	peak := 0.0
+	for i := 1; i < len(frames); i++ {
+		for k, m := range frames[i].Magnitude {
+			if d := math.Log1p(m) - math.Log1p(frames[i-1].Magnitude[k]); d > 0 {
+				flux[i] += d
+			}
+		}
+		peak = math.Max(peak, flux[i])
+	}
+	if peak == 0 {
+		return
+	}
+
+	local := make([]float64, 0, 2*transientWindow+1)
+	for i := range frames {
+		start, end := max(i-transientWindow, 0), min(i+transientWindow, len(frames)-1)
+		local = local[:0]
+		isMax := true
+		for j := start; j <= end; j++ {
+			local = append(local, flux[j]/peak)
+			if flux[j] > flux[i] {
+				isMax = false
+			}
+		}
+		sort.Float64s(local)
+		frames[i].Reset = isMax && flux[i]/peak > local[len(local)/2]+threshold
+	}
+}
@@ -0,0 +1,149 @@
+// Package vocoder implements a phase vocoder for time stretching, pitch shifting
+// and other modifications of the short-time spectrum.
+package vocoder
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	dft "github.com/epikur-io/go-discrete-fourier-transform"
+)
+
+// Frame is an analysis frame of the phase vocoder
+type Frame struct {
+	Magnitude []float64
+	Phase     []float64 // analysis phase of every bin in radians
+	Frequency []float64 // instantaneous frequency of every bin in radians per sample
+
+	// Reset makes Synthesize use the analysis phase instead of the propagated
+	// phase, which keeps transients sharp at the cost of a phase discontinuity
+	Reset bool
+}
+
+// Options configures Run
+type Options struct {
+	FrameSize    int
+	AnalysisHop  int // hop between the analysis frames
+	SynthesisHop int // hop between the synthesis frames, the duration changes by SynthesisHop/AnalysisHop
+
+	// Process is called for every frame between analysis and synthesis and may
+	// modify its magnitudes and frequencies. It is optional.
+	Process func(index int, frame *Frame)
+}
+
+// DefaultOptions analyzes and resynthesizes with 2048 sample frames and a hop
+// of a quarter frame, which leaves the signal unchanged
+var DefaultOptions = Options{FrameSize: 2048, AnalysisHop: 512, SynthesisHop: 512}
+
+// Analyze computes the Hanning windowed frames of samples and estimates the
+// instantaneous frequency of every bin from the phase advance between
+// consecutive frames. The hop should be at most a quarter frame for reliable
+// frequency estimates.
+func Analyze(samples []float64, frameSize, hopSize int) []Frame {
+	bins := frameSize/2 + 1
+	buf := make([]float64, frameSize)
+	var frames []Frame
+	var prev []float64
+	for _, seq := range dft.Frames(samples, frameSize, hopSize) {
+		copy(buf, seq)
+		dft.ApplyHanningWindow(buf)
+		coeffs := dft.Forward(buf)
+
+		f := Frame{
+			Magnitude: make([]float64, bins),
+			Phase:     make([]float64, bins),
+			Frequency: make([]float64, bins),
+		}
+		for k, c := range coeffs {
+			f.Magnitude[k] = cmplx.Abs(c)
+			f.Phase[k] = cmplx.Phase(c)
+
+			// Deviation of the phase advance from that of the bin center frequency
+			omega := 2 * math.Pi * float64(k) / float64(frameSize)
+			f.Frequency[k] = omega
+			if prev != nil {
+				deviation := wrap(f.Phase[k] - prev[k] - omega*float64(hopSize))
+				f.Frequency[k] += deviation / float64(hopSize)
+			}
+		}
+		prev = f.Phase
+		frames = append(frames, f)
+	}
+	return frames
+}
+
+// Synthesize propagates the phase of every bin with its instantaneous frequency
+// over hopSize samples per frame and overlap-adds the frames. The first frame
+// and frames marked with Reset keep their analysis phase. The result has (len(frames)-1)*hopSize+frameSize
+// samples.
+func Synthesize(frames []Frame, frameSize, hopSize int) []float64 {
+	if len(frames) == 0 {
+		return []float64{}
+	}
+	bins := frameSize/2 + 1
+	phase := append([]float64(nil), frames[0].Phase...)
+
+	spectra := make([]dft.Frame, len(frames))
+	for i, f := range frames {
+		if f.Reset {
+			copy(phase, f.Phase)
+		} else if i > 0 {
+			for k := range phase {
+				phase[k] += f.Frequency[k] * float64(hopSize)
+			}
+		}
+		coeffs := make([]complex128, bins)
+		for k := range coeffs {
+			coeffs[k] = cmplx.Rect(f.Magnitude[k], phase[k])
+		}
+		spectra[i] = dft.Frame{Spectrum: coeffs}
+	}
+	return dft.ISTFT(spectra, frameSize, hopSize, (len(frames)-1)*hopSize+frameSize)
+}
+
+// Run analyzes samples, calls opts.Process for every frame and resynthesizes
+// them. The result has round(len(samples)*SynthesisHop/AnalysisHop) samples.
+func Run(samples []float64, opts Options) ([]float64, error) {
+	if opts.FrameSize < 4 || opts.AnalysisHop <= 0 || opts.SynthesisHop <= 0 {
+		return nil, fmt.Errorf("invalid frame size %d or hops %d/%d", opts.FrameSize, opts.AnalysisHop, opts.SynthesisHop)
+	}
+	if opts.SynthesisHop > opts.FrameSize/2 {
+		return nil, fmt.Errorf("synthesis hop %d exceeds half the frame size", opts.SynthesisHop)
+	}
+	return run(samples, opts, func(frames []Frame) {
+		if opts.Process != nil {
+			for i := range frames {
+				opts.Process(i, &frames[i])
+			}
+		}
+	}), nil
+}
+
+// run pads samples, analyzes them, lets process modify all frames at once and
+// resynthesizes them
+func run(samples []float64, opts Options, process func(frames []Frame)) []float64 {
+
+	// Pad by a frame on both ends so every sample is covered by full windows
+	pad := opts.FrameSize
+	padded := make([]float64, len(samples)+2*pad)
+	copy(padded[pad:], samples)
+
+	frames := Analyze(padded, opts.FrameSize, opts.AnalysisHop)
+	process(frames)
+	out := Synthesize(frames, opts.FrameSize, opts.SynthesisHop)
+
+	ratio := float64(opts.SynthesisHop) / float64(opts.AnalysisHop)
+	start := int(math.Round(float64(pad) * ratio))
+	length := int(math.Round(float64(len(samples)) * ratio))
+	result := make([]float64, length)
+	if start < len(out) {
+		copy(result, out[start:])
+	}
+	return result
+}
+
+// wrap maps a phase to [-π, π)
+func wrap(phase float64) float64 {
+	return phase - 2*math.Pi*math.Floor((phase+math.Pi)/(2*math.Pi))
+}
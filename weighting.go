@@ -0,0 +1,125 @@
+package dft
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Weighting is a frequency weighting curve of IEC 61672
+type Weighting int
+
+const (
+	ZWeighting Weighting = iota // flat, no weighting
+	AWeighting
+	CWeighting
+)
+
+func (w Weighting) String() string {
+	switch w {
+	case AWeighting:
+		return "A"
+	case CWeighting:
+		return "C"
+	}
+	return "Z"
+}
+
+// Pole frequencies of the IEC 61672 weighting curves in Hz
+const (
+	weightingF1 = 20.598997
+	weightingF2 = 107.65265
+	weightingF3 = 737.86223
+	weightingF4 = 12194.217
+)
+
+// hanningENBW is the equivalent noise bandwidth of the Hanning window in bins
+const hanningENBW = 1.5
+
+// WeightingGain returns the linear gain of the weighting curve at freq Hz,
+// normalized to 1 at 1 kHz.
+func WeightingGain(w Weighting, freq float64) float64 {
+	return weightingResponse(w, freq) / weightingResponse(w, 1000)
+}
+
+// WeightingDB returns the gain of the weighting curve at freq Hz in dB
+func WeightingDB(w Weighting, freq float64) float64 {
+	return 20 * math.Log10(WeightingGain(w, freq))
+}
+
+// weightingResponse is the unnormalized analog magnitude response
+func weightingResponse(w Weighting, freq float64) float64 {
+	f2 := freq * freq
+	c := (f2 + weightingF1*weightingF1) * (f2 + weightingF4*weightingF4)
+	switch w {
+	case AWeighting:
+		return weightingF4 * weightingF4 * f2 * f2 /
+			(c * math.Sqrt((f2+weightingF2*weightingF2)*(f2+weightingF3*weightingF3)))
+	case CWeighting:
+		return weightingF4 * weightingF4 * f2 / c
+	}
+	return 1
+}
+
+// Weighted returns a copy of s with the weighting curve applied to every bin
+func (s Spectrum) Weighted(w Weighting) Spectrum {
+	weighted := s
+	weighted.Coefficients = make([]complex128, len(s.Coefficients))
+	weighted.Magnitude = make([]float64, len(s.Magnitude))
+	for i := range s.Coefficients {
+		g := 1.0
+		if w != ZWeighting {
+			g = WeightingGain(w, float64(i)*s.FreqRes())
+		}
+		weighted.Coefficients[i] = s.Coefficients[i] * complex(g, 0)
+		weighted.Magnitude[i] = s.Magnitude[i] * g
+	}
+	return weighted
+}
+
+// WeightedLevel returns the overall weighted level of s in dB relative to full
+// scale, a full scale sine at 1 kHz has a level of 0 dBA/dBC. The bin powers are
+// corrected for the equivalent noise bandwidth of the Hanning window, which
+// grows with the zero-padding of the spectrum.
+func WeightedLevel(s Spectrum, w Weighting) float64 {
+	weighted := s.Weighted(w)
+	sum := 0.0
+	for _, m := range weighted.Magnitude {
+		sum += m * m
+	}
+	enbw := hanningENBW * float64(s.FFTSize) / float64(s.SignalLength)
+	return AmplitudeToDB(math.Sqrt(sum/enbw), DBOptions{})
+}
+
+// WeightingFilter designs a time domain IIR filter for the weighting curve using
+// the bilinear transform. The filter is normalized to 0 dB at 1 kHz. Because of
+// the frequency warping of the bilinear transform the response falls off too
+// early close to the Nyquist frequency, use a sample rate of at least 44.1 kHz.
+func WeightingFilter(w Weighting, sampleRate int) (SOS, error) {
+	p1 := bilinearPole(weightingF1, sampleRate)
+	p4 := bilinearPole(weightingF4, sampleRate)
+
+	var f SOS
+	switch w {
+	case AWeighting:
+		p2 := bilinearPole(weightingF2, sampleRate)
+		p3 := bilinearPole(weightingF3, sampleRate)
+		f = SOS{
+			realBiquad(1, 1, p1, p1),
+			realBiquad(1, 1, p2, p3),
+			realBiquad(-1, -1, p4, p4),
+		}
+	case CWeighting:
+		f = SOS{
+			realBiquad(1, 1, p1, p1),
+			realBiquad(-1, -1, p4, p4),
+		}
+	case ZWeighting:
+		return SOS{{B0: 1}}, nil
+	default:
+		return nil, fmt.Errorf("unknown weighting %d", w)
+	}
+
+	f.Scale(1 / cmplx.Abs(f.Response(1000, sampleRate)))
+	return f, nil
+}